@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/metrics"
+	"github.com/dickeyy/go-distances/output"
+)
+
+// batchExtensions are the extensions of the files batch picks from
+// directories.
+var batchExtensions = []string{".json", ".geojson", ".csv", ".gpx"}
+
+// resultExtensions are the extensions of the result files batch writes with
+// -o, by output format. Other formats are written with .txt.
+var resultExtensions = map[string]string{
+	"json":     ".json",
+	"ndjson":   ".ndjson",
+	"csv":      ".csv",
+	"markdown": ".md",
+}
+
+// runBatch implements the batch subcommand, which measures the circular
+// route through every places file in directories or matching glob patterns,
+// in parallel. Results are written to one file per places file in the -o
+// directory, or else to a single combined report. Files that fail are
+// logged and skipped, and a summary is printed on standard error. It returns
+// the process exit code, which is 1 when any file failed.
+//
+//	go-distances batch [-formula haversine] [-format csv] [-o results] [-jobs 4] test-data 'tracks/*.gpx'
+func (c *cli) runBatch(args []string) int {
+	flags := c.flagSet("batch")
+	formula := flags.String("formula", "", "formula to measure every file with (default: the formula of each file)")
+	format := flags.String("format", "text", "output format: "+strings.Join(output.Names(), ", "))
+	outDir := flags.String("o", "", "directory to write one result file per places file to, instead of a combined report")
+	report := flags.String("report", "", "path of the combined report to write (default: standard output)")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "number of files processed in parallel")
+	metricsFile := flags.String("metrics-file", "", "write metrics in the Prometheus text format to this file when done")
+	logging := addLogFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-distances batch [flags] <directory or glob>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 || *jobs < 1 || (*outDir != "" && *report != "") {
+		flags.Usage()
+		return 2
+	}
+	logger, err := logging.logger(c.stderr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Invalid -log-format value: %v.\n", err)
+		return 2
+	}
+	writer, ok := output.Lookup(*format)
+	if !ok {
+		logger.Error(fmt.Sprintf("Invalid output format %q. Use one of: %s.", *format, strings.Join(output.Names(), ", ")))
+		return 2
+	}
+
+	files, err := batchFiles(flags.Args())
+	if err != nil {
+		logger.Error("Could not find the places files.", "error", err)
+		return 2
+	}
+	var outputs []string
+	if *outDir != "" {
+		if outputs, err = resultPaths(files, *outDir, *format); err != nil {
+			logger.Error("Results would overwrite each other. Write a combined report or run batch once per directory.", "error", err)
+			return 2
+		}
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			logger.Error("Could not create the output directory.", "error", err)
+			return 1
+		}
+	}
+
+	m := metrics.NewDistances()
+	results := processBatch(files, *jobs, func(i int, path string) batchResult {
+		result := measureFile(path, *formula, writer)
+		if result.err == nil && outputs != nil {
+			result.err = os.WriteFile(outputs[i], result.output, 0o644)
+		}
+		return result
+	})
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			m.Files.Inc("failed")
+			m.ParseFailure(result.err)
+			logger.Error(describeError(result.err), "file", result.path, "error", result.err)
+			continue
+		}
+		m.Files.Inc("succeeded")
+		m.Points.Add(float64(result.points), result.formula)
+	}
+
+	if outputs == nil {
+		if err := c.writeReport(*report, writer.Name, results); err != nil {
+			logger.Error("Could not write the report.", "error", err)
+			return 1
+		}
+	}
+	if *metricsFile != "" {
+		if err := writeMetrics(*metricsFile, m); err != nil {
+			logger.Error("Could not write the metrics file.", "error", err)
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "Processed %d places files: %d succeeded, %d failed.\n", len(results), len(results)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// batchFiles expands directories, to the places files directly inside them,
+// and glob patterns, to the files they match. Every argument must name at
+// least one file.
+func batchFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+		found := 0
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				files = append(files, match)
+				found++
+				continue
+			}
+			entries, err := os.ReadDir(match)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if entry.Type().IsRegular() && slices.Contains(batchExtensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+					files = append(files, filepath.Join(match, entry.Name()))
+					found++
+				}
+			}
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("no places files found for %q", arg)
+		}
+	}
+	return files, nil
+}
+
+// resultPaths returns the path in dir of the result file for every places
+// file: its base name with the extension of the output format. Places files
+// whose results would overwrite each other are an error.
+func resultPaths(files []string, dir, format string) ([]string, error) {
+	extension, ok := resultExtensions[format]
+	if !ok {
+		extension = ".txt"
+	}
+	paths := make([]string, len(files))
+	seen := make(map[string]string)
+	for i, file := range files {
+		base := filepath.Base(file)
+		paths[i] = filepath.Join(dir, strings.TrimSuffix(base, filepath.Ext(base))+extension)
+		if other, ok := seen[paths[i]]; ok {
+			return nil, fmt.Errorf("the results of %s and %s would both be written to %s", other, file, paths[i])
+		}
+		seen[paths[i]] = file
+	}
+	return paths, nil
+}
+
+// batchResult is the outcome of measuring one places file.
+type batchResult struct {
+	path    string
+	output  []byte // the result in the output format
+	formula string
+	points  int
+	err     error
+}
+
+// processBatch calls process for every file with up to jobs calls running at
+// once, and returns the results in the order of files.
+func processBatch(files []string, jobs int, process func(i int, path string) batchResult) []batchResult {
+	results := make([]batchResult, len(files))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = process(i, files[i])
+			}
+		}()
+	}
+	for i := range files {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
+
+// measureFile measures the circular route through the places of a file with
+// its own formula, or with formula when it is not empty, and writes the
+// result with writer.
+func measureFile(path, formula string, writer output.Format) batchResult {
+	result := batchResult{path: path}
+	dataset, err := geo.LoadFile(path)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if formula != "" {
+		dataset.Formula = formula
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		result.err = err
+		return result
+	}
+	route, err := calculator.Circular(dataset.Path)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	var b bytes.Buffer
+	if err := writer.Writer.WriteResult(&b, route); err != nil {
+		result.err = err
+		return result
+	}
+	result.output, result.formula, result.points = b.Bytes(), route.Formula, len(dataset.Path)
+	return result
+}
+
+// writeReport writes the combined report of the files that succeeded to the
+// named file, or to standard output when the name is empty.
+func (c *cli) writeReport(path, format string, results []batchResult) error {
+	if path == "" {
+		return combineResults(c.stdout, format, results)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := combineResults(file, format, results); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// combineResults writes the results of the files that succeeded as a single
+// document of the format, telling the files apart: JSON documents become an
+// array of documents with a "file" member, NDJSON lines and CSV rows gain a
+// file member or column, and other formats get a heading per file.
+func combineResults(w io.Writer, format string, results []batchResult) error {
+	var succeeded []batchResult
+	for _, result := range results {
+		if result.err == nil {
+			succeeded = append(succeeded, result)
+		}
+	}
+
+	switch format {
+	case "json":
+		documents := make([]json.RawMessage, len(succeeded))
+		for i, result := range succeeded {
+			documents[i] = withFile(result.output, result.path)
+		}
+		b, err := json.MarshalIndent(documents, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	case "ndjson":
+		for _, result := range succeeded {
+			for line := range bytes.Lines(result.output) {
+				if _, err := fmt.Fprintf(w, "%s\n", withFile(line, result.path)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case "csv":
+		return combineCSV(w, succeeded)
+	}
+
+	heading := "==> %s <==\n"
+	if format == "markdown" {
+		heading = "## %s\n\n"
+	}
+	for i, result := range succeeded {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, heading, result.path); err != nil {
+			return err
+		}
+		if _, err := w.Write(result.output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withFile adds a "file" member naming path at the start of a JSON object.
+func withFile(object []byte, path string) []byte {
+	name, _ := json.Marshal(path)
+	member := append(append([]byte(`{"file":`), name...), ',')
+	return append(member, bytes.TrimSpace(object)[1:]...)
+}
+
+// combineCSV writes the CSV results as one table with a leading file column
+// and a single header. Results whose header differs from the first, such as
+// those with elevations, get a header of their own.
+func combineCSV(w io.Writer, results []batchResult) error {
+	out := csv.NewWriter(w)
+	var header []string
+	for _, result := range results {
+		records, err := csv.NewReader(bytes.NewReader(result.output)).ReadAll()
+		if err != nil {
+			return err
+		}
+		for i, record := range records {
+			if i == 0 {
+				if slices.Equal(record, header) {
+					continue
+				}
+				header = record
+				record = append([]string{"file"}, record...)
+			} else {
+				record = append([]string{result.path}, record...)
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// batchDir writes two valid places files, an invalid one and a file batch
+// ignores to a temporary directory.
+func batchDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"equator.csv": "name,lat,lon\nA,0,0\nB,0,1\n",
+		"north.json":  `{"places": [{"name": "X", "latitude": "10", "longitude": "10"}, {"name": "Y", "latitude": "11", "longitude": "10"}], "formula": "haversine"}`,
+		"broken.csv":  "name,lat,lon\nA,north,0\nB,0,1\n",
+		"notes.txt":   "not a places file",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunBatchOutputDirectory(t *testing.T) {
+	dir := batchDir(t)
+	out := filepath.Join(t.TempDir(), "results")
+	metricsFile := filepath.Join(t.TempDir(), "batch.prom")
+	if code := quiet().runBatch([]string{"-format", "markdown", "-o", out, "-jobs", "2", "-metrics-file", metricsFile, dir}); code != 1 {
+		t.Errorf("exit code %d, want 1 for a failed file", code)
+	}
+
+	entries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, " ") != "equator.md north.md" {
+		t.Errorf("Result files %v, want equator.md north.md", names)
+	}
+	north, _ := os.ReadFile(filepath.Join(out, "north.md"))
+	if !strings.Contains(string(north), "haversine") {
+		t.Errorf("Unexpected result:\n%s", north)
+	}
+
+	contents, err := os.ReadFile(metricsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`go_distances_batch_files_total{result="failed"} 1`,
+		`go_distances_batch_files_total{result="succeeded"} 2`,
+		`go_distances_parse_failures_total{kind="latitude"} 1`,
+		`go_distances_points_processed_total{formula="haversine"} 2`,
+	} {
+		if !strings.Contains(string(contents), want) {
+			t.Errorf("Metrics have no %s:\n%s", want, contents)
+		}
+	}
+}
+
+func TestRunBatchReport(t *testing.T) {
+	dir := batchDir(t)
+	report := filepath.Join(t.TempDir(), "report.json")
+	args := []string{"-format", "json", "-formula", "sloc", "-report", report, filepath.Join(dir, "*.json"), filepath.Join(dir, "equator.csv")}
+	if code := quiet().runBatch(args); code != 0 {
+		t.Fatalf("exit code %d, want 0", code)
+	}
+
+	contents, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var documents []struct {
+		File    string `json:"file"`
+		Formula string `json:"formula"`
+		Legs    []any  `json:"legs"`
+	}
+	if err := json.Unmarshal(contents, &documents); err != nil {
+		t.Fatalf("invalid report: %v\n%s", err, contents)
+	}
+	if len(documents) != 2 || filepath.Base(documents[0].File) != "north.json" || filepath.Base(documents[1].File) != "equator.csv" {
+		t.Fatalf("Unexpected report:\n%s", contents)
+	}
+	for _, document := range documents {
+		if document.Formula != "sloc" || len(document.Legs) != 2 {
+			t.Errorf("Unexpected document %+v", document)
+		}
+	}
+}
+
+func TestRunBatchInvalid(t *testing.T) {
+	dir := batchDir(t)
+	other := t.TempDir()
+	os.WriteFile(filepath.Join(other, "equator.gpx"), []byte("<gpx></gpx>"), 0o644)
+
+	tests := [][]string{
+		{},
+		{"-jobs", "0", dir},
+		{"-o", "out", "-report", "report.txt", dir},
+		{"-format", "xml", dir},
+		{filepath.Join(dir, "missing*")},
+		{"-o", t.TempDir(), dir, other}, // equator.txt twice
+	}
+	for _, args := range tests {
+		if code := quiet().runBatch(args); code != 2 {
+			t.Errorf("runBatch(%v) = %d, want 2", args, code)
+		}
+	}
+}
+
+func TestCombineResults(t *testing.T) {
+	results := []batchResult{
+		{path: "a.csv", output: []byte("formula,distance\nvincenty,1\n")},
+		{path: "broken.csv", err: os.ErrNotExist},
+		{path: "b.csv", output: []byte("formula,distance\nhaversine,2\n")},
+	}
+	var b strings.Builder
+	if err := combineResults(&b, "csv", results); err != nil {
+		t.Fatal(err)
+	}
+	if want := "file,formula,distance\na.csv,vincenty,1\nb.csv,haversine,2\n"; b.String() != want {
+		t.Errorf("csv: got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	results = []batchResult{
+		{path: "a.csv", output: []byte("{\"leg\":1}\n{\"leg\":2}\n")},
+		{path: "b.csv", output: []byte("{\"leg\":1}\n")},
+	}
+	b.Reset()
+	if err := combineResults(&b, "ndjson", results); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"file\":\"a.csv\",\"leg\":1}\n{\"file\":\"a.csv\",\"leg\":2}\n{\"file\":\"b.csv\",\"leg\":1}\n"; b.String() != want {
+		t.Errorf("ndjson: got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	results = []batchResult{
+		{path: "a.csv", output: []byte("A\n")},
+		{path: "b.csv", output: []byte("B\n")},
+	}
+	b.Reset()
+	if err := combineResults(&b, "text", results); err != nil {
+		t.Fatal(err)
+	}
+	if want := "==> a.csv <==\nA\n\n==> b.csv <==\nB\n"; b.String() != want {
+		t.Errorf("text: got\n%s\nwant\n%s", b.String(), want)
+	}
+}
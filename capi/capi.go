@@ -0,0 +1,166 @@
+// Command capi is the C shared library of go-distances, for programs in
+// other languages that need the same distances as the command line. Build it
+// with:
+//
+//	go build -buildmode=c-shared -o libgodistances.so ./capi
+//
+// which also writes the header libgodistances.h declaring the functions and
+// status codes below. The functions only take and return C types, and report
+// failures with a status code, so that their ABI stays stable as the Go
+// implementation changes; GD_ABI_VERSION is increased on any incompatible
+// change.
+package main
+
+/*
+#include <stddef.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+// GD_ABI_VERSION is the version of the ABI declared in this header. Compare
+// it with gd_abi_version() to detect a mismatched library.
+#define GD_ABI_VERSION 1
+
+// Status codes returned by the gd_ functions.
+enum gd_status {
+	GD_OK = 0,
+	// the formula is not registered
+	GD_ERR_UNKNOWN_FORMULA = 1,
+	// a latitude is outside [-90, 90] or a coordinate is not finite
+	GD_ERR_INVALID_COORDINATE = 2,
+	// a pointer is NULL or the radius is negative or not finite
+	GD_ERR_INVALID_ARGUMENT = 3,
+	// a route has fewer than two points
+	GD_ERR_TOO_FEW_POINTS = 4,
+};
+
+// Read-only arguments, declared const for C++ callers.
+typedef const char gd_const_char;
+typedef const double gd_const_double;
+
+// gd_strerror returns a static description of a status code.
+const char *gd_strerror(int status);
+
+// int gd_abi_version(void);
+//     Returns GD_ABI_VERSION of the header the library was built with.
+//
+// int gd_distance(const char *formula, double lat1, double lon1,
+//                 double lat2, double lon2, double radius, double *distance);
+//     Stores in *distance the great-circle distance between two points, in
+//     degrees, measured with the named formula on a sphere of the given
+//     radius. A NULL or empty formula means vincenty, and a radius of 0 the
+//     mean radius of the Earth in kilometres; the distance is in the unit of
+//     the radius.
+//
+// int gd_route_length(const char *formula, const double *lats,
+//                     const double *lons, size_t n, int closed,
+//                     double radius, double *length);
+//     Stores in *length the length of the route through the n points whose
+//     latitudes and longitudes, in degrees, are in lats and lons. The route
+//     returns to its first point when closed is non-zero, as the command line
+//     measures it. The formula and radius are as for gd_distance.
+
+#ifdef __cplusplus
+}
+#endif
+*/
+import "C"
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// main is required by -buildmode=c-shared but never called.
+func main() {}
+
+// gd_abi_version and the other exported functions are documented in the
+// preamble, which is copied into the generated header.
+//
+//export gd_abi_version
+func gd_abi_version() C.int {
+	return C.GD_ABI_VERSION
+}
+
+//export gd_distance
+func gd_distance(formula *C.gd_const_char, lat1, lon1, lat2, lon2, radius C.double, distance *C.double) C.int {
+	if distance == nil {
+		return C.GD_ERR_INVALID_ARGUMENT
+	}
+	calculator, status := newCalculator(formula, radius)
+	if status != C.GD_OK {
+		return status
+	}
+	a, b := geo.NewPoint(float64(lat1), float64(lon1)), geo.NewPoint(float64(lat2), float64(lon2))
+	if !valid(a) || !valid(b) {
+		return C.GD_ERR_INVALID_COORDINATE
+	}
+	*distance = C.double(calculator.Distance(a, b))
+	return C.GD_OK
+}
+
+//export gd_route_length
+func gd_route_length(formula *C.gd_const_char, lats, lons *C.gd_const_double, n C.size_t, closed C.int, radius C.double, length *C.double) C.int {
+	if length == nil || (n > 0 && (lats == nil || lons == nil)) {
+		return C.GD_ERR_INVALID_ARGUMENT
+	}
+	calculator, status := newCalculator(formula, radius)
+	if status != C.GD_OK {
+		return status
+	}
+	if n < 2 {
+		return C.GD_ERR_TOO_FEW_POINTS
+	}
+
+	latitudes, longitudes := unsafe.Slice(lats, n), unsafe.Slice(lons, n)
+	path := make(geo.Path, n)
+	for i := range path {
+		path[i] = geo.NewPoint(float64(latitudes[i]), float64(longitudes[i]))
+		if !valid(path[i]) {
+			return C.GD_ERR_INVALID_COORDINATE
+		}
+	}
+
+	if closed == 0 {
+		*length = C.double(calculator.Length(path))
+		return C.GD_OK
+	}
+	// Circular only fails for fewer than two points
+	result, _ := calculator.Circular(path)
+	*length = C.double(result.Total)
+	return C.GD_OK
+}
+
+// newCalculator returns a calculator for a formula and radius as passed to
+// the gd_ functions, or the status code describing why it cannot.
+func newCalculator(formula *C.gd_const_char, radius C.double) (*geo.Calculator, C.int) {
+	r := float64(radius)
+	if r < 0 || math.IsNaN(r) || math.IsInf(r, 0) {
+		return nil, C.GD_ERR_INVALID_ARGUMENT
+	}
+	data := utils.Data{EarthRadius: r}
+	if formula != nil {
+		data.Formula = C.GoString((*C.char)(unsafe.Pointer(formula)))
+	}
+	r, name := data.Defaults()
+
+	body := geo.Earth
+	if radius != 0 {
+		body = geo.Earth.WithRadius(r, "")
+	}
+	calculator, err := geo.NewCalculator(name, body)
+	if err != nil {
+		return nil, C.GD_ERR_UNKNOWN_FORMULA
+	}
+	return calculator, C.GD_OK
+}
+
+// valid reports whether a point has a latitude in [-90, 90] and a finite
+// longitude.
+func valid(p geo.Point) bool {
+	return p.Lat >= -90 && p.Lat <= 90 && !math.IsNaN(p.Lon) && !math.IsInf(p.Lon, 0)
+}
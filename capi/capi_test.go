@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestCABI builds the shared library, compiles testdata/capi_test.c against
+// its generated header, and runs it, so the exported functions are
+// exercised exactly as C callers see them.
+func TestCABI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the shared library")
+	}
+	cc := os.Getenv("CC")
+	if cc == "" {
+		cc = "cc"
+	}
+	if _, err := exec.LookPath(cc); err != nil {
+		t.Skipf("no C compiler: %v", err)
+	}
+
+	dir := t.TempDir()
+	run(t, exec.Command("go", "build", "-buildmode=c-shared", "-o", filepath.Join(dir, "libgodistances.so"), "."))
+	program := filepath.Join(dir, "capi_test")
+	run(t, exec.Command(cc, "-o", program, filepath.Join("testdata", "capi_test.c"), "-I", dir, "-L", dir, "-lgodistances", "-lm"))
+
+	test := exec.Command(program)
+	test.Env = append(os.Environ(), "LD_LIBRARY_PATH="+dir, "DYLD_LIBRARY_PATH="+dir)
+	if output, err := test.CombinedOutput(); err != nil {
+		t.Errorf("%v\n%s", err, output)
+	}
+}
+
+func run(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %v\n%s", cmd.Args, err, output)
+	}
+}
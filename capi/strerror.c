@@ -0,0 +1,17 @@
+#include "_cgo_export.h"
+
+const char *gd_strerror(int status) {
+	switch (status) {
+	case GD_OK:
+		return "success";
+	case GD_ERR_UNKNOWN_FORMULA:
+		return "unknown formula";
+	case GD_ERR_INVALID_COORDINATE:
+		return "invalid coordinate";
+	case GD_ERR_INVALID_ARGUMENT:
+		return "invalid argument";
+	case GD_ERR_TOO_FEW_POINTS:
+		return "at least two points are required";
+	}
+	return "unknown status";
+}
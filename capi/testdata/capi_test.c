@@ -0,0 +1,53 @@
+// capi_test.c exercises libgodistances through its generated header. It
+// prints a line for every failed check and exits with the number of
+// failures.
+#include <math.h>
+#include <stdio.h>
+#include <string.h>
+
+#include "libgodistances.h"
+
+static int failures = 0;
+
+static void check(int ok, const char *what) {
+	if (!ok) {
+		printf("FAIL: %s\n", what);
+		failures++;
+	}
+}
+
+int main(void) {
+	double d = 0;
+
+	check(gd_abi_version() == GD_ABI_VERSION, "gd_abi_version matches the header");
+
+	// New York to Los Angeles
+	check(gd_distance("haversine", 40.7128, -74.0060, 34.0522, -118.2437, 0, &d) == GD_OK, "gd_distance haversine");
+	check(fabs(d - 3935.746) < 0.001, "haversine distance is 3935.746 km");
+	check(gd_distance(NULL, 40.7128, -74.0060, 34.0522, -118.2437, 3959, &d) == GD_OK, "gd_distance default formula");
+	check(fabs(d - 3935.746 * 3959 / 6371) < 0.01, "distance is in the unit of the radius");
+	check(gd_distance("spherical law of cosines", 0, 0, 0, 90, 2, &d) == GD_OK && fabs(d - M_PI) < 1e-12, "gd_distance sloc alias");
+
+	check(gd_distance("flat", 0, 0, 0, 1, 0, &d) == GD_ERR_UNKNOWN_FORMULA, "unknown formula");
+	check(gd_distance("haversine", 91, 0, 0, 1, 0, &d) == GD_ERR_INVALID_COORDINATE, "latitude out of range");
+	check(gd_distance("haversine", 0, NAN, 0, 1, 0, &d) == GD_ERR_INVALID_COORDINATE, "NaN longitude");
+	check(gd_distance("haversine", 0, 0, 0, 1, -1, &d) == GD_ERR_INVALID_ARGUMENT, "negative radius");
+	check(gd_distance("haversine", 0, 0, 0, 1, 0, NULL) == GD_ERR_INVALID_ARGUMENT, "NULL result");
+
+	// along the equator, one degree at a time
+	const double lats[] = {0, 0, 0};
+	const double lons[] = {0, 1, 2};
+	double one = 0, open = 0, closed = 0;
+	gd_distance("haversine", 0, 0, 0, 1, 0, &one);
+	check(gd_route_length("haversine", lats, lons, 3, 0, 0, &open) == GD_OK, "gd_route_length open");
+	check(fabs(open - 2 * one) < 1e-9, "open route length");
+	check(gd_route_length("haversine", lats, lons, 3, 1, 0, &closed) == GD_OK, "gd_route_length closed");
+	check(fabs(closed - 4 * one) < 1e-9, "closed route length");
+	check(gd_route_length("haversine", lats, lons, 1, 1, 0, &closed) == GD_ERR_TOO_FEW_POINTS, "too few points");
+	check(gd_route_length("haversine", NULL, lons, 3, 1, 0, &closed) == GD_ERR_INVALID_ARGUMENT, "NULL latitudes");
+
+	check(strcmp(gd_strerror(GD_ERR_UNKNOWN_FORMULA), "unknown formula") == 0, "gd_strerror");
+	check(strcmp(gd_strerror(42), "unknown status") == 0, "gd_strerror of an unknown status");
+
+	return failures;
+}
@@ -0,0 +1,106 @@
+// Package ecef converts between geodetic coordinates and Earth-centred,
+// Earth-fixed (ECEF) Cartesian coordinates, in which straight-line distances
+// through the Earth are simple vector lengths.
+package ecef
+
+import (
+	"math"
+
+	"github.com/dickeyy/go-distances/ellipsoid"
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// Vector is a position or displacement in ECEF coordinates. X points from
+// the center to latitude 0, longitude 0; Y to latitude 0, longitude 90°E;
+// and Z to the north pole. Units are those of the ellipsoid or sphere the
+// vector was converted from, metres for the predefined ellipsoids.
+type Vector struct {
+	X, Y, Z float64
+}
+
+// FromGeodetic converts a geodetic latitude and longitude, in degrees, and
+// a height above the ellipsoid into ECEF coordinates.
+func FromGeodetic(e ellipsoid.Ellipsoid, lat, lon, height float64) Vector {
+	phi, lambda := utils.DegreeToRad(lat), utils.DegreeToRad(lon)
+	n := e.PrimeVerticalRadius(lat)
+	return Vector{
+		X: (n + height) * math.Cos(phi) * math.Cos(lambda),
+		Y: (n + height) * math.Cos(phi) * math.Sin(lambda),
+		Z: (n*(1-e.E2()) + height) * math.Sin(phi),
+	}
+}
+
+// FromSpherical converts a latitude and longitude, in degrees, on a sphere
+// of the given radius into ECEF coordinates.
+func FromSpherical(lat, lon, radius float64) Vector {
+	phi, lambda := utils.DegreeToRad(lat), utils.DegreeToRad(lon)
+	return Vector{
+		X: radius * math.Cos(phi) * math.Cos(lambda),
+		Y: radius * math.Cos(phi) * math.Sin(lambda),
+		Z: radius * math.Sin(phi),
+	}
+}
+
+// Geodetic converts v back to a geodetic latitude and longitude, in
+// degrees, and a height above the ellipsoid, with Heikkinen's closed-form
+// solution. It is exact to well under a millimetre for points within a few
+// thousand kilometres of the surface.
+func (v Vector) Geodetic(e ellipsoid.Ellipsoid) (lat, lon, height float64) {
+	a, b, e2 := e.A, e.B(), e.E2()
+	ep2 := (a*a - b*b) / (b * b)
+	p := math.Hypot(v.X, v.Y)
+	z := v.Z
+
+	f := 54 * b * b * z * z
+	g := p*p + (1-e2)*z*z - e2*(a*a-b*b)
+	c := e2 * e2 * f * p * p / (g * g * g)
+	s := math.Cbrt(1 + c + math.Sqrt(c*c+2*c))
+	k := s + 1 + 1/s
+	pp := f / (3 * k * k * g * g)
+	q := math.Sqrt(1 + 2*e2*e2*pp)
+	r0 := -pp*e2*p/(1+q) + math.Sqrt(max(0, a*a/2*(1+1/q)-pp*(1-e2)*z*z/(q*(1+q))-pp*p*p/2))
+	u := math.Hypot(p-e2*r0, z)
+	w := math.Sqrt((p-e2*r0)*(p-e2*r0) + (1-e2)*z*z)
+	z0 := b * b * z / (a * w)
+
+	height = u * (1 - b*b/(a*w))
+	lat = utils.RadToDegree(math.Atan2(z+ep2*z0, p))
+	lon = utils.RadToDegree(math.Atan2(v.Y, v.X))
+	return lat, lon, height
+}
+
+// Add returns v + w.
+func (v Vector) Add(w Vector) Vector {
+	return Vector{v.X + w.X, v.Y + w.Y, v.Z + w.Z}
+}
+
+// Sub returns v - w.
+func (v Vector) Sub(w Vector) Vector {
+	return Vector{v.X - w.X, v.Y - w.Y, v.Z - w.Z}
+}
+
+// Scale returns v multiplied by s.
+func (v Vector) Scale(s float64) Vector {
+	return Vector{v.X * s, v.Y * s, v.Z * s}
+}
+
+// Dot returns the dot product of v and w.
+func (v Vector) Dot(w Vector) float64 {
+	return v.X*w.X + v.Y*w.Y + v.Z*w.Z
+}
+
+// Cross returns the cross product of v and w.
+func (v Vector) Cross(w Vector) Vector {
+	return Vector{v.Y*w.Z - v.Z*w.Y, v.Z*w.X - v.X*w.Z, v.X*w.Y - v.Y*w.X}
+}
+
+// Norm returns the length of v.
+func (v Vector) Norm() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+// Distance returns the straight-line distance from v to w, such as the
+// length of a tunnel between two places or the chord of a great circle.
+func (v Vector) Distance(w Vector) float64 {
+	return v.Sub(w).Norm()
+}
@@ -0,0 +1,82 @@
+package ecef
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dickeyy/go-distances/ellipsoid"
+)
+
+func TestFromGeodetic(t *testing.T) {
+	tests := []struct {
+		lat, lon, height float64
+		want             Vector
+	}{
+		{0, 0, 0, Vector{6378137, 0, 0}},
+		{0, 90, 0, Vector{0, 6378137, 0}},
+		{90, 0, 0, Vector{0, 0, 6356752.314245}},
+		{0, 180, 100, Vector{-6378237, 0, 0}},
+		// a worked example from the EPSG guidance note 7-2
+		{53.80939444, 2.12955, 73, Vector{3771793.968, 140253.342, 5124304.349}},
+	}
+	for _, tt := range tests {
+		got := FromGeodetic(ellipsoid.WGS84, tt.lat, tt.lon, tt.height)
+		if got.Distance(tt.want) > 1e-3 {
+			t.Errorf("FromGeodetic(%v, %v, %v) = %+v, want %+v", tt.lat, tt.lon, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestGeodeticRoundTrip(t *testing.T) {
+	for lat := -90.0; lat <= 90; lat += 7.5 {
+		for lon := -180.0; lon < 180; lon += 45 {
+			for _, height := range []float64{-10000, 0, 8848, 400000} {
+				v := FromGeodetic(ellipsoid.WGS84, lat, lon, height)
+				gotLat, gotLon, gotHeight := v.Geodetic(ellipsoid.WGS84)
+				lonError := math.Abs(math.Remainder(gotLon-lon, 360))
+				if math.Abs(lat) == 90 {
+					lonError = 0 // longitude is undefined at the poles
+				}
+				if math.Abs(gotLat-lat) > 1e-9 || lonError > 1e-9 || math.Abs(gotHeight-height) > 1e-4 {
+					t.Errorf("%v, %v, %v -> %v, %v, %v", lat, lon, height, gotLat, gotLon, gotHeight)
+				}
+			}
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// the chord between two points a quarter of the way round a sphere is
+	// √2 times its radius
+	a := FromSpherical(0, 0, 6371)
+	b := FromSpherical(0, 90, 6371)
+	if d := a.Distance(b); math.Abs(d-6371*math.Sqrt2) > 1e-9 {
+		t.Errorf("Distance = %f, want %f", d, 6371*math.Sqrt2)
+	}
+
+	// a tunnel through the Earth from pole to pole
+	north := FromGeodetic(ellipsoid.WGS84, 90, 0, 0)
+	south := FromGeodetic(ellipsoid.WGS84, -90, 0, 0)
+	if d := north.Distance(south); math.Abs(d-2*ellipsoid.WGS84.B()) > 1e-6 {
+		t.Errorf("Distance = %f, want %f", d, 2*ellipsoid.WGS84.B())
+	}
+}
+
+func TestVectorArithmetic(t *testing.T) {
+	v, w := Vector{1, 2, 3}, Vector{4, 5, 6}
+	if got := v.Add(w); got != (Vector{5, 7, 9}) {
+		t.Errorf("Add = %+v", got)
+	}
+	if got := w.Sub(v); got != (Vector{3, 3, 3}) {
+		t.Errorf("Sub = %+v", got)
+	}
+	if got := v.Scale(2); got != (Vector{2, 4, 6}) {
+		t.Errorf("Scale = %+v", got)
+	}
+	if got := v.Dot(w); got != 32 {
+		t.Errorf("Dot = %v", got)
+	}
+	if got := v.Cross(w); got != (Vector{-3, 6, -3}) {
+		t.Errorf("Cross = %+v", got)
+	}
+}
@@ -0,0 +1,50 @@
+// Package ellipsoid describes the reference ellipsoids that geodetic
+// coordinates are defined on, such as WGS 84.
+package ellipsoid
+
+import "math"
+
+// Ellipsoid is an oblate ellipsoid of revolution.
+type Ellipsoid struct {
+	Name string
+	// A is the equatorial radius, or semi-major axis, in metres.
+	A float64
+	// F is the flattening, (A - B) / A.
+	F float64
+}
+
+// Common reference ellipsoids.
+var (
+	// WGS84 is the ellipsoid of GPS, UTM and MGRS.
+	WGS84 = Ellipsoid{Name: "WGS 84", A: 6378137, F: 1 / 298.257223563}
+	// GRS80 is the ellipsoid of ITRS, ETRS89 and NAD83.
+	GRS80 = Ellipsoid{Name: "GRS 80", A: 6378137, F: 1 / 298.257222101}
+)
+
+// B returns the polar radius, or semi-minor axis, in metres.
+func (e Ellipsoid) B() float64 {
+	return e.A * (1 - e.F)
+}
+
+// E2 returns the square of the first eccentricity, f(2 - f).
+func (e Ellipsoid) E2() float64 {
+	return e.F * (2 - e.F)
+}
+
+// E returns the first eccentricity.
+func (e Ellipsoid) E() float64 {
+	return math.Sqrt(e.E2())
+}
+
+// N returns the third flattening, f / (2 - f), which the series expansions
+// of transverse Mercator projections are written in.
+func (e Ellipsoid) N() float64 {
+	return e.F / (2 - e.F)
+}
+
+// PrimeVerticalRadius returns the radius of curvature in the prime vertical
+// at the given latitude, in degrees.
+func (e Ellipsoid) PrimeVerticalRadius(lat float64) float64 {
+	sin := math.Sin(lat * math.Pi / 180)
+	return e.A / math.Sqrt(1-e.E2()*sin*sin)
+}
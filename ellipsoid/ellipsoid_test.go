@@ -0,0 +1,29 @@
+package ellipsoid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWGS84(t *testing.T) {
+	if b := WGS84.B(); math.Abs(b-6356752.314245) > 1e-6 {
+		t.Errorf("B = %f, want 6356752.314245", b)
+	}
+	if e2 := WGS84.E2(); math.Abs(e2-0.00669437999014) > 1e-14 {
+		t.Errorf("E2 = %.14f, want 0.00669437999014", e2)
+	}
+	if n := WGS84.N(); math.Abs(n-0.00167922038638) > 1e-14 {
+		t.Errorf("N = %.14f, want 0.00167922038638", n)
+	}
+}
+
+func TestPrimeVerticalRadius(t *testing.T) {
+	if n := WGS84.PrimeVerticalRadius(0); n != WGS84.A {
+		t.Errorf("PrimeVerticalRadius(0) = %f, want %f", n, WGS84.A)
+	}
+	// at the poles it is a²/b
+	want := WGS84.A * WGS84.A / WGS84.B()
+	if n := WGS84.PrimeVerticalRadius(90); math.Abs(n-want) > 1e-6 {
+		t.Errorf("PrimeVerticalRadius(90) = %f, want %f", n, want)
+	}
+}
@@ -0,0 +1,119 @@
+package formulas
+
+import (
+	"math"
+	"testing"
+)
+
+// approximationGrid returns the grid the errors of the approximate formulas
+// are documented over: every pair of points of a grid over the globe, every
+// point of it with points next to its antipode, and points of a finer grid
+// with points from 1e-7° to 3° away from them in twelve directions. Pairs
+// with a point beyond maxLat, or more than maxAngle radians apart, are left
+// out.
+func approximationGrid(maxLat, maxAngle float64) []pair {
+	var pairs []pair
+	add := func(p pair) {
+		p.lat2 = max(-90, min(p.lat2, 90))
+		if math.Abs(p.lat1) <= maxLat && math.Abs(p.lat2) <= maxLat && referenceAngle(p) <= maxAngle {
+			pairs = append(pairs, p)
+		}
+	}
+	for lat1 := -90.0; lat1 <= 90; lat1 += 10 {
+		for lon1 := -180.0; lon1 < 180; lon1 += 20 {
+			for lat2 := -90.0; lat2 <= 90; lat2 += 10 {
+				for lon2 := -180.0; lon2 < 180; lon2 += 20 {
+					add(pair{lat1 * 0.99, lon1 + 0.7, lat2, lon2})
+					add(pair{lat1, lon1, -lat1 + lat2*1e-5, lon1 + 180 + lon2*1e-5})
+				}
+			}
+		}
+	}
+	for lat := -89.5; lat <= 89.5; lat += 3.7 {
+		for lon := -179.9; lon < 180; lon += 23.3 {
+			for d := 1e-7; d < 4; d *= 3 {
+				for bearing := 0.1; bearing < 2*math.Pi; bearing += math.Pi / 6 {
+					add(pair{lat, lon, lat + d*math.Cos(bearing), lon + d*math.Sin(bearing)/math.Cos(lat*math.Pi/180)})
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// TestApproximateFormulas holds the approximate formulas to the maximum
+// errors documented with them, on the unit sphere.
+func TestApproximateFormulas(t *testing.T) {
+	global := approximationGrid(90, math.Pi)
+	nearby := approximationGrid(70, 100/6371.0)
+	tests := []struct {
+		name               string
+		grid               []pair
+		relative, absolute float64
+	}{
+		{"fasthaversine", global, 2e-10, 2e-11},
+		{"fasthaversine32", global, 5e-5, 1e-6},
+		{"equirectangular", nearby, 1e-4, 2e-11},
+		{"equirectangular32", nearby, 1e-4, 1e-6},
+	}
+	for _, tt := range tests {
+		formula, ok := Lookup(tt.name)
+		if !ok || !formula.Approximate || formula.Error != nil {
+			t.Fatalf("%s is registered as %+v, %t", tt.name, formula, ok)
+		}
+		if len(tt.grid) < 10000 {
+			t.Fatalf("%s: only %d pairs on the grid", tt.name, len(tt.grid))
+		}
+		for _, p := range tt.grid {
+			got, want := formula.Distance(p.lat1, p.lon1, p.lat2, p.lon2, 1), referenceAngle(p)
+			if math.Abs(got-want) > tt.relative*want+tt.absolute {
+				t.Fatalf("%s %+v: distance %v, want %v within %g of it plus %g", tt.name, p, got, want, tt.relative, tt.absolute)
+			}
+		}
+	}
+}
+
+func TestApproximateFormulasCoincident(t *testing.T) {
+	for _, f := range All() {
+		if !f.Approximate {
+			continue
+		}
+		for _, p := range randomPairs(1000, 20) {
+			if d := f.Distance(p.lat1, p.lon1, p.lat1, p.lon1, 6371); d != 0 {
+				t.Fatalf("%s: coincident points at %v, %v are %v apart", f.Name, p.lat1, p.lon1, d)
+			}
+		}
+	}
+}
+
+func TestApproximateFormulasLongitudes(t *testing.T) {
+	// across the antimeridian, and with longitudes outside [-180, 180]
+	want := Haversine(10, 179, 11, -179, 6371)
+	for _, tt := range []struct{ lon1, lon2 float64 }{{179, -179}, {-181, -179}, {539, 181}, {-541, -899}} {
+		if got := FastHaversine(10, tt.lon1, 11, tt.lon2, 6371); math.Abs(got-want) > 1e-6 {
+			t.Errorf("FastHaversine from %v to %v: got %v, want %v", tt.lon1, tt.lon2, got, want)
+		}
+		if got := Equirectangular(10, tt.lon1, 11, tt.lon2, 6371); math.Abs(got-want) > 1e-4*want {
+			t.Errorf("Equirectangular from %v to %v: got %v, want %v", tt.lon1, tt.lon2, got, want)
+		}
+		if got := FastHaversine32(10, float32(tt.lon1), 11, float32(tt.lon2), 6371); math.Abs(float64(got)-want) > 0.01 {
+			t.Errorf("FastHaversine32 from %v to %v: got %v, want %v", tt.lon1, tt.lon2, got, want)
+		}
+	}
+}
+
+func TestPolynomials(t *testing.T) {
+	for x := -math.Pi / 2; x <= math.Pi/2; x += 1e-4 {
+		if d := math.Abs(sinPoly(x) - math.Sin(x)); d > 1e-11 {
+			t.Fatalf("sinPoly(%v) is %g off", x, d)
+		}
+		if d := math.Abs(cosPoly(x) - math.Cos(x)); d > 1e-12 {
+			t.Fatalf("cosPoly(%v) is %g off", x, d)
+		}
+	}
+	for x := 0.0; x <= asinTableMax; x += 1e-5 {
+		if d := math.Abs(asinInterpolate(x) - math.Asin(x)); d > 1e-12 {
+			t.Fatalf("asinInterpolate(%v) is %g off", x, d)
+		}
+	}
+}
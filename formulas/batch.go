@@ -0,0 +1,68 @@
+// Package formulas provides implementations of various distance calculation
+// formulas for geographical points on a sphere.
+package formulas
+
+// PointsFunc calculates the great-circle distance between points i and j of
+// p, on a sphere of radius earthRadius, reusing the trigonometry p holds for
+// them. HaversinePoints, VincentyPoints and SphericalLawOfCosinesPoints are
+// PointsFuncs, and return the same distances as the Funcs they are named
+// after.
+type PointsFunc func(p *Points, i, j int, earthRadius float64) float64
+
+// points returns the batch function of the formula, or one calling Distance
+// for formulas without a batch function.
+func (f Formula) points() PointsFunc {
+	if f.Batch != nil {
+		return f.Batch
+	}
+	return func(p *Points, i, j int, earthRadius float64) float64 {
+		return f.Distance(p.lat[i], p.lon[i], p.lat[j], p.lon[j], earthRadius)
+	}
+}
+
+// Legs appends to dst[:0] the distances between consecutive points of p,
+// and returns the extended slice. A closed route has a last leg from the
+// last point back to the first, so it has as many legs as points; an open
+// route has one fewer.
+func (f Formula) Legs(dst []float64, p *Points, closed bool, earthRadius float64) []float64 {
+	distance := f.points()
+	dst = dst[:0]
+	for i := 1; i < p.Len(); i++ {
+		dst = append(dst, distance(p, i-1, i, earthRadius))
+	}
+	if closed && p.Len() > 0 {
+		dst = append(dst, distance(p, p.Len()-1, 0, earthRadius))
+	}
+	return dst
+}
+
+// From appends to dst[:0] the distance from point i of p to every point of
+// p, and returns the extended slice.
+func (f Formula) From(dst []float64, p *Points, i int, earthRadius float64) []float64 {
+	distance := f.points()
+	dst = dst[:0]
+	for j := range p.Len() {
+		dst = append(dst, distance(p, i, j, earthRadius))
+	}
+	return dst
+}
+
+// Matrix returns the distance between every pair of points of p:
+// Matrix(p)[i][j] is the distance from point i to point j. Each pair is
+// measured once, so the matrix is exactly symmetric.
+func (f Formula) Matrix(p *Points, earthRadius float64) [][]float64 {
+	distance := f.points()
+	n := p.Len()
+	cells := make([]float64, n*n)
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = cells[i*n : (i+1)*n : (i+1)*n]
+	}
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			d := distance(p, i, j, earthRadius)
+			matrix[i][j], matrix[j][i] = d, d
+		}
+	}
+	return matrix
+}
@@ -0,0 +1,149 @@
+package formulas
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// randomPoints returns the points of n reproducible random pairs, which
+// include poles, antimeridian crossings and coincident and antipodal
+// neighbours.
+func randomPoints(n int, seed uint64) *Points {
+	latitudes, longitudes := make([]float64, 0, n), make([]float64, 0, n)
+	for _, p := range randomPairs((n+1)/2, seed) {
+		latitudes = append(latitudes, p.lat1, p.lat2)
+		longitudes = append(longitudes, p.lon1, p.lon2)
+	}
+	return mustPoints(latitudes[:n], longitudes[:n])
+}
+
+// sameDistance reports whether a batch distance equals the per-pair one,
+// allowing for fused multiply-adds on platforms that have them.
+func sameDistance(got, want float64) bool {
+	return math.Abs(got-want) <= 4*unitRoundoff*want
+}
+
+func TestPointsFuncs(t *testing.T) {
+	p := randomPoints(1000, 10)
+	for _, f := range All() {
+		if f.Batch == nil {
+			continue
+		}
+		for i := range p.Len() {
+			for _, j := range []int{i, (i + 1) % p.Len(), (i * 7) % p.Len()} {
+				got := f.Batch(p, i, j, 6371)
+				if want := f.Distance(p.Lat(i), p.Lon(i), p.Lat(j), p.Lon(j), 6371); !sameDistance(got, want) {
+					t.Fatalf("%s batch distance from %d to %d is %v, want %v", f.Name, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestLegs(t *testing.T) {
+	p := randomPoints(101, 11)
+	for _, f := range append(All(), Formula{Name: "unbatched", Distance: Haversine}) {
+		legs := f.Legs(make([]float64, 3), p, true, 2)
+		if len(legs) != p.Len() {
+			t.Fatalf("%s: got %d closed legs, want %d", f.Name, len(legs), p.Len())
+		}
+		for i, d := range legs {
+			next := (i + 1) % p.Len()
+			if want := f.Distance(p.Lat(i), p.Lon(i), p.Lat(next), p.Lon(next), 2); !sameDistance(d, want) {
+				t.Fatalf("%s: leg %d is %v, want %v", f.Name, i, d, want)
+			}
+		}
+		if open := f.Legs(legs, p, false, 2); len(open) != p.Len()-1 {
+			t.Errorf("%s: got %d open legs, want %d", f.Name, len(open), p.Len()-1)
+		}
+	}
+	if legs := All()[0].Legs(nil, mustPoints(nil, nil), true, 1); len(legs) != 0 {
+		t.Errorf("got legs %v for no points", legs)
+	}
+}
+
+func TestFromAndMatrix(t *testing.T) {
+	p := randomPoints(60, 12)
+	for _, f := range append(All(), Formula{Name: "unbatched", Distance: Vincenty}) {
+		matrix := f.Matrix(p, 1)
+		var from []float64
+		for i := range p.Len() {
+			from = f.From(from, p, i, 1)
+			for j := range p.Len() {
+				want := f.Distance(p.Lat(i), p.Lon(i), p.Lat(j), p.Lon(j), 1)
+				if !sameDistance(from[j], want) {
+					t.Fatalf("%s: From(%d)[%d] = %v, want %v", f.Name, i, j, from[j], want)
+				}
+				if i != j && !sameDistance(matrix[i][j], want) && !sameDistance(matrix[i][j], f.Distance(p.Lat(j), p.Lon(j), p.Lat(i), p.Lon(i), 1)) {
+					t.Fatalf("%s: Matrix[%d][%d] = %v, want %v", f.Name, i, j, matrix[i][j], want)
+				}
+				if matrix[i][j] != matrix[j][i] || i == j && matrix[i][j] != 0 {
+					t.Fatalf("%s: Matrix[%d][%d] = %v but Matrix[%d][%d] = %v", f.Name, i, j, matrix[i][j], j, i, matrix[j][i])
+				}
+			}
+		}
+	}
+}
+
+// The benchmarks measure each formula per pair, calling Distance with
+// degrees, against its batch function, which includes building the Points.
+// Compare the pairs/s of the two with, for example:
+//
+//	go test -run '^$' -bench . ./formulas
+var benchmarkSizes = []int{10, 1000, 100000}
+
+func BenchmarkLegs(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		p := randomPoints(n, 13)
+		latitudes, longitudes := p.lat, p.lon
+		legs := make([]float64, n)
+		for _, f := range All() {
+			b.Run(fmt.Sprintf("%s/pair/%d", f.Name, n), func(b *testing.B) {
+				for b.Loop() {
+					for i := range n {
+						next := (i + 1) % n
+						legs[i] = f.Distance(latitudes[i], longitudes[i], latitudes[next], longitudes[next], 6371)
+					}
+				}
+				b.ReportMetric(float64(n*b.N)/b.Elapsed().Seconds(), "pairs/s")
+			})
+			b.Run(fmt.Sprintf("%s/batch/%d", f.Name, n), func(b *testing.B) {
+				for b.Loop() {
+					legs = f.Legs(legs, mustPoints(latitudes, longitudes), true, 6371)
+				}
+				b.ReportMetric(float64(n*b.N)/b.Elapsed().Seconds(), "pairs/s")
+			})
+		}
+	}
+}
+
+func BenchmarkMatrix(b *testing.B) {
+	const n = 300
+	p := randomPoints(n, 14)
+	latitudes, longitudes := p.lat, p.lon
+	pairs := n * (n - 1) / 2
+	for _, f := range All() {
+		b.Run(f.Name+"/pair", func(b *testing.B) {
+			for b.Loop() {
+				matrix := make([][]float64, n)
+				for i := range matrix {
+					matrix[i] = make([]float64, n)
+				}
+				for i := range n {
+					for j := i + 1; j < n; j++ {
+						d := f.Distance(latitudes[i], longitudes[i], latitudes[j], longitudes[j], 6371)
+						matrix[i][j], matrix[j][i] = d, d
+					}
+				}
+			}
+			b.ReportMetric(float64(pairs*b.N)/b.Elapsed().Seconds(), "pairs/s")
+		})
+		b.Run(f.Name+"/batch", func(b *testing.B) {
+			for b.Loop() {
+				f.Matrix(mustPoints(latitudes, longitudes), 6371)
+			}
+			b.ReportMetric(float64(pairs*b.N)/b.Elapsed().Seconds(), "pairs/s")
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Package formulas provides implementations of various distance calculation
+// formulas for geographical points on a sphere.
+package formulas
+
+import (
+	"math"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// Equirectangular approximates the great-circle distance between two points
+// by projecting them onto a plane with the equirectangular projection
+// centred on their mean latitude, and measuring the straight line between
+// them there. It takes a cosine and a square root, so it is the fastest
+// formula, but its error grows with the length of the leg and towards the
+// poles, and it is only meant for pre-filtering nearby points.
+//
+// Formula is based on:
+// https://en.wikipedia.org/wiki/Equirectangular_projection
+// https://www.movable-type.co.uk/scripts/latlong.html
+//
+// Over the grid of the tests, which pairs points at latitudes within ±70°
+// with points up to 100 km away on the Earth, the distance is within 1e-4
+// of the great-circle distance, relative to it, plus 2e-11 times
+// earthRadius.
+//
+// Coordinates are in degrees, with latitudes within [-90, 90], and
+// earthRadius is in the desired unit.
+func Equirectangular(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
+	lat1Rad := utils.DegreeToRad(lat1)
+	lat2Rad := utils.DegreeToRad(lat2)
+	deltaLon := reduceDeltaLon(utils.DegreeToRad(lon2) - utils.DegreeToRad(lon1))
+
+	x := deltaLon * cosPoly((lat1Rad+lat2Rad)/2)
+	y := lat2Rad - lat1Rad
+	return earthRadius * math.Sqrt(x*x+y*y)
+}
+
+// Equirectangular32 is Equirectangular in float32, for points stored in
+// float32 to halve their memory. Over the grid of the tests, it is within
+// 1e-4 of the great-circle distance, relative to it, plus 1e-6 times
+// earthRadius, or about 6 m on the Earth.
+func Equirectangular32(lat1, lon1, lat2, lon2 float32, earthRadius float32) float32 {
+	lat1Rad := lat1 * (math.Pi / 180)
+	lat2Rad := lat2 * (math.Pi / 180)
+	deltaLon := reduceDeltaLon32((lon2 - lon1) * (math.Pi / 180))
+
+	x := deltaLon * cosPoly32((lat1Rad+lat2Rad)/2)
+	y := lat2Rad - lat1Rad
+	return earthRadius * sqrt32(x*x+y*y)
+}
+
+// equirectangular32 is Equirectangular32 as a Func.
+func equirectangular32(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
+	return float64(Equirectangular32(float32(lat1), float32(lon1), float32(lat2), float32(lon2), float32(earthRadius)))
+}
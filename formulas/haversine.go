@@ -37,3 +37,40 @@ func Haversine(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
 
 	return distance
 }
+
+// HaversinePoints is Haversine for points i and j of p.
+func HaversinePoints(p *Points, i, j int, earthRadius float64) float64 {
+	sinHalfDeltaLat := math.Sin((p.latRad[j] - p.latRad[i]) / 2)
+	sinHalfDeltaLon := math.Sin((p.lonRad[j] - p.lonRad[i]) / 2)
+	a := sinHalfDeltaLat*sinHalfDeltaLat +
+		p.cosLat[i]*p.cosLat[j]*sinHalfDeltaLon*sinHalfDeltaLon
+	return earthRadius * (2 * math.Asin(math.Sqrt(a)))
+}
+
+// HaversineError estimates the numerical error of Haversine for the given
+// points, in the unit of earthRadius.
+//
+// The haversine form is accurate for short distances, but arcsin is badly
+// conditioned near 1, so the error grows for nearly antipodal points.
+func HaversineError(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
+	// Convert degrees to radians.
+	lat1Rad := utils.DegreeToRad(lat1)
+	lon1Rad := utils.DegreeToRad(lon1)
+	lat2Rad := utils.DegreeToRad(lat2)
+	lon2Rad := utils.DegreeToRad(lon2)
+
+	deltaLat := lat2Rad - lat1Rad
+	deltaLon := lon2Rad - lon1Rad
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	c := 2 * math.Asin(math.Sqrt(min(a, 1)))
+
+	// a carries a relative error of a few ulps, which arcsin(sqrt(a))
+	// amplifies by sqrt(a/(1-a)), up to about 2*sqrt(4u) when a rounds to 1
+	amplified := 2 * math.Sqrt(4*unitRoundoff)
+	if 1-a > 0 {
+		amplified = min(amplified, 4*unitRoundoff*math.Sqrt(a/(1-a)))
+	}
+
+	return earthRadius * (amplified + 4*unitRoundoff*c)
+}
@@ -28,3 +28,17 @@ func TestHaversine(t *testing.T) {
 		t.Errorf("got %d, want %d", distance, want)
 	}
 }
+
+func TestHaversineError(t *testing.T) {
+	if got := HaversineError(0, 0, 0, 0, 6371.0); got != 0 {
+		t.Errorf("got %g, want 0 for coincident points", got)
+	}
+	short := HaversineError(40, -105, 40.00000001, -105.00000001, 1)
+	if short > 1e-20 {
+		t.Errorf("got %g, expected a tiny error for a short leg", short)
+	}
+	antipodal := HaversineError(40, -105, -40, 75, 1)
+	if antipodal < 1e-9 || antipodal > 1e-7 {
+		t.Errorf("got %g, expected about 4e-8 for antipodal points", antipodal)
+	}
+}
@@ -0,0 +1,69 @@
+// Package formulas provides implementations of various distance calculation
+// formulas for geographical points on a sphere.
+package formulas
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// Points holds a set of points as parallel slices, together with the
+// trigonometry of every point, so that the batch functions measuring routes
+// and matrices convert and take the sine and cosine of each point once
+// rather than once for every pair it is in. A Points is not modified once
+// built and is safe for concurrent use.
+type Points struct {
+	lat, lon       []float64 // degrees
+	latRad, lonRad []float64
+	sinLat, cosLat []float64
+}
+
+// ErrLengthMismatch is returned by NewPoints when there are not as many
+// latitudes as longitudes.
+var ErrLengthMismatch = errors.New("latitudes and longitudes differ in number")
+
+// NewPoints returns the points with the given latitudes and longitudes, in
+// degrees. It fails with ErrLengthMismatch if the slices have different
+// lengths.
+func NewPoints(latitudes, longitudes []float64) (*Points, error) {
+	if len(latitudes) != len(longitudes) {
+		return nil, fmt.Errorf("%w: %d latitudes but %d longitudes", ErrLengthMismatch, len(latitudes), len(longitudes))
+	}
+	n := len(latitudes)
+	// one allocation for every column
+	columns := make([]float64, 6*n)
+	p := &Points{
+		lat:    columns[0*n : 1*n : 1*n],
+		lon:    columns[1*n : 2*n : 2*n],
+		latRad: columns[2*n : 3*n : 3*n],
+		lonRad: columns[3*n : 4*n : 4*n],
+		sinLat: columns[4*n : 5*n : 5*n],
+		cosLat: columns[5*n : 6*n : 6*n],
+	}
+	copy(p.lat, latitudes)
+	copy(p.lon, longitudes)
+	for i := range n {
+		p.latRad[i] = utils.DegreeToRad(latitudes[i])
+		p.lonRad[i] = utils.DegreeToRad(longitudes[i])
+		p.sinLat[i], p.cosLat[i] = math.Sin(p.latRad[i]), math.Cos(p.latRad[i])
+	}
+	return p, nil
+}
+
+// Len returns the number of points.
+func (p *Points) Len() int {
+	return len(p.lat)
+}
+
+// Lat returns the latitude of point i, in degrees.
+func (p *Points) Lat(i int) float64 {
+	return p.lat[i]
+}
+
+// Lon returns the longitude of point i, in degrees.
+func (p *Points) Lon(i int) float64 {
+	return p.lon[i]
+}
@@ -0,0 +1,31 @@
+package formulas
+
+import (
+	"errors"
+	"testing"
+)
+
+// mustPoints is NewPoints for slices of the same length.
+func mustPoints(latitudes, longitudes []float64) *Points {
+	p, err := NewPoints(latitudes, longitudes)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func TestNewPoints(t *testing.T) {
+	p := mustPoints([]float64{40.7128, 34.0522}, []float64{-74.0060, -118.2437})
+	if p.Len() != 2 || p.Lat(1) != 34.0522 || p.Lon(0) != -74.0060 {
+		t.Errorf("Unexpected points: %d, %v, %v", p.Len(), p.Lat(1), p.Lon(0))
+	}
+	if p := mustPoints(nil, nil); p.Len() != 0 {
+		t.Errorf("got %d points, want 0", p.Len())
+	}
+}
+
+func TestNewPointsMismatch(t *testing.T) {
+	if _, err := NewPoints([]float64{1, 2}, []float64{1}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("expected ErrLengthMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package formulas
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// The property tests check every great-circle formula against invariants of
+// the distance on a sphere, over reproducible random pairs of points that
+// over-sample the cases formulas get wrong. They run on the unit sphere, so
+// distances are central angles in radians, and allow each formula the error
+// its Error function estimates, times errorSlack since the estimates are
+// only accurate to an order of magnitude, plus an absolute roundingSlack for
+// the rounding of converting degrees to radians, which moves points by up to
+// about 1e-15 radians.
+const (
+	propertyPairs = 20000
+	errorSlack    = 4
+	roundingSlack = 1e-14
+)
+
+// greatCircleFormulas are the formulas the invariants hold for.
+var greatCircleFormulas = []string{"haversine", "vincenty", "sloc"}
+
+// pair is two points, in degrees.
+type pair struct {
+	lat1, lon1, lat2, lon2 float64
+}
+
+func (p pair) reversed() pair {
+	return pair{p.lat2, p.lon2, p.lat1, p.lon1}
+}
+
+// randomPairs returns n reproducible pairs of points. One in eight pairs is
+// two uniformly random points, and the others are coincident points,
+// antipodal and nearly antipodal points, pairs with a pole, pairs across the
+// antimeridian, points a few centimetres apart on the Earth and points next
+// to the poles.
+func randomPairs(n int, seed uint64) []pair {
+	r := rand.New(rand.NewPCG(seed, seed))
+	point := func() (lat, lon float64) {
+		return math.Asin(2*r.Float64()-1) * 180 / math.Pi, 360*r.Float64() - 180
+	}
+	pairs := make([]pair, n)
+	for i := range pairs {
+		var p pair
+		p.lat1, p.lon1 = point()
+		switch i % 8 {
+		case 0:
+			p.lat2, p.lon2 = point()
+		case 1:
+			p.lat2, p.lon2 = p.lat1, p.lon1
+		case 2:
+			p.lat2, p.lon2 = -p.lat1, p.lon1+180
+		case 3:
+			p.lat2, p.lon2 = -p.lat1+r.NormFloat64()*1e-6, p.lon1+180+r.NormFloat64()*1e-6
+		case 4:
+			p.lat1 = math.Copysign(90, p.lat1)
+			p.lat2, p.lon2 = point()
+		case 5:
+			p.lon1 = 180 - 5*r.Float64()
+			p.lat2, p.lon2 = p.lat1+r.NormFloat64(), -180+5*r.Float64()
+		case 6:
+			p.lat2, p.lon2 = p.lat1+r.NormFloat64()*1e-7, p.lon1+r.NormFloat64()*1e-7
+		case 7:
+			p.lat1 = math.Copysign(90-r.ExpFloat64()*1e-6, p.lat1)
+			p.lat2, p.lon2 = math.Copysign(90-r.ExpFloat64()*1e-6, -p.lat1), 360*r.Float64()-180
+		}
+		p.lat2 = max(-90, min(p.lat2, 90))
+		p.lon2 = math.Mod(p.lon2+540, 360) - 180
+		pairs[i] = p
+	}
+	return pairs
+}
+
+// referenceAngle is the central angle between the points of p, computed
+// from their unit vectors with the well-conditioned atan2 of the norms of
+// their cross and dot products.
+func referenceAngle(p pair) float64 {
+	vector := func(lat, lon float64) [3]float64 {
+		phi, lambda := utils.DegreeToRad(lat), utils.DegreeToRad(lon)
+		return [3]float64{math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)}
+	}
+	a, b := vector(p.lat1, p.lon1), vector(p.lat2, p.lon2)
+	cross := math.Hypot(math.Hypot(a[1]*b[2]-a[2]*b[1], a[2]*b[0]-a[0]*b[2]), a[0]*b[1]-a[1]*b[0])
+	return math.Atan2(cross, a[0]*b[0]+a[1]*b[1]+a[2]*b[2])
+}
+
+// forEachFormula runs test on every great-circle formula with a function
+// measuring a pair and one returning the tolerance for it.
+func forEachFormula(t *testing.T, test func(t *testing.T, distance, tolerance func(pair) float64)) {
+	for _, name := range greatCircleFormulas {
+		formula, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("%s is not registered", name)
+		}
+		distance := func(p pair) float64 { return formula.Distance(p.lat1, p.lon1, p.lat2, p.lon2, 1) }
+		tolerance := func(p pair) float64 {
+			return errorSlack*formula.Error(p.lat1, p.lon1, p.lat2, p.lon2, 1) + roundingSlack
+		}
+		t.Run(name, func(t *testing.T) { test(t, distance, tolerance) })
+	}
+}
+
+func TestPropertyRange(t *testing.T) {
+	forEachFormula(t, func(t *testing.T, distance, tolerance func(pair) float64) {
+		for _, p := range randomPairs(propertyPairs, 1) {
+			d := distance(p)
+			if !(d >= 0) || d > math.Pi+tolerance(p) {
+				t.Fatalf("%+v: distance %v is outside [0, π]", p, d)
+			}
+			if p.lat1 == p.lat2 && p.lon1 == p.lon2 && d != 0 {
+				t.Fatalf("%+v: coincident points are %v apart", p, d)
+			}
+		}
+	})
+}
+
+func TestPropertySymmetry(t *testing.T) {
+	forEachFormula(t, func(t *testing.T, distance, tolerance func(pair) float64) {
+		for _, p := range randomPairs(propertyPairs, 2) {
+			if d, r := distance(p), distance(p.reversed()); math.Abs(d-r) > tolerance(p) {
+				t.Fatalf("%+v: distance %v there and %v back", p, d, r)
+			}
+		}
+	})
+}
+
+func TestPropertyTriangleInequality(t *testing.T) {
+	forEachFormula(t, func(t *testing.T, distance, tolerance func(pair) float64) {
+		pairs := randomPairs(propertyPairs, 3)
+		for i, ab := range pairs {
+			next := pairs[(i+1)%len(pairs)]
+			bc := pair{ab.lat2, ab.lon2, next.lat1, next.lon1}
+			ac := pair{ab.lat1, ab.lon1, next.lat1, next.lon1}
+			slack := tolerance(ab) + tolerance(bc) + tolerance(ac)
+			if d := distance(ac); d > distance(ab)+distance(bc)+slack {
+				t.Fatalf("%+v then %+v: direct distance %v is longer than %v + %v", ab, bc, d, distance(ab), distance(bc))
+			}
+		}
+	})
+}
+
+func TestPropertyInvariance(t *testing.T) {
+	forEachFormula(t, func(t *testing.T, distance, tolerance func(pair) float64) {
+		r := rand.New(rand.NewPCG(4, 4))
+		for _, p := range randomPairs(propertyPairs, 4) {
+			d := distance(p)
+			// rotating about the axis, across the antimeridian or past it,
+			// and reflecting in the equator keep the distance
+			shift := 720*r.Float64() - 360
+			rotated := pair{p.lat1, p.lon1 + shift, p.lat2, p.lon2 + shift}
+			reflected := pair{-p.lat1, p.lon1, -p.lat2, p.lon2}
+			for _, q := range []pair{rotated, reflected} {
+				if got := distance(q); math.Abs(got-d) > tolerance(p)+tolerance(q) {
+					t.Fatalf("%+v: distance %v, but %v for %+v", p, d, got, q)
+				}
+			}
+		}
+	})
+}
+
+func TestPropertyAgreement(t *testing.T) {
+	forEachFormula(t, func(t *testing.T, distance, tolerance func(pair) float64) {
+		for _, p := range randomPairs(propertyPairs, 5) {
+			if d, want := distance(p), referenceAngle(p); math.Abs(d-want) > tolerance(p) {
+				t.Fatalf("%+v: distance %v, want %v within %v", p, d, want, tolerance(p))
+			}
+		}
+	})
+}
+
+func TestPropertyFormulasAgree(t *testing.T) {
+	pairs := randomPairs(propertyPairs, 8)
+	for i, name := range greatCircleFormulas {
+		f, _ := Lookup(name)
+		for _, other := range greatCircleFormulas[i+1:] {
+			g, _ := Lookup(other)
+			for _, p := range pairs {
+				a, b := f.Distance(p.lat1, p.lon1, p.lat2, p.lon2, 1), g.Distance(p.lat1, p.lon1, p.lat2, p.lon2, 1)
+				tolerance := errorSlack*(f.Error(p.lat1, p.lon1, p.lat2, p.lon2, 1)+g.Error(p.lat1, p.lon1, p.lat2, p.lon2, 1)) + 2*roundingSlack
+				if math.Abs(a-b) > tolerance {
+					t.Fatalf("%+v: %s gives %v and %s %v, more than %v apart", p, name, a, other, b, tolerance)
+				}
+			}
+		}
+	}
+}
+
+func TestPropertyAntipodes(t *testing.T) {
+	forEachFormula(t, func(t *testing.T, distance, tolerance func(pair) float64) {
+		for _, p := range randomPairs(propertyPairs, 6) {
+			antipodal := pair{p.lat1, p.lon1, -p.lat1, p.lon1 + 180}
+			if d := distance(antipodal); math.Abs(d-math.Pi) > tolerance(antipodal) {
+				t.Fatalf("%+v: antipodes are %v apart, want π", antipodal, d)
+			}
+		}
+	})
+}
+
+func TestPropertyScale(t *testing.T) {
+	for _, name := range greatCircleFormulas {
+		formula, _ := Lookup(name)
+		for _, p := range randomPairs(1000, 7) {
+			unit := formula.Distance(p.lat1, p.lon1, p.lat2, p.lon2, 1)
+			if d := formula.Distance(p.lat1, p.lon1, p.lat2, p.lon2, utils.DefaultEarthRadius); math.Abs(d-unit*utils.DefaultEarthRadius) > 2*unitRoundoff*d {
+				t.Fatalf("%s %+v: %v on the Earth, but %v on the unit sphere", name, p, d, unit)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package formulas provides implementations of various distance calculation
+// formulas for geographical points on a sphere.
+package formulas
+
+import "slices"
+
+// Func calculates the great-circle distance between two points given in
+// degrees, on a sphere of radius earthRadius. Haversine, Vincenty and
+// SphericalLawOfCosines are all Funcs.
+type Func func(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64
+
+// unitRoundoff is the largest relative rounding error of a single float64
+// operation, 2⁻⁵³.
+const unitRoundoff = 0x1p-53
+
+// Formula is a distance formula registered under a name.
+type Formula struct {
+	Name     string
+	Distance Func
+	// Error estimates the numerical error of Distance for the given points,
+	// in the unit of earthRadius, or is nil when no estimate is available.
+	// Estimates are meant to flag ill-conditioned inputs and are only
+	// accurate to an order of magnitude.
+	Error Func
+	// Batch is Distance for the points of a Points, or is nil when the
+	// formula has no batch function, in which case Legs, From and Matrix
+	// call Distance for every pair.
+	Batch PointsFunc
+	// Approximate is true for formulas that trade accuracy for speed, whose
+	// error is bounded in their documentation rather than estimated by
+	// Error, which is nil for them.
+	Approximate bool
+}
+
+// registry holds the registered formulas in registration order.
+var registry = []Formula{
+	{Name: "haversine", Distance: Haversine, Error: HaversineError, Batch: HaversinePoints},
+	{Name: "vincenty", Distance: Vincenty, Error: VincentyError, Batch: VincentyPoints},
+	{Name: "sloc", Distance: SphericalLawOfCosines, Error: SphericalLawOfCosinesError, Batch: SphericalLawOfCosinesPoints},
+	{Name: "fasthaversine", Distance: FastHaversine, Approximate: true},
+	{Name: "equirectangular", Distance: Equirectangular, Approximate: true},
+	{Name: "fasthaversine32", Distance: fastHaversine32, Approximate: true},
+	{Name: "equirectangular32", Distance: equirectangular32, Approximate: true},
+}
+
+// Register adds a formula, replacing any formula already registered under
+// the same name. It is not safe to call concurrently with the other
+// functions in this file and is meant to be called from init functions.
+func Register(formula Formula) {
+	i := slices.IndexFunc(registry, func(f Formula) bool { return f.Name == formula.Name })
+	if i >= 0 {
+		registry[i] = formula
+		return
+	}
+	registry = append(registry, formula)
+}
+
+// Lookup returns the formula registered under name.
+func Lookup(name string) (Formula, bool) {
+	i := slices.IndexFunc(registry, func(f Formula) bool { return f.Name == name })
+	if i < 0 {
+		return Formula{}, false
+	}
+	return registry[i], true
+}
+
+// All returns every registered formula in registration order.
+func All() []Formula {
+	return slices.Clone(registry)
+}
+
+// Names returns the names of every registered formula in registration order.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, f := range registry {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// ExactNames returns the names of the registered formulas that are not
+// Approximate, in registration order.
+func ExactNames() []string {
+	var names []string
+	for _, f := range registry {
+		if !f.Approximate {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
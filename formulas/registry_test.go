@@ -0,0 +1,39 @@
+package formulas
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"haversine", "vincenty", "sloc", "fasthaversine", "equirectangular", "fasthaversine32", "equirectangular32"} {
+		formula, ok := Lookup(name)
+		if !ok || formula.Name != name || formula.Distance == nil {
+			t.Errorf("Lookup(%q) = %+v, %t", name, formula, ok)
+		}
+	}
+	if _, ok := Lookup("invalid"); ok {
+		t.Errorf("Lookup(\"invalid\") succeeded")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	saved := All()
+	defer func() { registry = saved }()
+
+	zero := func(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 { return 0 }
+	Register(Formula{Name: "zero", Distance: zero})
+	Register(Formula{Name: "haversine", Distance: zero})
+
+	want := []string{"haversine", "vincenty", "sloc", "fasthaversine", "equirectangular", "fasthaversine32", "equirectangular32", "zero"}
+	if got := Names(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got, want := ExactNames(), []string{"haversine", "vincenty", "sloc", "zero"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	formula, _ := Lookup("haversine")
+	if formula.Distance(0, 0, 10, 10, 6371) != 0 {
+		t.Fatalf("expected haversine to be replaced")
+	}
+}
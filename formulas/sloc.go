@@ -8,15 +8,75 @@ import (
 	"github.com/dickeyy/go-distances/utils"
 )
 
+// slocSmallAngle is the central angle, in radians, below which
+// SphericalLawOfCosines switches to the haversine form. Below it, acos of a
+// value so close to 1 would lose more than four digits of precision.
+const slocSmallAngle = 1e-2
+
+// slocSmallAngleCos is the cosine of slocSmallAngle.
+var slocSmallAngleCos = math.Cos(slocSmallAngle)
+
 // SphericalLawOfCosines calculates the great-circle distance between two points
 // using the spherical law of cosines.
 //
+// The argument of arccos is clamped to [-1, 1], so rounding can never produce
+// NaN, and for central angles below 0.01 radians the haversine form is used
+// instead, as arccos is badly conditioned near 1.
+//
 // Formula is based on:
 // https://en.wikipedia.org/wiki/Great-circle_distance
 // https://en.wikipedia.org/wiki/Spherical_law_of_cosines
 //
 // Coordinates are in degrees, and earthRadius is in the desired unit.
 func SphericalLawOfCosines(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
+	cosSigma := slocCosSigma(lat1, lon1, lat2, lon2)
+	if cosSigma > slocSmallAngleCos {
+		return Haversine(lat1, lon1, lat2, lon2, earthRadius)
+	}
+
+	// Spherical Law of Cosines formula
+	deltaSigma := math.Acos(cosSigma)
+
+	// Distance
+	distance := float64(earthRadius) * deltaSigma
+
+	return distance
+}
+
+// SphericalLawOfCosinesPoints is SphericalLawOfCosines for points i and j
+// of p.
+func SphericalLawOfCosinesPoints(p *Points, i, j int, earthRadius float64) float64 {
+	cosSigma := p.sinLat[i]*p.sinLat[j] + p.cosLat[i]*p.cosLat[j]*math.Cos(p.lonRad[j]-p.lonRad[i])
+	cosSigma = max(-1, min(1, cosSigma))
+	if cosSigma > slocSmallAngleCos {
+		return HaversinePoints(p, i, j, earthRadius)
+	}
+	return earthRadius * math.Acos(cosSigma)
+}
+
+// SphericalLawOfCosinesError estimates the numerical error of
+// SphericalLawOfCosines for the given points, in the unit of earthRadius.
+//
+// arccos amplifies the rounding error of its argument by 1/sin(Δσ), which
+// grows without bound near antipodal points.
+func SphericalLawOfCosinesError(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
+	cosSigma := slocCosSigma(lat1, lon1, lat2, lon2)
+	if cosSigma > slocSmallAngleCos {
+		return HaversineError(lat1, lon1, lat2, lon2, earthRadius)
+	}
+
+	deltaSigma := math.Acos(cosSigma)
+	amplified := 4 * unitRoundoff / math.Sqrt(1-cosSigma*cosSigma)
+	// the error of arccos near -1 is at most that of a perturbation of
+	// 4 ulps in its argument, about sqrt(8u)
+	amplified = min(amplified, math.Sqrt(8*unitRoundoff))
+
+	return earthRadius * (amplified + 4*unitRoundoff*deltaSigma)
+}
+
+// slocCosSigma returns the cosine of the central angle between two points,
+// clamped to [-1, 1].
+func slocCosSigma(lat1, lon1, lat2, lon2 float64) float64 {
 	// Convert degrees to radians.
 	lat1Rad := utils.DegreeToRad(lat1)
 	lon1Rad := utils.DegreeToRad(lon1)
@@ -26,14 +86,9 @@ func SphericalLawOfCosines(lat1, lon1, lat2, lon2 float64, earthRadius float64)
 	// Difference in longitude
 	deltaLon := lon2Rad - lon1Rad
 
-	// Spherical Law of Cosines formula
-	deltaSigma := math.Acos(
-		math.Sin(lat1Rad)*math.Sin(lat2Rad) +
-			math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Cos(deltaLon),
-	)
-
-	// Distance
-	distance := float64(earthRadius) * deltaSigma
+	cosSigma := math.Sin(lat1Rad)*math.Sin(lat2Rad) +
+		math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Cos(deltaLon)
 
-	return distance
+	// Rounding can push the value just past ±1, where arccos is NaN.
+	return max(-1, min(1, cosSigma))
 }
@@ -28,3 +28,38 @@ func TestSLOC(t *testing.T) {
 		t.Errorf("got %d, want %d", distance, want)
 	}
 }
+
+// sin²φ + cos²φ rounds to just above 1 for this latitude, which used to make
+// math.Acos return NaN for coincident points.
+func TestSLOCCoincidentPointsNotNaN(t *testing.T) {
+	lat, lon := -88.9863, -178.9709
+	distance := SphericalLawOfCosines(lat, lon, lat, lon, 6371.0)
+	if distance != 0 {
+		t.Errorf("got %f, want 0", distance)
+	}
+}
+
+func TestSLOCShortDistance(t *testing.T) {
+	lat1, lon1 := 40.0, -105.0
+	lat2, lon2 := 40.00000001, -105.00000001
+	distance := SphericalLawOfCosines(lat1, lon1, lat2, lon2, 6371000.0)
+	want := Haversine(lat1, lon1, lat2, lon2, 6371000.0)
+	if math.Abs(distance-want) > 1e-12 {
+		t.Errorf("got %g, want %g", distance, want)
+	}
+}
+
+func TestSLOCAntipodal(t *testing.T) {
+	distance := SphericalLawOfCosines(40, -105, -40, 75, 1)
+	if math.Abs(distance-math.Pi) > 1e-7 {
+		t.Errorf("got %.12f, want %.12f", distance, math.Pi)
+	}
+}
+
+func TestSLOCErrorIllConditionedNearAntipode(t *testing.T) {
+	antipodal := SphericalLawOfCosinesError(40, -105, -40, 75, 1)
+	regular := SphericalLawOfCosinesError(40, -105, 0, 0, 1)
+	if antipodal < 1e-9 || regular > 1e-14 {
+		t.Errorf("got antipodal %g, regular %g", antipodal, regular)
+	}
+}
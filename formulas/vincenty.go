@@ -36,3 +36,28 @@ func Vincenty(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
 	// Distance = radius * central angle
 	return float64(earthRadius) * centralAngle
 }
+
+// VincentyPoints is Vincenty for points i and j of p.
+func VincentyPoints(p *Points, i, j int, earthRadius float64) float64 {
+	deltaLon := p.lonRad[j] - p.lonRad[i]
+	sinDeltaLon, cosDeltaLon := math.Sin(deltaLon), math.Cos(deltaLon)
+
+	A := p.cosLat[j] * sinDeltaLon
+	B := p.cosLat[i]*p.sinLat[j] - p.sinLat[i]*p.cosLat[j]*cosDeltaLon
+	C := p.sinLat[i]*p.sinLat[j] + p.cosLat[i]*p.cosLat[j]*cosDeltaLon
+	return earthRadius * math.Atan2(math.Sqrt(A*A+B*B), C)
+}
+
+// VincentyError estimates the numerical error of Vincenty for the given
+// points, in the unit of earthRadius.
+//
+// atan2 is well conditioned everywhere, but the numerator is a difference of
+// products of order one, so it carries an absolute error of a couple of ulps
+// that dominates for very short distances.
+func VincentyError(lat1, lon1, lat2, lon2 float64, earthRadius float64) float64 {
+	centralAngle := Vincenty(lat1, lon1, lat2, lon2, 1)
+	if centralAngle == 0 {
+		return 0
+	}
+	return earthRadius * (2*unitRoundoff + 4*unitRoundoff*centralAngle)
+}
@@ -28,3 +28,17 @@ func TestVincenty(t *testing.T) {
 		t.Errorf("got %d, want %d", distance, want)
 	}
 }
+
+func TestVincentyError(t *testing.T) {
+	if got := VincentyError(0, 0, 0, 0, 6371.0); got != 0 {
+		t.Errorf("got %g, want 0 for coincident points", got)
+	}
+	short := VincentyError(40, -105, 40.00000001, -105.00000001, 1)
+	if short < 1e-16 {
+		t.Errorf("got %g, expected an absolute error floor for a short leg", short)
+	}
+	antipodal := VincentyError(40, -105, -40, 75, 1)
+	if antipodal > 1e-14 {
+		t.Errorf("got %g, expected a small error for antipodal points", antipodal)
+	}
+}
@@ -0,0 +1,41 @@
+// Package geo is the library behind go-distances. It models places as Points
+// and routes as Paths, and computes great-circle distances between them with
+// a Calculator configured with a formula and a celestial Body.
+package geo
+
+import (
+	"math"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// InitialBearing returns the direction of the great circle from a to b as it
+// leaves a, in degrees clockwise from north in [0, 360). The bearing between
+// coincident points is 0.
+func InitialBearing(a, b Point) float64 {
+	phi1, phi2 := utils.DegreeToRad(a.Lat), utils.DegreeToRad(b.Lat)
+	deltaLambda := utils.DegreeToRad(b.Lon - a.Lon)
+	y := math.Sin(deltaLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(deltaLambda)
+	return normalizeBearing(utils.RadToDegree(math.Atan2(y, x)))
+}
+
+// FinalBearing returns the direction of the great circle from a to b as it
+// arrives at b, in degrees clockwise from north in [0, 360). It differs from
+// InitialBearing because great circles are not rhumb lines.
+func FinalBearing(a, b Point) float64 {
+	return normalizeBearing(InitialBearing(b, a) + 180)
+}
+
+// normalizeBearing maps a bearing in degrees into [0, 360).
+func normalizeBearing(bearing float64) float64 {
+	bearing = math.Mod(bearing, 360)
+	if bearing < 0 {
+		bearing += 360
+	}
+	if bearing == 360 {
+		// -tiny + 360 rounds up to 360
+		bearing = 0
+	}
+	return bearing
+}
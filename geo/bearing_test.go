@@ -0,0 +1,36 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInitialBearing(t *testing.T) {
+	tests := []struct {
+		a, b Point
+		want float64
+	}{
+		{NewPoint(0, 0), NewPoint(1, 0), 0},
+		{NewPoint(0, 0), NewPoint(0, 1), 90},
+		{NewPoint(0, 0), NewPoint(-1, 0), 180},
+		{NewPoint(0, 0), NewPoint(0, -1), 270},
+		{NewPoint(0, 179), NewPoint(0, -179), 90}, // across the antimeridian
+		{NewPoint(0, 0), NewPoint(0, 0), 0},
+		// Baghdad to Osaka, from Vincenty's and Veness's worked examples
+		{NewPoint(35, 45), NewPoint(35, 135), 60.16},
+	}
+	for _, tt := range tests {
+		if got := InitialBearing(tt.a, tt.b); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("InitialBearing(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFinalBearing(t *testing.T) {
+	if got := FinalBearing(NewPoint(35, 45), NewPoint(35, 135)); math.Abs(got-119.84) > 0.01 {
+		t.Errorf("FinalBearing = %v, want 119.84", got)
+	}
+	if got := FinalBearing(NewPoint(0, 0), NewPoint(0, 1)); math.Abs(got-90) > 1e-9 {
+		t.Errorf("FinalBearing along the equator = %v, want 90", got)
+	}
+}
@@ -0,0 +1,75 @@
+// Package geo is the library behind go-distances. It models places as Points
+// and routes as Paths, and computes great-circle distances between them with
+// a Calculator configured with a formula and a celestial Body.
+package geo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownUnit is returned when a length in metres, such as an elevation,
+// cannot be converted into the unit of a body because it is not known.
+var ErrUnknownUnit = errors.New("unit of the radius is not known")
+
+// Body is a sphere that distances are measured on.
+type Body struct {
+	Name   string  `json:"name"`
+	Radius float64 `json:"radius"`
+	// Unit names the unit of Radius, and so of every distance measured on
+	// the body. It is empty when the unit is not known.
+	Unit string `json:"unit,omitempty"`
+}
+
+// Mean radii of some common bodies, in kilometres.
+var (
+	Earth = Body{Name: "Earth", Radius: 6371.0, Unit: "km"}
+	Moon  = Body{Name: "Moon", Radius: 1737.4, Unit: "km"}
+	Mars  = Body{Name: "Mars", Radius: 3389.5, Unit: "km"}
+)
+
+// WithRadius returns a copy of the body with a different radius, in the
+// given unit, which is empty when it is not known.
+func (b Body) WithRadius(radius float64, unit string) Body {
+	return Body{Name: b.Name, Radius: radius, Unit: unit}
+}
+
+// UnitName returns the unit of the body, or "units" when it is not known.
+func (b Body) UnitName() string {
+	if b.Unit == "" {
+		return "units"
+	}
+	return b.Unit
+}
+
+// metresPerUnit is the length in metres of the units FromMetres knows.
+var metresPerUnit = map[string]float64{
+	"m":   1,
+	"km":  1000,
+	"mi":  1609.344,
+	"nmi": 1852,
+	"ft":  0.3048,
+}
+
+// FromMetres converts a length in metres, such as an elevation, into the
+// unit of the body. It returns an error wrapping ErrUnknownUnit when the
+// unit is not one of those in metresPerUnit.
+func (b Body) FromMetres(metres float64) (float64, error) {
+	perUnit, err := b.metresPerUnit()
+	return metres / perUnit, err
+}
+
+// ToMetres converts a length in the unit of the body into metres. It is the
+// inverse of FromMetres.
+func (b Body) ToMetres(length float64) (float64, error) {
+	perUnit, err := b.metresPerUnit()
+	return length * perUnit, err
+}
+
+func (b Body) metresPerUnit() (float64, error) {
+	perUnit, ok := metresPerUnit[b.Unit]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownUnit, b.Unit)
+	}
+	return perUnit, nil
+}
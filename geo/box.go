@@ -0,0 +1,80 @@
+// Package geo is the library behind go-distances. It models places as Points
+// and routes as Paths, and computes great-circle distances between them with
+// a Calculator configured with a formula and a celestial Body.
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Box is a latitude/longitude bounding box, in degrees. A box whose MinLon
+// is greater than its MaxLon crosses the antimeridian.
+type Box struct {
+	MinLat float64 `json:"minLat"`
+	MinLon float64 `json:"minLon"`
+	MaxLat float64 `json:"maxLat"`
+	MaxLon float64 `json:"maxLon"`
+}
+
+// Validate reports whether the box has latitudes in [-90, 90], longitudes in
+// [-180, 180] and MinLat no greater than MaxLat. NaNs are in no range.
+func (b Box) Validate() error {
+	switch {
+	case !(math.Abs(b.MinLat) <= 90) || !(math.Abs(b.MaxLat) <= 90):
+		return fmt.Errorf("box latitudes must be within [-90, 90]: %v", b)
+	case !(math.Abs(b.MinLon) <= 180) || !(math.Abs(b.MaxLon) <= 180):
+		return fmt.Errorf("box longitudes must be within [-180, 180]: %v", b)
+	case b.MinLat > b.MaxLat:
+		return fmt.Errorf("box minimum latitude is above its maximum: %v", b)
+	}
+	return nil
+}
+
+// Contains reports whether p lies inside the box, edges included.
+func (b Box) Contains(p Point) bool {
+	if p.Lat < b.MinLat || p.Lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return p.Lon >= b.MinLon && p.Lon <= b.MaxLon
+	}
+	return p.Lon >= b.MinLon || p.Lon <= b.MaxLon
+}
+
+// lonSpan returns the width of the box in degrees of longitude.
+func (b Box) lonSpan() float64 {
+	if b.MinLon <= b.MaxLon {
+		return b.MaxLon - b.MinLon
+	}
+	return 360 - b.MinLon + b.MaxLon
+}
+
+// Center returns the point halfway between the edges of the box.
+func (b Box) Center() Point {
+	lon := b.MinLon + b.lonSpan()/2
+	if lon > 180 {
+		lon -= 360
+	}
+	return NewPoint((b.MinLat+b.MaxLat)/2, lon)
+}
+
+// CentralAngle returns an upper bound, in radians, on the central angle
+// between the box's Center and any point inside the box.
+func (b Box) CentralAngle() float64 {
+	const rad = math.Pi / 180
+	halfLat := (b.MaxLat - b.MinLat) / 2 * rad
+	halfLon := b.lonSpan() / 2 * rad
+	center := b.Center()
+
+	// the largest cosine of any latitude in the box
+	maxCos := math.Max(math.Cos(b.MinLat*rad), math.Cos(b.MaxLat*rad))
+	if b.MinLat <= 0 && b.MaxLat >= 0 {
+		maxCos = 1
+	}
+
+	// hav(σ) = hav(Δφ) + cos φ₁ cos φ₂ hav(Δλ), bounded term by term
+	hav := func(x float64) float64 { return math.Pow(math.Sin(x/2), 2) }
+	bound := hav(halfLat) + math.Cos(center.Lat*rad)*maxCos*hav(math.Min(halfLon, math.Pi))
+	return 2 * math.Asin(math.Sqrt(math.Min(bound, 1)))
+}
@@ -0,0 +1,54 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoxContains(t *testing.T) {
+	box := Box{MinLat: 10, MinLon: 20, MaxLat: 30, MaxLon: 40}
+	if !box.Contains(NewPoint(10, 40)) || !box.Contains(NewPoint(20, 30)) {
+		t.Errorf("expected points inside %v", box)
+	}
+	if box.Contains(NewPoint(5, 30)) || box.Contains(NewPoint(20, 41)) {
+		t.Errorf("expected points outside %v", box)
+	}
+}
+
+func TestBoxContainsAntimeridian(t *testing.T) {
+	box := Box{MinLat: -10, MinLon: 170, MaxLat: 10, MaxLon: -170}
+	if !box.Contains(NewPoint(0, 175)) || !box.Contains(NewPoint(0, -175)) {
+		t.Errorf("expected points inside %v", box)
+	}
+	if box.Contains(NewPoint(0, 0)) {
+		t.Errorf("expected point outside %v", box)
+	}
+	if center := box.Center(); center.Lat != 0 || math.Abs(math.Abs(center.Lon)-180) > 1e-12 {
+		t.Errorf("got center %+v, want 0, ±180", center)
+	}
+}
+
+func TestBoxValidate(t *testing.T) {
+	if err := (Box{MinLat: -10, MinLon: 170, MaxLat: 10, MaxLon: -170}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, box := range []Box{{MinLat: -91}, {MaxLon: 181}, {MinLat: 10, MaxLat: 0}, {MinLat: math.NaN()}, {MinLon: math.Inf(1)}} {
+		if box.Validate() == nil {
+			t.Errorf("expected %v to be invalid", box)
+		}
+	}
+}
+
+func TestBoxCentralAngle(t *testing.T) {
+	box := Box{MinLat: 40, MinLon: -80, MaxLat: 50, MaxLon: -60}
+	calculator, _ := NewCalculator("haversine", Earth.WithRadius(1, ""))
+	bound := box.CentralAngle()
+	center := box.Center()
+	for lat := 40.0; lat <= 50; lat += 0.5 {
+		for lon := -80.0; lon <= -60; lon += 0.5 {
+			if d := calculator.Distance(center, NewPoint(lat, lon)); d > bound {
+				t.Fatalf("point %f,%f is %f from the center, beyond the bound %f", lat, lon, d, bound)
+			}
+		}
+	}
+}
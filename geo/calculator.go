@@ -0,0 +1,288 @@
+// Package geo is the library behind go-distances. It models places as Points
+// and routes as Paths, and computes great-circle distances between them with
+// a Calculator configured with a formula and a celestial Body.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dickeyy/go-distances/formulas"
+)
+
+// StoppedSpeed is the speed, in metres per second, below which a leg counts
+// as stopped rather than moving, so that time spent standing still with a
+// wandering GPS fix does not count towards a result's MovingTime.
+const StoppedSpeed = 0.2
+
+var (
+	// ErrUnknownFormula is returned for formula names that are not
+	// registered in the formulas package.
+	ErrUnknownFormula = errors.New("unknown formula")
+	// ErrTooFewPoints is returned when a route has fewer than two points.
+	ErrTooFewPoints = errors.New("at least two points are required")
+)
+
+// Calculator measures distances with one formula on one body.
+type Calculator struct {
+	Formula formulas.Formula
+	Body    Body
+}
+
+// NewCalculator returns a Calculator using the formula registered under the
+// given name.
+func NewCalculator(formula string, body Body) (*Calculator, error) {
+	f, ok := formulas.Lookup(formula)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormula, formula)
+	}
+	return &Calculator{Formula: f, Body: body}, nil
+}
+
+// Distance returns the great-circle distance between a and b, in the unit of
+// the calculator's body.
+func (c *Calculator) Distance(a, b Point) float64 {
+	return c.Formula.Distance(a.Lat, a.Lon, b.Lat, b.Lon, c.Body.Radius)
+}
+
+// points returns the points of path for the batch functions of the
+// calculator's formula, or nil when it has none.
+func (c *Calculator) points(path Path) *formulas.Points {
+	if c.Formula.Batch == nil {
+		return nil
+	}
+	points, err := formulas.NewPoints(path.Latitudes(), path.Longitudes())
+	if err != nil {
+		// both slices have one coordinate per point of path
+		panic(fmt.Sprintf("geo: %v", err))
+	}
+	return points
+}
+
+// legs returns the distances of the legs of the route through path, with a
+// last leg back to the first point when closed, measured in one batch when
+// the formula has a batch function.
+func (c *Calculator) legs(path Path, closed bool) []float64 {
+	if points := c.points(path); points != nil {
+		return c.Formula.Legs(nil, points, closed, c.Body.Radius)
+	}
+	var legs []float64
+	for i := 1; i < len(path); i++ {
+		legs = append(legs, c.Distance(path[i-1], path[i]))
+	}
+	if closed && len(path) > 0 {
+		legs = append(legs, c.Distance(path[len(path)-1], path[0]))
+	}
+	return legs
+}
+
+// Length returns the length of the open route that visits every point of
+// path in order, in the unit of the calculator's body.
+func (c *Calculator) Length(path Path) float64 {
+	var length float64
+	for _, d := range c.legs(path, false) {
+		length += d
+	}
+	return length
+}
+
+// Matrix returns the distance between every pair of points of path, in the
+// unit of the calculator's body: Matrix(path)[i][j] is the distance from
+// path[i] to path[j]. Each pair is measured once, so the matrix is exactly
+// symmetric.
+func (c *Calculator) Matrix(path Path) [][]float64 {
+	if points := c.points(path); points != nil {
+		return c.Formula.Matrix(points, c.Body.Radius)
+	}
+	matrix := make([][]float64, len(path))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(path))
+	}
+	for i := range path {
+		for j := i + 1; j < len(path); j++ {
+			d := c.Distance(path[i], path[j])
+			matrix[i][j], matrix[j][i] = d, d
+		}
+	}
+	return matrix
+}
+
+// Error estimates the numerical error of Distance(a, b), in the unit of the
+// calculator's body. It returns 0 when the formula has no error estimate.
+func (c *Calculator) Error(a, b Point) float64 {
+	if c.Formula.Error == nil {
+		return 0
+	}
+	return c.Formula.Error(a.Lat, a.Lon, b.Lat, b.Lon, c.Body.Radius)
+}
+
+// Leg is the stretch of a route between two consecutive points.
+type Leg struct {
+	FromIndex int     `json:"fromIndex"`
+	ToIndex   int     `json:"toIndex"`
+	From      Point   `json:"from"`
+	To        Point   `json:"to"`
+	Distance  float64 `json:"distance"`
+	// Slant is the length of the straight line between the endpoints of the
+	// unrolled leg, √(Distance² + climb²), where climb is the change in
+	// elevation. It equals Distance when either elevation is unknown.
+	Slant float64 `json:"slant"`
+	// Error is the estimated numerical error of Distance.
+	Error float64 `json:"error"`
+}
+
+// Climb returns the change in elevation from the start to the end of the
+// leg, negative when descending. ok is false when either elevation is
+// unknown.
+func (l Leg) Climb() (climb float64, ok bool) {
+	if l.From.Elevation == nil || l.To.Elevation == nil {
+		return 0, false
+	}
+	return *l.To.Elevation - *l.From.Elevation, true
+}
+
+// Duration returns the time taken to travel the leg. ok is false when either
+// time is unknown or the leg ends before it starts, as the closing leg of a
+// circular route over a track does.
+func (l Leg) Duration() (d time.Duration, ok bool) {
+	if l.From.Time == nil || l.To.Time == nil || l.To.Time.Before(*l.From.Time) {
+		return 0, false
+	}
+	return l.To.Time.Sub(*l.From.Time), true
+}
+
+// Speed returns the average speed over the leg, in units of the body's
+// radius per hour. ok is false when the duration is unknown or zero.
+func (l Leg) Speed() (speed float64, ok bool) {
+	d, ok := l.Duration()
+	if !ok || d == 0 {
+		return 0, false
+	}
+	return l.Distance / d.Hours(), true
+}
+
+// Pace returns the time taken per unit of the body's radius over the leg.
+// ok is false when the duration is unknown or the leg has zero length.
+func (l Leg) Pace() (pace time.Duration, ok bool) {
+	d, ok := l.Duration()
+	if !ok || l.Distance == 0 {
+		return 0, false
+	}
+	return time.Duration(float64(d) / l.Distance), true
+}
+
+// RelativeError returns the estimated error of the leg relative to its
+// distance, or 0 for legs of zero length.
+func (l Leg) RelativeError() float64 {
+	if l.Distance == 0 {
+		return 0
+	}
+	return l.Error / l.Distance
+}
+
+// Result is the outcome of measuring a route.
+type Result struct {
+	Formula string  `json:"formula"`
+	Body    Body    `json:"body"`
+	Legs    []Leg   `json:"legs"`
+	Total   float64 `json:"total"`
+	// SlantTotal is the sum of the slant distances of the legs.
+	SlantTotal float64 `json:"slantTotal"`
+	// Ascent and Descent are the total climb and drop over the legs whose
+	// elevations are known, both positive.
+	Ascent  float64 `json:"ascent"`
+	Descent float64 `json:"descent"`
+	// Duration is the total time of the legs whose duration is known, and
+	// MovingTime and MovingDistance the time and distance of those legs
+	// travelled at StoppedSpeed or faster.
+	Duration       time.Duration `json:"duration"`
+	MovingTime     time.Duration `json:"movingTime"`
+	MovingDistance float64       `json:"movingDistance"`
+	// MaxSpeed is the highest average speed of a leg, in units of the
+	// body's radius per hour.
+	MaxSpeed float64 `json:"maxSpeed"`
+}
+
+// HasElevation reports whether any leg of the result has known elevations
+// at both ends.
+func (r *Result) HasElevation() bool {
+	for _, leg := range r.Legs {
+		if _, ok := leg.Climb(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTime reports whether any leg of the result has a known duration.
+func (r *Result) HasTime() bool {
+	for _, leg := range r.Legs {
+		if _, ok := leg.Duration(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MovingSpeed returns the average speed while moving, in units of the
+// body's radius per hour. ok is false when there is no moving time.
+func (r *Result) MovingSpeed() (speed float64, ok bool) {
+	if r.MovingTime == 0 {
+		return 0, false
+	}
+	return r.MovingDistance / r.MovingTime.Hours(), true
+}
+
+// Circular measures the closed route that visits every point of path in
+// order and then returns to the first point, so the result has one leg per
+// point. Timed legs need the unit of the body, to compare their speed with
+// StoppedSpeed: without it the error wraps ErrUnknownUnit.
+func (c *Calculator) Circular(path Path) (*Result, error) {
+	if len(path) < 2 {
+		return nil, ErrTooFewPoints
+	}
+
+	result := &Result{Formula: c.Formula.Name, Body: c.Body, Legs: make([]Leg, len(path))}
+	distances := c.legs(path, true)
+	// in units of the radius per hour, only known when the unit is
+	stopped, unitErr := c.Body.FromMetres(StoppedSpeed * 3600)
+	for i := range path {
+		next := (i + 1) % len(path)
+		leg := Leg{
+			FromIndex: i,
+			ToIndex:   next,
+			From:      path[i],
+			To:        path[next],
+			Distance:  distances[i],
+			Error:     c.Error(path[i], path[next]),
+		}
+		leg.Slant = leg.Distance
+		if climb, ok := leg.Climb(); ok {
+			leg.Slant = math.Hypot(leg.Distance, climb)
+			if climb > 0 {
+				result.Ascent += climb
+			} else {
+				result.Descent -= climb
+			}
+		}
+		if d, ok := leg.Duration(); ok {
+			result.Duration += d
+			if speed, ok := leg.Speed(); ok {
+				if unitErr != nil {
+					return nil, fmt.Errorf("cannot tell moving legs from stopped ones: %w", unitErr)
+				}
+				result.MaxSpeed = max(result.MaxSpeed, speed)
+				if speed >= stopped {
+					result.MovingTime += d
+					result.MovingDistance += leg.Distance
+				}
+			}
+		}
+		result.Legs[i] = leg
+		result.Total += leg.Distance
+		result.SlantTotal += leg.Slant
+	}
+	return result, nil
+}
@@ -0,0 +1,302 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dickeyy/go-distances/formulas"
+)
+
+var testPath = Path{
+	{Name: "New York", Lat: 40.7128, Lon: -74.0060},
+	{Name: "Los Angeles", Lat: 34.0522, Lon: -118.2437},
+	{Name: "Chicago", Lat: 41.8781, Lon: -87.6298},
+}
+
+func TestNewCalculatorUnknownFormula(t *testing.T) {
+	_, err := NewCalculator("invalid", Earth)
+	if !errors.Is(err, ErrUnknownFormula) {
+		t.Fatalf("Expected ErrUnknownFormula, got %v", err)
+	}
+}
+
+// based on kdickey.json
+func TestCalculatorDistance(t *testing.T) {
+	calculator, err := NewCalculator("haversine", Earth.WithRadius(6967404.0, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewPoint(75.20479441439075, -87.42362995032933)
+	b := NewPoint(-63.27864890563778, -9.284284051915705)
+
+	distance := int(math.Round(calculator.Distance(a, b)))
+	want := 17892709
+	if distance != want {
+		t.Errorf("got %d, want %d", distance, want)
+	}
+}
+
+func TestCalculatorCircular(t *testing.T) {
+	calculator, err := NewCalculator("vincenty", Earth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := calculator.Circular(testPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Formula != "vincenty" || result.Body != Earth {
+		t.Errorf("Unexpected formula or body: %s %+v", result.Formula, result.Body)
+	}
+	if len(result.Legs) != 3 {
+		t.Fatalf("Expected 3 legs, got %d", len(result.Legs))
+	}
+
+	want := []int{3936, 2804, 1144}
+	total := 0.0
+	for i, leg := range result.Legs {
+		if leg.FromIndex != i || leg.ToIndex != (i+1)%3 {
+			t.Errorf("leg %d: got %d -> %d", i, leg.FromIndex, leg.ToIndex)
+		}
+		if leg.From.Name != testPath[i].Name || leg.To.Name != testPath[(i+1)%3].Name {
+			t.Errorf("leg %d: got %s -> %s", i, leg.From.Name, leg.To.Name)
+		}
+		if got := int(math.Round(leg.Distance)); got != want[i] {
+			t.Errorf("leg %d: got %d, want %d", i, got, want[i])
+		}
+		total += leg.Distance
+	}
+	if result.Total != total {
+		t.Errorf("got total %f, want %f", result.Total, total)
+	}
+}
+
+func TestCalculatorCircularTooFewPoints(t *testing.T) {
+	calculator, _ := NewCalculator("sloc", Earth)
+	_, err := calculator.Circular(testPath[:1])
+	if !errors.Is(err, ErrTooFewPoints) {
+		t.Fatalf("Expected ErrTooFewPoints, got %v", err)
+	}
+}
+
+func TestLegRelativeError(t *testing.T) {
+	if got := (Leg{Distance: 0, Error: 1}).RelativeError(); got != 0 {
+		t.Errorf("got %g, want 0 for a zero-length leg", got)
+	}
+	if got := (Leg{Distance: 100, Error: 1}).RelativeError(); got != 0.01 {
+		t.Errorf("got %g, want 0.01", got)
+	}
+}
+
+func TestCalculatorCircularError(t *testing.T) {
+	calculator, _ := NewCalculator("sloc", Earth)
+	result, err := calculator.Circular(Path{NewPoint(40, -105), NewPoint(-40, 75)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, leg := range result.Legs {
+		if leg.RelativeError() < 1e-9 {
+			t.Errorf("leg %d: expected an ill-conditioned leg, got relative error %g", i, leg.RelativeError())
+		}
+	}
+}
+
+func TestCalculatorCircularElevation(t *testing.T) {
+	elevation := func(km float64) *float64 { return &km }
+	path := Path{
+		{Name: "Trailhead", Lat: 46.7865, Lon: -121.7353, Elevation: elevation(1.647)},
+		{Name: "Camp Muir", Lat: 46.8356, Lon: -121.7323, Elevation: elevation(3.1)},
+		{Name: "Summit", Lat: 46.8523, Lon: -121.7603, Elevation: elevation(4.392)},
+		{Name: "Unknown", Lat: 46.80, Lon: -121.74},
+	}
+	calculator, _ := NewCalculator("haversine", Earth)
+	result, err := calculator.Circular(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.HasElevation() {
+		t.Fatal("HasElevation = false")
+	}
+
+	leg := result.Legs[0]
+	climb, ok := leg.Climb()
+	if !ok || math.Abs(climb-1.453) > 1e-12 {
+		t.Errorf("Climb = %v, %v, want 1.453", climb, ok)
+	}
+	if want := math.Sqrt(leg.Distance*leg.Distance + climb*climb); math.Abs(leg.Slant-want) > 1e-12 {
+		t.Errorf("Slant = %v, want %v", leg.Slant, want)
+	}
+	// legs to and from the place without an elevation are flat
+	for _, leg := range result.Legs[2:] {
+		if _, ok := leg.Climb(); ok || leg.Slant != leg.Distance {
+			t.Errorf("Leg %d has a climb", leg.FromIndex+1)
+		}
+	}
+	if math.Abs(result.Ascent-2.745) > 1e-12 || result.Descent != 0 {
+		t.Errorf("Ascent, Descent = %v, %v, want 2.745, 0", result.Ascent, result.Descent)
+	}
+	if result.SlantTotal <= result.Total {
+		t.Errorf("SlantTotal = %v, want more than %v", result.SlantTotal, result.Total)
+	}
+
+	flat, _ := calculator.Circular(testPath)
+	if flat.HasElevation() || flat.SlantTotal != flat.Total {
+		t.Errorf("Unexpected elevation in %+v", flat)
+	}
+}
+
+func TestCalculatorCircularTime(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		t := start.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+	// northwards along a meridian, stopping for ten minutes at the second
+	// point; 0.01° of latitude is about 1.112 km
+	path := Path{
+		{Lat: 0, Lon: 0, Time: at(0)},
+		{Lat: 0.01, Lon: 0, Time: at(10)},
+		{Lat: 0.01, Lon: 0, Time: at(20)},
+		{Lat: 0.03, Lon: 0, Time: at(30)},
+	}
+	calculator, _ := NewCalculator("haversine", Earth)
+	result, err := calculator.Circular(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.HasTime() {
+		t.Fatal("HasTime = false")
+	}
+
+	if d, ok := result.Legs[0].Duration(); !ok || d != 10*time.Minute {
+		t.Errorf("Duration = %v, %v, want 10m", d, ok)
+	}
+	if speed, ok := result.Legs[0].Speed(); !ok || math.Abs(speed-6.6717) > 1e-4 {
+		t.Errorf("Speed = %v, %v, want 6.6717 km/h", speed, ok)
+	}
+	if pace, ok := result.Legs[0].Pace(); !ok || pace.Round(time.Second) != 9*time.Minute {
+		t.Errorf("Pace = %v, %v, want 9m0s", pace, ok)
+	}
+	if _, ok := result.Legs[1].Pace(); ok {
+		t.Error("Pace of a leg without length is known")
+	}
+	// the closing leg goes back in time
+	if _, ok := result.Legs[3].Duration(); ok {
+		t.Error("Duration of the closing leg is known")
+	}
+
+	if result.Duration != 30*time.Minute || result.MovingTime != 20*time.Minute {
+		t.Errorf("Duration, MovingTime = %v, %v, want 30m, 20m", result.Duration, result.MovingTime)
+	}
+	if math.Abs(result.MaxSpeed-13.3434) > 1e-4 {
+		t.Errorf("MaxSpeed = %v, want 13.3434 km/h", result.MaxSpeed)
+	}
+	if speed, ok := result.MovingSpeed(); !ok || math.Abs(speed-10.0076) > 1e-4 {
+		t.Errorf("MovingSpeed = %v, %v, want 10.0076 km/h", speed, ok)
+	}
+
+	untimed, _ := calculator.Circular(testPath)
+	if untimed.HasTime() || untimed.Duration != 0 {
+		t.Errorf("Unexpected time in %+v", untimed)
+	}
+	if _, ok := untimed.MovingSpeed(); ok {
+		t.Error("MovingSpeed of an untimed result is known")
+	}
+}
+
+func TestCalculatorCircularTimeWithRadius(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	// a walk of about 6 km/h: 0.01° of latitude, about 1.112 km, in eleven
+	// minutes
+	path := Path{
+		{Lat: 0, Lon: 0, Time: &start},
+		{Lat: 0.01, Lon: 0, Time: new(time.Time)},
+	}
+	*path[1].Time = start.Add(11 * time.Minute)
+	for _, body := range []Body{Earth.WithRadius(6371, "km"), Earth.WithRadius(6371000, "m"), Earth.WithRadius(3958.8, "mi")} {
+		calculator, _ := NewCalculator("haversine", body)
+		result, err := calculator.Circular(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.MovingTime != 11*time.Minute {
+			t.Errorf("MovingTime = %v with a radius in %s, want 11m", result.MovingTime, body.Unit)
+		}
+	}
+
+	calculator, _ := NewCalculator("haversine", Earth.WithRadius(6371, ""))
+	if _, err := calculator.Circular(path); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("Circular with an unknown unit: got %v, want ErrUnknownUnit", err)
+	}
+	if _, err := calculator.Circular(Path{{Lat: 0, Lon: 0}, {Lat: 0.01, Lon: 0}}); err != nil {
+		t.Errorf("Circular of an untimed path with an unknown unit: %v", err)
+	}
+}
+
+func TestCalculatorLength(t *testing.T) {
+	calculator, _ := NewCalculator("haversine", Earth)
+	want := calculator.Distance(testPath[0], testPath[1]) + calculator.Distance(testPath[1], testPath[2])
+	if got := calculator.Length(testPath); got != want {
+		t.Errorf("Length = %v, want %v", got, want)
+	}
+	if got := calculator.Length(testPath[:1]); got != 0 {
+		t.Errorf("Length of a single point = %v, want 0", got)
+	}
+}
+
+func TestCalculatorMatrix(t *testing.T) {
+	calculator, _ := NewCalculator("haversine", Earth)
+	matrix := calculator.Matrix(testPath)
+	if len(matrix) != len(testPath) {
+		t.Fatalf("Matrix has %d rows, want %d", len(matrix), len(testPath))
+	}
+	for i := range testPath {
+		if matrix[i][i] != 0 {
+			t.Errorf("matrix[%d][%d] = %v, want 0", i, i, matrix[i][i])
+		}
+		for j := range testPath {
+			if want := calculator.Distance(testPath[min(i, j)], testPath[max(i, j)]); matrix[i][j] != want {
+				t.Errorf("matrix[%d][%d] = %v, want %v", i, j, matrix[i][j], want)
+			}
+		}
+	}
+}
+
+// TestCalculatorBatch checks that routes and matrices, measured in one
+// batch for formulas with a batch function, match Distance for every
+// formula and for one without a batch function.
+func TestCalculatorBatch(t *testing.T) {
+	path := append(Path{{Lat: 90, Lon: 0}, {Lat: -33.8688, Lon: 151.2093}}, testPath...)
+	unbatched := formulas.Formula{Name: "unbatched", Distance: formulas.Vincenty}
+	for _, f := range append(formulas.All(), unbatched) {
+		calculator := &Calculator{Formula: f, Body: Earth}
+		same := func(got, want float64) bool { return math.Abs(got-want) <= 1e-12*want }
+
+		matrix := calculator.Matrix(path)
+		result, err := calculator.Circular(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var length float64
+		for i := range path {
+			next := (i + 1) % len(path)
+			if want := calculator.Distance(path[i], path[next]); !same(result.Legs[i].Distance, want) {
+				t.Errorf("%s: leg %d is %v, want %v", f.Name, i, result.Legs[i].Distance, want)
+			}
+			if next != 0 {
+				length += calculator.Distance(path[i], path[next])
+			}
+			for j := range path {
+				if want := calculator.Distance(path[i], path[j]); !same(matrix[i][j], want) && !same(matrix[i][j], calculator.Distance(path[j], path[i])) {
+					t.Errorf("%s: Matrix[%d][%d] = %v, want %v", f.Name, i, j, matrix[i][j], want)
+				}
+			}
+		}
+		if got := calculator.Length(path); !same(got, length) {
+			t.Errorf("%s: Length = %v, want %v", f.Name, got, length)
+		}
+	}
+}
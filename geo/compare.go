@@ -0,0 +1,99 @@
+// Package geo is the library behind go-distances. It models places as Points
+// and routes as Paths, and computes great-circle distances between them with
+// a Calculator configured with a formula and a celestial Body.
+package geo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/dickeyy/go-distances/formulas"
+)
+
+// Comparison holds the legs of a circular route measured with every
+// registered formula that is not approximate.
+type Comparison struct {
+	Body Body `json:"body"`
+	// Formulas names the formulas in the order of ComparedLeg.Distances.
+	Formulas []string `json:"formulas"`
+	// Reference names the formula differences are measured against.
+	Reference string `json:"reference"`
+	// Threshold is the largest relative difference from the reference that
+	// does not count as a disagreement.
+	Threshold float64       `json:"threshold"`
+	Legs      []ComparedLeg `json:"legs"`
+}
+
+// ComparedLeg is one leg of a Comparison.
+type ComparedLeg struct {
+	FromIndex int       `json:"fromIndex"`
+	ToIndex   int       `json:"toIndex"`
+	From      Point     `json:"from"`
+	To        Point     `json:"to"`
+	Distances []float64 `json:"distances"`
+	// Disagrees is true when some formula differs from the reference by
+	// more than the comparison's threshold.
+	Disagrees bool `json:"disagrees"`
+}
+
+// Compare measures the circular route through path with every registered
+// formula and compares each leg against the reference formula. Approximate
+// formulas are left out, as they differ from the others by design, unless
+// one is the reference.
+func Compare(path Path, body Body, reference string, threshold float64) (*Comparison, error) {
+	if len(path) < 2 {
+		return nil, ErrTooFewPoints
+	}
+	all := slices.DeleteFunc(formulas.All(), func(f formulas.Formula) bool {
+		return f.Approximate && f.Name != reference
+	})
+	ref := slices.IndexFunc(all, func(f formulas.Formula) bool { return f.Name == reference })
+	if ref < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormula, reference)
+	}
+
+	comparison := &Comparison{Body: body, Reference: reference, Threshold: threshold, Legs: make([]ComparedLeg, len(path))}
+	for _, f := range all {
+		comparison.Formulas = append(comparison.Formulas, f.Name)
+	}
+
+	for i := range path {
+		next := (i + 1) % len(path)
+		leg := ComparedLeg{FromIndex: i, ToIndex: next, From: path[i], To: path[next], Distances: make([]float64, len(all))}
+		for j, f := range all {
+			leg.Distances[j] = f.Distance(path[i].Lat, path[i].Lon, path[next].Lat, path[next].Lon, body.Radius)
+		}
+		for j := range all {
+			_, relative := comparison.Difference(leg, j)
+			if math.Abs(relative) > threshold || math.IsNaN(relative) {
+				leg.Disagrees = true
+			}
+		}
+		comparison.Legs[i] = leg
+	}
+	return comparison, nil
+}
+
+// ReferenceIndex returns the position of the reference formula in Formulas.
+func (c *Comparison) ReferenceIndex() int {
+	return slices.Index(c.Formulas, c.Reference)
+}
+
+// Difference returns how far the distance of the formula at index i in
+// Formulas is from the reference distance of leg, both in absolute terms
+// and relative to the reference. The relative difference is zero when both
+// distances are zero.
+func (c *Comparison) Difference(leg ComparedLeg, i int) (absolute, relative float64) {
+	reference := leg.Distances[c.ReferenceIndex()]
+	absolute = leg.Distances[i] - reference
+	switch {
+	case absolute == 0:
+		relative = 0
+	case reference == 0:
+		relative = math.Inf(1)
+	default:
+		relative = absolute / reference
+	}
+	return absolute, relative
+}
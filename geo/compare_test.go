@@ -0,0 +1,81 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	comparison, err := Compare(testPath, Earth, "vincenty", 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(comparison.Formulas, []string{"haversine", "vincenty", "sloc"}) {
+		t.Fatalf("Unexpected formulas: %v", comparison.Formulas)
+	}
+	if comparison.ReferenceIndex() != 1 {
+		t.Fatalf("got reference index %d, want 1", comparison.ReferenceIndex())
+	}
+	if len(comparison.Legs) != 3 {
+		t.Fatalf("Expected 3 legs, got %d", len(comparison.Legs))
+	}
+	for i, leg := range comparison.Legs {
+		if leg.Disagrees {
+			t.Errorf("leg %d: formulas should agree, got %v", i, leg.Distances)
+		}
+		absolute, relative := comparison.Difference(leg, 1)
+		if absolute != 0 || relative != 0 {
+			t.Errorf("leg %d: reference differs from itself by %g, %g", i, absolute, relative)
+		}
+	}
+}
+
+func TestCompareApproximate(t *testing.T) {
+	comparison, err := Compare(testPath, Earth, "equirectangular", 1e-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(comparison.Formulas, []string{"haversine", "vincenty", "sloc", "equirectangular"}) {
+		t.Fatalf("Unexpected formulas: %v", comparison.Formulas)
+	}
+	for i, leg := range comparison.Legs {
+		if !leg.Disagrees {
+			t.Errorf("leg %d: expected the equirectangular approximation to disagree over %v", i, leg.Distances)
+		}
+	}
+}
+
+func TestCompareDisagreement(t *testing.T) {
+	comparison, err := Compare(testPath, Earth, "haversine", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, leg := range comparison.Legs {
+		if !leg.Disagrees {
+			t.Errorf("leg %d: expected a disagreement with a negative threshold", i)
+		}
+	}
+}
+
+func TestComparisonDifference(t *testing.T) {
+	comparison := &Comparison{Formulas: []string{"a", "b"}, Reference: "a"}
+	absolute, relative := comparison.Difference(ComparedLeg{Distances: []float64{100, 101}}, 1)
+	if absolute != 1 || math.Abs(relative-0.01) > 1e-15 {
+		t.Errorf("got %g, %g, want 1, 0.01", absolute, relative)
+	}
+	_, relative = comparison.Difference(ComparedLeg{Distances: []float64{0, 1}}, 1)
+	if !math.IsInf(relative, 1) {
+		t.Errorf("got %g, want +Inf", relative)
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	if _, err := Compare(testPath, Earth, "invalid", 0); !errors.Is(err, ErrUnknownFormula) {
+		t.Errorf("Expected ErrUnknownFormula, got %v", err)
+	}
+	if _, err := Compare(testPath[:1], Earth, "haversine", 0); !errors.Is(err, ErrTooFewPoints) {
+		t.Errorf("Expected ErrTooFewPoints, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package geo is the library behind go-distances. It models places as Points
+// and routes as Paths, and computes great-circle distances between them with
+// a Calculator configured with a formula and a celestial Body.
+package geo
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// Dataset is the content of a places document: the places, the body they lie
+// on and the formula the document asks for.
+type Dataset struct {
+	Path    Path
+	Body    Body
+	Formula string
+	// Format is the format of the document the dataset was loaded from, or
+	// empty when it was not loaded from a document.
+	Format utils.Format
+}
+
+// Load reads a places document in any format understood by utils.Decode.
+// Errors are those of utils.ParseReader.
+func Load(r io.Reader) (*Dataset, error) {
+	data, err := utils.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return fromData(data)
+}
+
+// LoadFile reads a places document from the named file. Errors are those of
+// utils.ParseFile.
+func LoadFile(name string) (*Dataset, error) {
+	data, err := utils.DecodeFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return fromData(data)
+}
+
+// Write writes the dataset to w as a places document in the given format,
+// which Load reads back. Errors are those of utils.Encode.
+func Write(w io.Writer, dataset *Dataset, format utils.Format) error {
+	return utils.Encode(w, dataset.Data(), format)
+}
+
+// Data converts the dataset back into a places document. Elevations are
+// converted back into metres, and left out for bodies whose unit is not
+// known, which Load does not read them for. The radius and its unit are only
+// set for bodies other than Earth.
+func (d *Dataset) Data() utils.Data {
+	data := utils.Data{Places: make([]utils.Point, len(d.Path)), Formula: d.Formula, Format: d.Format}
+	if d.Body != Earth {
+		data.EarthRadius, data.Unit = d.Body.Radius, d.Body.Unit
+	}
+	for i, point := range d.Path {
+		place := utils.Point{
+			Name:      point.Name,
+			Latitude:  strconv.FormatFloat(point.Lat, 'f', -1, 64),
+			Longitude: strconv.FormatFloat(point.Lon, 'f', -1, 64),
+		}
+		if point.Elevation != nil {
+			if metres, err := d.Body.ToMetres(*point.Elevation); err == nil {
+				place.Elevation = strconv.FormatFloat(metres, 'f', -1, 64)
+			}
+		}
+		if point.Time != nil {
+			place.Time = point.Time.Format(time.RFC3339Nano)
+		}
+		data.Places[i] = place
+	}
+	return data
+}
+
+// Calculator returns a Calculator for the dataset's formula and body.
+func (d *Dataset) Calculator() (*Calculator, error) {
+	return NewCalculator(d.Formula, d.Body)
+}
+
+func fromData(data utils.Data) (*Dataset, error) {
+	latitudes, longitudes, err := data.ParsePlaces()
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := &Dataset{Path: make(Path, len(data.Places)), Format: data.Format}
+	var radius float64
+	radius, dataset.Formula = data.Defaults()
+	dataset.Body = Earth
+	if data.EarthRadius != 0 {
+		dataset.Body = Earth.WithRadius(radius, data.Unit)
+	}
+
+	for i, place := range data.Places {
+		elevation, err := utils.ParseElevation(i, place)
+		if err != nil {
+			return nil, err
+		}
+		if elevation != nil {
+			if *elevation, err = dataset.Body.FromMetres(*elevation); err != nil {
+				return nil, &utils.ParseError{Index: i, Name: place.Name, Field: "elevation", Value: place.Elevation, Err: err}
+			}
+		}
+		visited, err := utils.ParseTime(i, place)
+		if err != nil {
+			return nil, err
+		}
+		dataset.Path[i] = Point{Name: place.Name, Lat: latitudes[i], Lon: longitudes[i], Elevation: elevation, Time: visited}
+	}
+	return dataset, nil
+}
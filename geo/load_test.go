@@ -0,0 +1,249 @@
+package geo
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+func TestLoad(t *testing.T) {
+	input := `{
+		"places": [
+			{"name": "New York", "latitude": "40.7128", "longitude": "-74.0060"},
+			{"name": "Los Angeles", "latitude": "34.0522", "longitude": "-118.2437"}
+		],
+		"earthRadius": 3959,
+		"formula": "spherical law of cosines"
+	}`
+	dataset, err := Load(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset.Path) != 2 || dataset.Path[1] != (Point{Name: "Los Angeles", Lat: 34.0522, Lon: -118.2437}) {
+		t.Fatalf("Unexpected path: %+v", dataset.Path)
+	}
+	if dataset.Body.Radius != 3959 || dataset.Body.Unit != "" {
+		t.Errorf("Unexpected body: %+v", dataset.Body)
+	}
+	if dataset.Formula != "sloc" {
+		t.Errorf("got formula %s, want sloc", dataset.Formula)
+	}
+	if _, err := dataset.Calculator(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dataset, err := Load(strings.NewReader("name,lat,lon\nNew York,40.7128,-74.0060\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataset.Body != Earth || dataset.Formula != "vincenty" {
+		t.Errorf("Unexpected body or formula: %+v %s", dataset.Body, dataset.Formula)
+	}
+}
+
+func TestLoadInvalidCoordinate(t *testing.T) {
+	_, err := Load(strings.NewReader("name,lat,lon\nNew York,north,-74.0060\n"))
+	var parseErr *utils.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Name != "New York" {
+		t.Fatalf("Expected *utils.ParseError, got %v", err)
+	}
+}
+
+func TestLoadFileNotFound(t *testing.T) {
+	_, err := LoadFile("nonexistent.json")
+	if !errors.Is(err, utils.ErrNotFound) {
+		t.Fatalf("Expected utils.ErrNotFound, got %v", err)
+	}
+}
+
+func TestLoadGeohash(t *testing.T) {
+	dataset, err := Load(strings.NewReader("name,geohash\nSan Francisco,9q8yy\nNew York,dr5ru\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := calculator.Distance(dataset.Path[0], dataset.Path[1]); d < 4120 || d > 4140 {
+		t.Errorf("San Francisco to New York = %f km, want about 4130", d)
+	}
+}
+
+func TestLoadElevation(t *testing.T) {
+	dataset, err := Load(strings.NewReader("name,lat,lon,ele\nTrailhead,46.7865,-121.7353,1647\nSummit,46.8523,-121.7603,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := dataset.Path[0].Elevation; e == nil || *e != 1.647 {
+		t.Errorf("Elevation = %v, want 1.647 km", e)
+	}
+	if e := dataset.Path[1].Elevation; e != nil {
+		t.Errorf("Elevation = %v, want nil", *e)
+	}
+
+	_, err = Load(strings.NewReader("name,lat,lon,ele\nTrailhead,46.7865,-121.7353,high\n"))
+	var parseErr *utils.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "elevation" {
+		t.Errorf("Expected *utils.ParseError for elevation, got %v", err)
+	}
+}
+
+func TestLoadElevationWithRadius(t *testing.T) {
+	input := `{
+		"places": [
+			{"name": "Summit", "latitude": "46.8523", "longitude": "-121.7603", "elevation": "4392"},
+			{"name": "Paradise", "latitude": "46.7865", "longitude": "-121.7353", "elevation": "1600"}
+		],
+		"earthRadius": 6371000,
+		"unit": "m"
+	}`
+	dataset, err := Load(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := dataset.Path[0].Elevation; e == nil || *e != 4392 {
+		t.Fatalf("Elevation = %v, want 4392 m", e)
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := calculator.Circular(dataset.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leg := result.Legs[0]; leg.Slant-leg.Distance > 1000 {
+		t.Errorf("slant %v m on a %v m leg, want less than a kilometre more", leg.Slant, leg.Distance)
+	}
+	if math.Abs(result.Ascent-2792) > 1e-9 || math.Abs(result.Descent-2792) > 1e-9 {
+		t.Errorf("ascent %v and descent %v, want 2792 m", result.Ascent, result.Descent)
+	}
+
+	_, err = Load(strings.NewReader(`{"places": [{"latitude": "0", "longitude": "0", "elevation": "12"}], "earthRadius": 6371000}`))
+	var parseErr *utils.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "elevation" || !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("Expected ErrUnknownUnit for an elevation without the unit of the radius, got %v", err)
+	}
+}
+
+func TestLoadTime(t *testing.T) {
+	dataset, err := Load(strings.NewReader("lat,lon,time\n46.7865,-121.7353,2024-05-01T09:00:00Z\n46.8523,-121.7603,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visited := dataset.Path[0].Time; visited == nil || !visited.Equal(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time = %v, want 09:00 UTC", visited)
+	}
+	if visited := dataset.Path[1].Time; visited != nil {
+		t.Errorf("Time = %v, want nil", *visited)
+	}
+
+	_, err = Load(strings.NewReader("lat,lon,time\n46.7865,-121.7353,noon\n"))
+	var parseErr *utils.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "time" {
+		t.Errorf("Expected *utils.ParseError for time, got %v", err)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	input := "name,lat,lon,ele,time\nTrailhead,46.7865,-121.7353,1647,2024-05-01T06:00:00Z\n,46.8523,-121.7603,,\n"
+	dataset, err := Load(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []utils.Format{utils.FormatJSON, utils.FormatGeoJSON, utils.FormatCSV, utils.FormatGPX} {
+		var b strings.Builder
+		if err := Write(&b, dataset, format); err != nil {
+			t.Fatalf("Write(%s): %v", format, err)
+		}
+		got, err := Load(strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatalf("Load(%s): %v\n%s", format, err, b.String())
+		}
+		if got.Format != format {
+			t.Errorf("Format = %s, want %s", got.Format, format)
+		}
+		got.Format = dataset.Format
+		if !reflect.DeepEqual(got, dataset) {
+			t.Errorf("%s: got %+v, want %+v", format, got, dataset)
+		}
+	}
+
+	dataset.Body = Earth.WithRadius(6371.0088, "km")
+	if data := dataset.Data(); data.EarthRadius != 6371.0088 || data.Unit != "km" || data.Places[0].Elevation != "1647" {
+		t.Errorf("Unexpected document for a custom radius: %+v", data)
+	}
+}
+
+// FuzzLoad checks that every document Load accepts has its coordinates in
+// range, and that writing it in any format and loading it back keeps the
+// locations exactly, and the elevations and times of its points. CSV and GPX
+// drop the radius, so elevations are compared in metres.
+func FuzzLoad(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte) {
+		dataset, err := Load(bytes.NewReader(document))
+		if err != nil {
+			return
+		}
+		for i, p := range dataset.Path {
+			if !(math.Abs(p.Lat) <= 90) || !(math.Abs(p.Lon) <= 180) {
+				t.Fatalf("point %d out of range: %v, %v", i, p.Lat, p.Lon)
+			}
+			if p.Elevation != nil && (math.IsNaN(*p.Elevation) || math.IsInf(*p.Elevation, 0)) {
+				t.Fatalf("point %d has elevation %v", i, *p.Elevation)
+			}
+		}
+
+		for _, format := range []utils.Format{utils.FormatJSON, utils.FormatGeoJSON, utils.FormatCSV, utils.FormatGPX} {
+			var b bytes.Buffer
+			if err := Write(&b, dataset, format); err != nil {
+				t.Fatalf("Write(%s): %v", format, err)
+			}
+			got, err := Load(&b)
+			if err != nil {
+				t.Fatalf("cannot load %s written from %q: %v", format, document, err)
+			}
+			if len(got.Path) != len(dataset.Path) {
+				t.Fatalf("%s: got %d points, want %d", format, len(got.Path), len(dataset.Path))
+			}
+			for i, p := range got.Path {
+				want := dataset.Path[i]
+				if p.Lat != want.Lat || p.Lon != want.Lon {
+					t.Fatalf("%s: point %d is at %v, %v, want %v, %v", format, i, p.Lat, p.Lon, want.Lat, want.Lon)
+				}
+				if (p.Elevation == nil) != (want.Elevation == nil) ||
+					p.Elevation != nil && !closeTo(metres(t, got.Body, *p.Elevation), metres(t, dataset.Body, *want.Elevation)) {
+					t.Fatalf("%s: point %d has elevation %v, want %v", format, i, p.Elevation, want.Elevation)
+				}
+				if (p.Time == nil) != (want.Time == nil) || p.Time != nil && !p.Time.Equal(*want.Time) {
+					t.Fatalf("%s: point %d has time %v, want %v", format, i, p.Time, want.Time)
+				}
+			}
+		}
+	})
+}
+
+// metres converts an elevation loaded on body back into metres.
+func metres(t *testing.T, body Body, elevation float64) float64 {
+	t.Helper()
+	m, err := body.ToMetres(elevation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// closeTo reports whether two elevations in metres are equal but for the
+// rounding of converting them between units.
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Abs(b) || math.Abs(a-b) <= 1e-300
+}
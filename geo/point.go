@@ -0,0 +1,46 @@
+// Package geo is the library behind go-distances. It models places as Points
+// and routes as Paths, and computes great-circle distances between them with
+// a Calculator configured with a formula and a celestial Body.
+package geo
+
+import "time"
+
+// Point is a named place on the surface of a body. Latitude and longitude
+// are in degrees.
+type Point struct {
+	Name string  `json:"name,omitempty"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+
+	// Elevation is the height above the surface, in the unit of the body's
+	// radius, or nil when unknown.
+	Elevation *float64 `json:"elevation,omitempty"`
+	// Time is when the point was visited, or nil when unknown.
+	Time *time.Time `json:"time,omitempty"`
+}
+
+// NewPoint returns an unnamed Point at the given latitude and longitude.
+func NewPoint(lat, lon float64) Point {
+	return Point{Lat: lat, Lon: lon}
+}
+
+// Path is an ordered sequence of points.
+type Path []Point
+
+// Latitudes returns the latitude of every point in the path.
+func (p Path) Latitudes() []float64 {
+	latitudes := make([]float64, len(p))
+	for i, point := range p {
+		latitudes[i] = point.Lat
+	}
+	return latitudes
+}
+
+// Longitudes returns the longitude of every point in the path.
+func (p Path) Longitudes() []float64 {
+	longitudes := make([]float64, len(p))
+	for i, point := range p {
+		longitudes[i] = point.Lon
+	}
+	return longitudes
+}
@@ -0,0 +1,47 @@
+package geo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPathCoordinates(t *testing.T) {
+	if got, want := testPath.Latitudes(), []float64{40.7128, 34.0522, 41.8781}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := testPath.Longitudes(), []float64{-74.0060, -118.2437, -87.6298}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBodyUnitName(t *testing.T) {
+	if got := Earth.UnitName(); got != "km" {
+		t.Errorf("got %s, want km", got)
+	}
+	if got := Earth.WithRadius(3959, "").UnitName(); got != "units" {
+		t.Errorf("got %s, want units", got)
+	}
+	if got := Earth.WithRadius(3959, "mi").UnitName(); got != "mi" {
+		t.Errorf("got %s, want mi", got)
+	}
+}
+
+func TestBodyFromMetres(t *testing.T) {
+	if got, err := Earth.FromMetres(4392); got != 4.392 || err != nil {
+		t.Errorf("Earth.FromMetres = %v, %v, want 4.392", got, err)
+	}
+	if got, err := Earth.WithRadius(6371000, "m").FromMetres(4392); got != 4392 || err != nil {
+		t.Errorf("FromMetres in metres = %v, %v, want 4392", got, err)
+	}
+	if _, err := Earth.WithRadius(6371, "").FromMetres(4392); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("FromMetres with an unknown unit: got %v, want ErrUnknownUnit", err)
+	}
+	if _, err := Earth.WithRadius(6371, "furlong").ToMetres(4392); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToMetres with an unknown unit: got %v, want ErrUnknownUnit", err)
+	}
+	km, _ := Earth.FromMetres(4392)
+	if got, _ := Earth.ToMetres(km); got != 4392 {
+		t.Errorf("Earth.ToMetres = %v, want 4392", got)
+	}
+}
@@ -0,0 +1,212 @@
+// Package geohash encodes and decodes geohashes, the base-32 strings that
+// name nested latitude/longitude cells, and finds the neighbours of a cell.
+//
+// See https://en.wikipedia.org/wiki/Geohash.
+package geohash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MaxPrecision is the longest geohash Encode produces. Twelve characters
+// name cells a few centimetres across, beyond the precision of a float64
+// coordinate in degrees.
+const MaxPrecision = 12
+
+// alphabet is the geohash base-32 alphabet, which omits "a", "i", "l" and "o".
+const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// ErrInvalid is wrapped by errors for strings that are not geohashes.
+var ErrInvalid = errors.New("invalid geohash")
+
+// decodeTable maps a byte to its 5-bit value, or -1 when it is not part of
+// the alphabet. Upper-case letters are accepted too.
+var decodeTable = func() [256]int8 {
+	var table [256]int8
+	for i := range table {
+		table[i] = -1
+	}
+	for i := range len(alphabet) {
+		table[alphabet[i]] = int8(i)
+		table[strings.ToUpper(alphabet[i : i+1])[0]] = int8(i)
+	}
+	return table
+}()
+
+// Box is the latitude/longitude extent of a geohash cell, in degrees.
+type Box struct {
+	MinLat, MinLon, MaxLat, MaxLon float64
+}
+
+// Center returns the point in the middle of the cell.
+func (b Box) Center() (lat, lon float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLon + b.MaxLon) / 2
+}
+
+// Encode returns the geohash of the cell of the given precision, in
+// characters, that contains the point. precision is clamped to
+// [1, MaxPrecision]. Latitudes are clamped to [-90, 90] and longitudes are
+// wrapped into [-180, 180).
+func Encode(lat, lon float64, precision int) string {
+	precision = max(1, min(precision, MaxPrecision))
+	lat = max(-90, min(lat, 90))
+	lon = wrapLongitude(lon)
+
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	hash := make([]byte, precision)
+	even := true
+	for i := range hash {
+		var value byte
+		for range 5 {
+			value <<= 1
+			// even bits refine the longitude, odd bits the latitude
+			if even {
+				mid := (minLon + maxLon) / 2
+				if lon >= mid {
+					value |= 1
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if lat >= mid {
+					value |= 1
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+		hash[i] = alphabet[value]
+	}
+	return string(hash)
+}
+
+// Bounds returns the cell named by hash. Geohashes are case-insensitive.
+func Bounds(hash string) (Box, error) {
+	if hash == "" {
+		return Box{}, fmt.Errorf("%w: empty string", ErrInvalid)
+	}
+	if len(hash) > 2*MaxPrecision {
+		return Box{}, fmt.Errorf("%w: %q is too long", ErrInvalid, hash)
+	}
+
+	box := Box{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180}
+	even := true
+	for i := range len(hash) {
+		value := decodeTable[hash[i]]
+		if value < 0 {
+			return Box{}, fmt.Errorf("%w: %q has invalid character %q", ErrInvalid, hash, hash[i])
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := value>>bit&1 == 1
+			if even {
+				mid := (box.MinLon + box.MaxLon) / 2
+				if set {
+					box.MinLon = mid
+				} else {
+					box.MaxLon = mid
+				}
+			} else {
+				mid := (box.MinLat + box.MaxLat) / 2
+				if set {
+					box.MinLat = mid
+				} else {
+					box.MaxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return box, nil
+}
+
+// Decode returns the center of the cell named by hash.
+func Decode(hash string) (lat, lon float64, err error) {
+	box, err := Bounds(hash)
+	if err != nil {
+		return 0, 0, err
+	}
+	lat, lon = box.Center()
+	return lat, lon, nil
+}
+
+// Valid reports whether s is a geohash.
+func Valid(s string) bool {
+	_, err := Bounds(s)
+	return err == nil
+}
+
+// Direction is one of the eight compass directions around a cell.
+type Direction int
+
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// offsets are the cell steps, in latitude and longitude, of each direction.
+var offsets = [8][2]float64{
+	North:     {1, 0},
+	NorthEast: {1, 1},
+	East:      {0, 1},
+	SouthEast: {-1, 1},
+	South:     {-1, 0},
+	SouthWest: {-1, -1},
+	West:      {0, -1},
+	NorthWest: {1, -1},
+}
+
+// Neighbor returns the geohash of the same precision adjacent to hash in the
+// given direction. Cells wrap around the antimeridian; there is no cell
+// beyond the poles, so the neighbour of a polar cell towards its pole is "".
+func Neighbor(hash string, direction Direction) (string, error) {
+	box, err := Bounds(hash)
+	if err != nil {
+		return "", err
+	}
+	if direction < North || direction > NorthWest {
+		return "", fmt.Errorf("invalid direction %d", direction)
+	}
+
+	lat, lon := box.Center()
+	lat += offsets[direction][0] * (box.MaxLat - box.MinLat)
+	lon += offsets[direction][1] * (box.MaxLon - box.MinLon)
+	if lat > 90 || lat < -90 {
+		return "", nil
+	}
+	return strings.ToLower(Encode(lat, lon, len(hash))), nil
+}
+
+// Neighbors returns the eight geohashes around hash, indexed by Direction.
+func Neighbors(hash string) ([8]string, error) {
+	var neighbors [8]string
+	for direction := North; direction <= NorthWest; direction++ {
+		neighbor, err := Neighbor(hash, direction)
+		if err != nil {
+			return [8]string{}, err
+		}
+		neighbors[direction] = neighbor
+	}
+	return neighbors, nil
+}
+
+// wrapLongitude wraps lon into [-180, 180).
+func wrapLongitude(lon float64) float64 {
+	if lon >= -180 && lon < 180 {
+		return lon
+	}
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
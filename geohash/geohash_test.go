@@ -0,0 +1,128 @@
+package geohash
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		lat, lon  float64
+		precision int
+		want      string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{37.7749, -122.4194, 5, "9q8yy"},
+		{40.7128, -74.0060, 5, "dr5re"},
+		{0, 0, 1, "s"},
+		{-90, -180, 3, "000"},
+		{90, 180, 3, "bpb"}, // 180 wraps to -180
+		{37.7749, -122.4194, 0, "9"},
+		{37.7749, -122.4194, 20, "9q8yyk8ytpxr"},
+	}
+	for _, tt := range tests {
+		if got := Encode(tt.lat, tt.lon, tt.precision); got != tt.want {
+			t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lon, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	lat, lon, err := Decode("u4pruydqqvj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(lat-57.64911) > 1e-5 || math.Abs(lon-10.40744) > 1e-5 {
+		t.Errorf("Decode = %v, %v, want 57.64911, 10.40744", lat, lon)
+	}
+
+	upper, _, err := Decode("U4PRUYDQQVJ")
+	if err != nil || upper != lat {
+		t.Errorf("Decode is case-sensitive: %v, %v", upper, err)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, hash := range []string{"", "9q8a", "dr5ru!", "0123456789bcdefghjkmnpqrs"} {
+		if _, _, err := Decode(hash); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) error = %v, want ErrInvalid", hash, err)
+		}
+		if Valid(hash) {
+			t.Errorf("Valid(%q) = true", hash)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	box, err := Bounds("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Box{MinLat: 42.5830078125, MinLon: -5.625, MaxLat: 42.626953125, MaxLon: -5.5810546875}
+	if box != want {
+		t.Errorf("Bounds = %+v, want %+v", box, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for precision := 1; precision <= MaxPrecision; precision++ {
+		for _, p := range [][2]float64{{51.5, -0.12}, {-33.86, 151.2}, {0, 0}, {89.99, 179.99}, {-89.99, -179.99}} {
+			hash := Encode(p[0], p[1], precision)
+			box, err := Bounds(hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p[0] < box.MinLat || p[0] > box.MaxLat || p[1] < box.MinLon || p[1] > box.MaxLon {
+				t.Errorf("%v not inside the cell of %q, %+v", p, hash, box)
+			}
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	got, err := Neighbors("9q8yy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [8]string{"9q8zn", "9q8zp", "9q8yz", "9q8yx", "9q8yw", "9q8yt", "9q8yv", "9q8zj"}
+	if got != want {
+		t.Errorf("Neighbors(9q8yy) = %v, want %v", got, want)
+	}
+}
+
+func TestNeighborEdges(t *testing.T) {
+	// cells wrap around the antimeridian
+	if got, _ := Neighbor("8", West); got != "x" {
+		t.Errorf("Neighbor(8, West) = %q, want x", got)
+	}
+	if got, _ := Neighbor("x", East); got != "8" {
+		t.Errorf("Neighbor(x, East) = %q, want 8", got)
+	}
+	// there is nothing beyond the poles
+	if got, _ := Neighbor("b", North); got != "" {
+		t.Errorf("Neighbor(b, North) = %q, want empty", got)
+	}
+	if _, err := Neighbor("9q8yy", Direction(8)); err == nil {
+		t.Error("Neighbor with an invalid direction succeeded")
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, hash string) {
+		lat, lon, err := Decode(hash)
+		if err != nil {
+			return
+		}
+		if !(math.Abs(lat) <= 90) || !(lon >= -180 && lon < 180) {
+			t.Fatalf("Decode(%q) = %v, %v, out of range", hash, lat, lon)
+		}
+		if len(hash) > MaxPrecision {
+			return
+		}
+		if got := Encode(lat, lon, len(hash)); got != strings.ToLower(hash) {
+			t.Fatalf("Encode(Decode(%q)) = %q", hash, got)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/formulas"
+	"github.com/dickeyy/go-distances/output"
+)
+
+// update rewrites the golden files with the current output instead of
+// comparing against them:
+//
+//	go test -run Golden -update .
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// goldenDir holds the inputs of the golden tests in inputs, and the expected
+// output for every input in a directory named after it.
+const goldenDir = "testdata/golden"
+
+// TestGoldenFormats runs the program on every input with every formula and
+// output format, and compares its standard output with
+// testdata/golden/<input>/<formula>.<format>.golden.
+func TestGoldenFormats(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "inputs", "*"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no golden inputs: %v", err)
+	}
+	for _, input := range inputs {
+		for _, formula := range formulas.Names() {
+			for _, format := range output.Names() {
+				name := filepath.Join(filepath.Base(input), formula+"."+format)
+				t.Run(name, func(t *testing.T) {
+					checkGolden(t, name, "", "-file", input, "-formula", formula, "-format", format)
+				})
+			}
+		}
+	}
+}
+
+// TestGoldenSessions runs the program on other flags and interactive
+// sessions, and compares its standard output with
+// testdata/golden/sessions/<name>.golden.
+func TestGoldenSessions(t *testing.T) {
+	cities := filepath.Join(goldenDir, "inputs", "cities.json")
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{"compare", "", []string{"-file", cities, "-compare"}},
+		{"compare-reference", "", []string{"-file", cities, "-compare", "-reference", "sloc", "-threshold", "1e-3"}},
+		{"columns", "", []string{"-file", filepath.Join(goldenDir, "inputs", "depots.csv"), "-columns", "utm,mgrs,geohash,pluscode"}},
+		{"stdin", "name,lat,lon\nNorth Pole,90,0\nSouth Pole,-90,0\n", []string{"-file", "-", "-format", "markdown"}},
+		{"prompt-file", "y\n" + cities + "\n", nil},
+		{"prompt-manual", "n\n3\n0 0\n0 90\n90 0\n1\nhaversine\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkGolden(t, filepath.Join("sessions", tt.name), tt.stdin, tt.args...)
+		})
+	}
+}
+
+// checkGolden runs the program with args on stdin and compares its standard
+// output with the golden file of the name, or rewrites it with -update. The
+// program must succeed.
+func checkGolden(t *testing.T, name, stdin string, args ...string) {
+	t.Helper()
+	c, stdout, stderr := testCLI(stdin)
+	if code := c.run(args); code != 0 {
+		t.Fatalf("exit code %d\n%s", code, stderr)
+	}
+
+	path := filepath.Join(goldenDir, name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, stdout.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run go test -update to create it", err)
+	}
+	if got := stdout.String(); got != string(want) {
+		t.Errorf("output differs from %s; run go test -update if the change is intended\n%s", path, firstDifference(got, string(want)))
+	}
+}
+
+// firstDifference describes the first line where got and want differ.
+func firstDifference(got, want string) string {
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(want, "\n")
+	for i := range max(len(gotLines), len(wantLines)) {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			return "line " + strconv.Itoa(i+1) + ":\n got: " + g + "\nwant: " + w
+		}
+	}
+	return ""
+}
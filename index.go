@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dickeyy/go-distances/formulas"
+	"github.com/dickeyy/go-distances/spatial"
+)
+
+// runIndex implements the index subcommand, which builds a spatial index
+// file from a places file and queries it later without re-parsing the
+// places. It returns the process exit code.
+//
+//	go-distances index build [-formula haversine] [-o places.idx] [-log-format json] places.json
+//	go-distances index query -at 40.71,-74.00 [-k 5] [-radius 50] [-log-format json] places.idx
+//	go-distances index query -box 40,-75,41,-73 places.idx
+func (c *cli) runIndex(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "build":
+			return c.runIndexBuild(args[1:])
+		case "query":
+			return c.runIndexQuery(args[1:])
+		}
+	}
+	fmt.Fprintln(c.stderr, "Usage: go-distances index build|query [flags] <file>")
+	return 2
+}
+
+func (c *cli) runIndexBuild(args []string) int {
+	flags := c.flagSet("index build")
+	formula := flags.String("formula", "", "formula used as the distance metric: "+strings.Join(formulas.ExactNames(), ", ")+" (default: the formula of the places file)")
+	out := flags.String("o", "", "path of the index file to write (default: the places file with .idx appended)")
+	logging := addLogFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-distances index build [flags] <places file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	logger, err := logging.logger(c.stderr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Invalid -log-format value: %v.\n", err)
+		return 2
+	}
+	placesFile := flags.Arg(0)
+	if *out == "" {
+		if placesFile == "-" {
+			logger.Error("An output file must be given with -o when reading from standard input.")
+			return 2
+		}
+		*out = placesFile + ".idx"
+	}
+
+	// describe the source before reading it, so a change made while the
+	// index is built makes it stale rather than silently out of date
+	var source spatial.Source
+	if placesFile != "-" {
+		var err error
+		source, err = spatial.SourceOf(placesFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Error("Could not read the places file.", "file", placesFile, "error", err)
+			return 1
+		}
+	}
+
+	dataset, err := c.loadDataset(placesFile)
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	if *formula != "" {
+		dataset.Formula = *formula
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+
+	index, err := spatial.NewIndex(dataset.Path, calculator)
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	if err := index.Save(*out, source); err != nil {
+		logger.Error("Could not write the index file.", "file", *out, "error", err)
+		return 1
+	}
+	fmt.Fprintf(c.stdout, "Indexed %d places into %s.\n", index.Len(), *out)
+	return 0
+}
+
+func (c *cli) runIndexQuery(args []string) int {
+	flags := c.flagSet("index query")
+	query := addQueryFlags(flags)
+	logging := addLogFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-distances index query -at lat,lon | -box minLat,minLon,maxLat,maxLon [flags] <index file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || !query.valid() {
+		flags.Usage()
+		return 2
+	}
+	logger, err := logging.logger(c.stderr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Invalid -log-format value: %v.\n", err)
+		return 2
+	}
+
+	file, err := spatial.Open(flags.Arg(0))
+	if err != nil {
+		message := "Could not open the index file."
+		if errors.Is(err, spatial.ErrStale) {
+			message = fmt.Sprintf("The index file is stale. Rebuild it with: go-distances index build -o %s <places file>", flags.Arg(0))
+		}
+		logger.Error(message, "file", flags.Arg(0), "error", err)
+		return 1
+	}
+	defer file.Close()
+
+	return query.run(c, file.Index, logger)
+}
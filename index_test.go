@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunIndex(t *testing.T) {
+	dir := t.TempDir()
+	placesFile := filepath.Join(dir, "places.csv")
+	err := os.WriteFile(placesFile, []byte("name,lat,lon\nNew York,40.7128,-74.0060\nChicago,41.8781,-87.6298\n"), 0644)
+	if err != nil {
+		t.Fatalf("Error creating test file: %v", err)
+	}
+	indexFile := placesFile + ".idx"
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"build", placesFile}, 0},
+		{[]string{"query", "-at", "40,-100", "-k", "1", indexFile}, 0},
+		{[]string{"query", "-box", "40,-80,42,-70", indexFile}, 0},
+		{[]string{"query", "-box", "40,-80,42", indexFile}, 2},
+		{[]string{"query", "-at", "40,-100", "-box", "40,-80,42,-70", indexFile}, 2},
+		{[]string{"query", "-at", "40,-100", placesFile}, 1},
+		{[]string{"build", "-formula", "invalid", placesFile}, 1},
+		{[]string{"build", "-formula", "equirectangular", placesFile}, 1},
+		{[]string{"build", "-"}, 2},
+		{[]string{"rebuild"}, 2},
+		{[]string{"query", "-at", "40,-100", "-log-level", "loud", indexFile}, 2},
+	}
+	for _, tt := range tests {
+		if got := quiet().runIndex(tt.args); got != tt.want {
+			t.Errorf("runIndex(%v) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(placesFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := quiet().runIndex([]string{"query", "-at", "40,-100", indexFile}); got != 1 {
+		t.Errorf("querying a stale index = %d, want 1", got)
+	}
+}
@@ -0,0 +1,137 @@
+// Package jsapi implements the functions the WebAssembly build of
+// go-distances exposes to JavaScript. They take and return JSON documents,
+// which the js/wasm glue in the wasm directory converts from and to plain
+// JavaScript objects, so the API can be tested without a JavaScript runtime.
+//
+// Failures are answered with an Error document rather than thrown, in the
+// same shape as the errors of the HTTP API.
+package jsapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/dickeyy/go-distances/formulas"
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/output"
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// Coordinate is a position in degrees.
+type Coordinate struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// DistanceRequest is the argument of Distance.
+type DistanceRequest struct {
+	From Coordinate `json:"from"`
+	To   Coordinate `json:"to"`
+	// Formula defaults, as in places documents, to vincenty.
+	Formula string `json:"formula,omitempty"`
+	// Radius defaults to the mean radius of the Earth in kilometres. Any
+	// other radius is in an unknown unit.
+	Radius float64 `json:"radius,omitempty"`
+}
+
+// DistanceResponse is the result of Distance.
+type DistanceResponse struct {
+	Formula  string  `json:"formula"`
+	Unit     string  `json:"unit"`
+	Distance float64 `json:"distance"`
+}
+
+// Error is the result of a call that failed.
+type Error struct {
+	Error string `json:"error"`
+	// Place is the one-based index of the place the error is about, if any.
+	Place int `json:"place,omitempty"`
+	// Field is the field of the place the error is about, if any.
+	Field string `json:"field,omitempty"`
+}
+
+// Formulas returns the names of the registered formulas.
+func Formulas() []string {
+	return formulas.Names()
+}
+
+// Distance answers a DistanceRequest with a DistanceResponse. Coordinates off
+// the globe are answered with an Error whose Field is "from" or "to".
+func Distance(request []byte) []byte {
+	var r DistanceRequest
+	if err := json.Unmarshal(request, &r); err != nil {
+		return encodeError(fmt.Errorf("invalid distance request: %w", err))
+	}
+	for _, end := range []struct {
+		field string
+		c     Coordinate
+	}{{"from", r.From}, {"to", r.To}} {
+		if !(math.Abs(end.c.Lat) <= 90) || !(math.Abs(end.c.Lon) <= 180) {
+			err := fmt.Errorf("%w: %s is at %v, %v, but latitudes must be within [-90, 90] and longitudes within [-180, 180]", utils.ErrOutOfRange, end.field, end.c.Lat, end.c.Lon)
+			return encode(Error{Error: err.Error(), Field: end.field})
+		}
+	}
+	radius, formula := utils.Data{EarthRadius: r.Radius, Formula: r.Formula}.Defaults()
+	body := geo.Earth
+	if r.Radius != 0 {
+		body = geo.Earth.WithRadius(radius, "")
+	}
+	calculator, err := geo.NewCalculator(formula, body)
+	if err != nil {
+		return encodeError(err)
+	}
+	return encode(DistanceResponse{
+		Formula:  calculator.Formula.Name,
+		Unit:     body.UnitName(),
+		Distance: calculator.Distance(geo.NewPoint(r.From.Lat, r.From.Lon), geo.NewPoint(r.To.Lat, r.To.Lon)),
+	})
+}
+
+// Route answers a places document, in any format geo.Load reads, with the
+// circular route through its places as written by the json output format.
+// A non-empty formula overrides the formula of the document.
+func Route(document []byte, formula string) []byte {
+	dataset, err := geo.Load(bytes.NewReader(document))
+	if err != nil {
+		return encodeError(err)
+	}
+	if formula != "" {
+		dataset.Formula = formula
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		return encodeError(err)
+	}
+	result, err := calculator.Circular(dataset.Path)
+	if err != nil {
+		return encodeError(err)
+	}
+
+	format, _ := output.Lookup("json")
+	var b bytes.Buffer
+	if err := format.Writer.WriteResult(&b, result); err != nil {
+		return encodeError(err)
+	}
+	return bytes.TrimSpace(b.Bytes())
+}
+
+func encode(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return encodeError(err)
+	}
+	return b
+}
+
+func encodeError(err error) []byte {
+	e := Error{Error: err.Error()}
+	var parseErr *utils.ParseError
+	if errors.As(err, &parseErr) {
+		e.Place, e.Field = parseErr.Index+1, parseErr.Field
+	}
+	b, _ := json.Marshal(e)
+	return b
+}
@@ -0,0 +1,118 @@
+package jsapi
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestFormulas(t *testing.T) {
+	names := Formulas()
+	for _, name := range []string{"haversine", "vincenty", "sloc"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("Formulas() = %v, missing %s", names, name)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	var response DistanceResponse
+	request := `{"from": {"lat": 40.7128, "lon": -74.0060}, "to": {"lat": 34.0522, "lon": -118.2437}, "formula": "haversine"}`
+	if err := json.Unmarshal(Distance([]byte(request)), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Formula != "haversine" || response.Unit != "km" || math.Abs(response.Distance-3935.75) > 0.01 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	request = `{"from": {"lat": 0, "lon": 0}, "to": {"lat": 0, "lon": 90}, "radius": 2}`
+	if err := json.Unmarshal(Distance([]byte(request)), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Formula != "vincenty" || response.Unit != "units" || math.Abs(response.Distance-math.Pi) > 1e-12 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	document := `{"places": [
+		{"name": "New York", "latitude": "40.7128", "longitude": "-74.0060"},
+		{"name": "Los Angeles", "latitude": "34.0522", "longitude": "-118.2437"}
+	]}`
+	var response struct {
+		Formula string `json:"formula"`
+		Legs    []struct {
+			From struct {
+				Name string `json:"name"`
+			} `json:"from"`
+			Distance float64 `json:"distance"`
+		} `json:"legs"`
+	}
+	if err := json.Unmarshal(Route([]byte(document), "sloc"), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Formula != "sloc" || len(response.Legs) != 2 || response.Legs[1].From.Name != "Los Angeles" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	// other formats are read too
+	if err := json.Unmarshal(Route([]byte("lat,lon\n0,0\n0,1\n"), ""), &response); err != nil || response.Formula != "vincenty" {
+		t.Errorf("Unexpected response: %+v, %v", response, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		response []byte
+		place    int
+		field    string
+	}{
+		{Distance([]byte(`{"from": "here"}`)), 0, ""},
+		{Distance([]byte(`{"formula": "flat"}`)), 0, ""},
+		{Distance([]byte(`{"from": {"lat": 0, "lon": 0}, "to": {"lat": 90.5, "lon": 0}}`)), 0, "to"},
+		{Route([]byte("lat,lon\n0,0\nnorth,1\n"), ""), 2, "latitude"},
+		{Route([]byte("lat,lon\n0,0\n"), ""), 0, ""},
+		{Route([]byte("lat,lon\n0,0\n0,1\n"), "flat"), 0, ""},
+	}
+	for _, tt := range tests {
+		var e Error
+		if err := json.Unmarshal(tt.response, &e); err != nil || e.Error == "" || e.Place != tt.place || e.Field != tt.field {
+			t.Errorf("Unexpected error %s", tt.response)
+		}
+	}
+}
+
+func FuzzDistance(f *testing.F) {
+	f.Fuzz(func(t *testing.T, request []byte) {
+		response := Distance(request)
+		var answer struct {
+			Error    *string  `json:"error"`
+			Distance *float64 `json:"distance"`
+		}
+		if err := json.Unmarshal(response, &answer); err != nil {
+			t.Fatalf("Distance(%q) = %q, which is not JSON: %v", request, response, err)
+		}
+		if answer.Error != nil {
+			return
+		}
+		if answer.Distance == nil {
+			t.Fatalf("Distance(%q) = %q, which has neither a distance nor an error", request, response)
+		}
+		var r DistanceRequest
+		json.Unmarshal(request, &r)
+		for _, c := range []Coordinate{r.From, r.To} {
+			if !(math.Abs(c.Lat) <= 90) || !(math.Abs(c.Lon) <= 180) {
+				t.Fatalf("Distance(%q) accepted %+v", request, c)
+			}
+		}
+	})
+}
+
+func FuzzRoute(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte, formula string) {
+		if response := Route(document, formula); !json.Valid(response) {
+			t.Fatalf("Route(%q, %q) = %q, which is not JSON", document, formula, response)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/dickeyy/go-distances/metrics"
+)
+
+// logFlags are the flags choosing how a command logs.
+type logFlags struct {
+	format string
+	level  slog.Level
+}
+
+// addLogFlags defines the -log-format and -log-level flags on flags.
+func addLogFlags(flags *flag.FlagSet) *logFlags {
+	l := &logFlags{}
+	flags.StringVar(&l.format, "log-format", "text", "format of log messages on standard error: text or json")
+	flags.TextVar(&l.level, "log-level", slog.LevelInfo, "least severe level logged: debug, info, warn or error")
+	return l
+}
+
+// logger returns a logger writing to w as the flags choose.
+func (l *logFlags) logger(w io.Writer) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: l.level}
+	switch l.format {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q, use text or json", l.format)
+}
+
+// writeMetrics writes the metrics to the named file in the Prometheus text
+// format, replacing it atomically so a node exporter's textfile collector
+// never reads it half written.
+func writeMetrics(path string, m *metrics.Distances) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".metrics-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if err := m.Registry.Write(temp); err != nil {
+		temp.Close()
+		return err
+	}
+	// temporary files are private, but the collector runs as another user
+	if err := temp.Chmod(0o644); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
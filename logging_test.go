@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/metrics"
+)
+
+func TestLogFlags(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	logging := addLogFlags(flags)
+	if err := flags.Parse([]string{"-log-format", "json", "-log-level", "warn"}); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	logger, err := logging.logger(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hidden")
+	logger.Warn("Invalid formula.", "formula", "flat")
+	if got := b.String(); strings.Contains(got, "hidden") || !strings.Contains(got, `"level":"WARN","msg":"Invalid formula.","formula":"flat"`) {
+		t.Errorf("Unexpected log %q", got)
+	}
+
+	logging.format = "xml"
+	if _, err := logging.logger(&b); err == nil {
+		t.Error("Expected an error for an unknown log format")
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	m := metrics.NewDistances()
+	m.Points.Add(3, "haversine")
+	path := filepath.Join(t.TempDir(), "go_distances.prom")
+	if err := writeMetrics(path, m); err != nil {
+		t.Fatal(err)
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), `go_distances_points_processed_total{formula="haversine"} 3`) {
+		t.Errorf("Unexpected metrics file:\n%s", contents)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o644 {
+		t.Errorf("Mode = %v, want 0644", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("Temporary files left behind: %v", entries)
+	}
+}
@@ -6,148 +6,372 @@
 //   - Vincenty formula (simplified version)
 //   - Spherical Law of Cosines (SLOC)
 //
-// Users can input data manually or from a JSON file, specify the Earth's radius,
-// and choose the calculation formula.
+// Users can input data manually or from a places file, specify the Earth's radius,
+// and choose the calculation formula. The calculations themselves live in the
+// importable geo package.
 package main
 
 import (
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
-	"math"
-	"slices"
+	"io"
+	"log/slog"
+	"os"
 	"strings"
 
 	"github.com/dickeyy/go-distances/formulas"
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/metrics"
+	"github.com/dickeyy/go-distances/output"
+	"github.com/dickeyy/go-distances/spatial"
 	"github.com/dickeyy/go-distances/utils"
 )
 
-var numPoints int
-var importFile string
-var formula string
-var earthRadius float64
-var latitudes []float64
-var longitudes []float64
+// cli holds the standard streams the program reads and writes, so that tests
+// can run it on buffers.
+type cli struct {
+	stdin  *bufio.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
 
-var validFormulas = []string{"haversine", "vincenty", "sloc"}
+// newCLI returns a cli reading from stdin and writing to stdout and stderr.
+func newCLI(stdin io.Reader, stdout, stderr io.Writer) *cli {
+	return &cli{stdin: bufio.NewReader(stdin), stdout: stdout, stderr: stderr}
+}
 
-// calculateCircularDistance computes the distances between points in a circular manner
-// using the specified formula. It accepts slices of latitudes and longitudes,
-// the Earth's radius, and the formula name.
-//
-// Supported formulas are "haversine", "vincenty", and "sloc".
+// flagSet returns a flag set for the named command that reports errors on
+// standard error and leaves exiting to the caller.
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+// calculateCircularDistance computes the distances between the points of a
+// dataset in a circular manner, using the dataset's formula and body.
 //
 // The function prints the calculated distances between consecutive points,
-// wrapping around to the first point after the last one.
-func calculateCircularDistance(latitudes []float64, longitudes []float64, earthRadius float64, formula string) {
-	numPoints := len(latitudes)
-	if numPoints < 2 {
-		fmt.Println("At least two points are required to calculate circular distances.")
-		return
+// wrapping around to the first point after the last one, with the given
+// output writer.
+//
+// Legs whose estimated numerical error exceeds errorThreshold, relative to
+// their distance, are warned about with logger, which also reports errors.
+// The points measured are counted in m. It returns the process exit code.
+func (c *cli) calculateCircularDistance(dataset *geo.Dataset, writer output.Writer, errorThreshold float64, logger *slog.Logger, m *metrics.Distances) int {
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
 	}
 
-	distances := make([]int, numPoints)
+	result, err := calculator.Circular(dataset.Path)
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	m.Points.Add(float64(len(dataset.Path)), result.Formula)
 
-	switch formula {
-	case "haversine":
-		for i := range numPoints {
-			nextIndex := (i + 1) % numPoints
-			distances[i] = int(math.Round(formulas.Haversine(latitudes[i], longitudes[i], latitudes[nextIndex], longitudes[nextIndex], earthRadius)))
-		}
-	case "vincenty":
-		for i := range numPoints {
-			nextIndex := (i + 1) % numPoints
-			distances[i] = int(math.Round(formulas.Vincenty(latitudes[i], longitudes[i], latitudes[nextIndex], longitudes[nextIndex], earthRadius)))
-		}
-	case "sloc":
-		for i := range numPoints {
-			nextIndex := (i + 1) % numPoints
-			distances[i] = int(math.Round(formulas.SphericalLawOfCosines(latitudes[i], longitudes[i], latitudes[nextIndex], longitudes[nextIndex], earthRadius)))
+	if err := writer.WriteResult(c.stdout, result); err != nil {
+		logger.Error("Could not write the result.", "error", err)
+		return 1
+	}
+
+	for i, leg := range result.Legs {
+		if leg.RelativeError() > errorThreshold {
+			logger.Warn(fmt.Sprintf("The %s formula is ill-conditioned for leg %d (%d -> %d); its distance may be off by %.3g %s (%.3g of the leg).",
+				result.Formula, i+1, leg.FromIndex+1, leg.ToIndex+1, leg.Error, result.Body.UnitName(), leg.RelativeError()),
+				"formula", result.Formula, "leg", i+1, "max_error", leg.Error, "relative_error", leg.RelativeError())
 		}
-	default:
-		fmt.Println("Invalid formula.")
-		return
+	}
+	return 0
+}
+
+// compareFormulas measures the circular route through the dataset's points
+// with every registered formula and prints a table comparing them against
+// the reference formula, flagging legs whose relative difference exceeds the
+// threshold. An empty reference means the dataset's own formula. Errors are
+// reported with logger. It returns the process exit code.
+func (c *cli) compareFormulas(dataset *geo.Dataset, reference string, threshold float64, logger *slog.Logger) int {
+	if reference == "" {
+		reference = dataset.Formula
+	}
+
+	comparison, err := geo.Compare(dataset.Path, dataset.Body, reference, threshold)
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
 	}
 
-	fmt.Printf("\nCircular distances using %s formula:\n", formula)
-	for i := range numPoints {
-		fmt.Printf("Distance %d -> %d: %d units\n", i+1, (i+1)%numPoints+1, distances[i])
+	if err := output.WriteComparison(c.stdout, comparison); err != nil {
+		logger.Error("Could not write the comparison.", "error", err)
+		return 1
 	}
+	return 0
 }
 
 // importDataFromUser prompts the user to enter the number of points,
 // their latitudes and longitudes, the Earth's radius, and the formula to use.
-// It populates the global variables with the input data.
-func importDataFromUser() {
-	fmt.Print("Enter the number of points: ")
-	fmt.Scan(&numPoints)
+func (c *cli) importDataFromUser() (*geo.Dataset, error) {
+	var numPoints int
+	fmt.Fprint(c.stdout, "Enter the number of points: ")
+	fmt.Fscan(c.stdin, &numPoints)
 
-	latitudes = make([]float64, numPoints)
-	longitudes = make([]float64, numPoints)
+	dataset := &geo.Dataset{Path: make(geo.Path, max(numPoints, 0))}
 
-	fmt.Printf("Enter the latitudes and longitudes of the %d points:\n", numPoints)
-	for i := range numPoints {
-		fmt.Printf("Point %d:\n", i+1)
-		fmt.Print("Latitude: ")
-		fmt.Scan(&latitudes[i])
-		fmt.Print("Longitude: ")
-		fmt.Scan(&longitudes[i])
+	fmt.Fprintf(c.stdout, "Enter the latitudes and longitudes of the %d points:\n", numPoints)
+	for i := range dataset.Path {
+		fmt.Fprintf(c.stdout, "Point %d:\n", i+1)
+		fmt.Fprint(c.stdout, "Latitude: ")
+		fmt.Fscan(c.stdin, &dataset.Path[i].Lat)
+		fmt.Fprint(c.stdout, "Longitude: ")
+		fmt.Fscan(c.stdin, &dataset.Path[i].Lon)
 	}
 
-	fmt.Print("Enter the Earth's radius: ")
-	fmt.Scan(&earthRadius)
+	var earthRadius float64
+	fmt.Fprint(c.stdout, "Enter the Earth's radius: ")
+	fmt.Fscan(c.stdin, &earthRadius)
+	dataset.Body = geo.Earth.WithRadius(earthRadius, "")
 
-	fmt.Print("Enter the formula to use (haversine or vincenty or sloc): ")
-	fmt.Scan(&formula)
+	fmt.Fprintf(c.stdout, "Enter the formula to use (%s): ", strings.Join(formulas.ExactNames(), " or "))
+	fmt.Fscan(c.stdin, &dataset.Formula)
 
-	if !slices.Contains(validFormulas, formula) {
-		fmt.Println("Invalid formula.")
-		return
+	if _, ok := formulas.Lookup(dataset.Formula); !ok {
+		return nil, fmt.Errorf("%w: %q", geo.ErrUnknownFormula, dataset.Formula)
 	}
+	return dataset, nil
 }
 
-// importDataFromFile prompts the user for a JSON file path and reads the data
-// from the file. It populates the global variables with the imported data.
+// importDataFromFile prompts the user for a file path and reads the data
+// from the file.
 //
-// The JSON file should contain an array of points with latitudes and longitudes,
-// the Earth's radius, and the formula to use.
-func importDataFromFile() {
-	fmt.Print("Enter the path to the file: ")
+// The file may be JSON, GeoJSON, CSV or GPX; the format is detected from its
+// contents. A path of "-" reads the data from standard input instead.
+func (c *cli) importDataFromFile() (*geo.Dataset, error) {
+	fmt.Fprint(c.stdout, "Enter the path to the file (or - for standard input): ")
 	var filePath string
-	fmt.Scan(&filePath)
+	fmt.Fscan(c.stdin, &filePath)
 
-	// make sure the file is json
-	if !strings.HasSuffix(filePath, ".json") {
-		fmt.Println("Invalid file format. Please enter a JSON file.")
-		return
+	dataset, err := c.loadDataset(filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	// read the file
-	var err error
-	numPoints, latitudes, longitudes, earthRadius, formula, err = utils.ParseFile(filePath)
-	if err != nil {
-		fmt.Println(err)
-		return
+	fmt.Fprintf(c.stdout, "Data imported from %s:\n\n", filePath)
+	return dataset, nil
+}
+
+// loadDataset reads a places document from the named file, or from standard
+// input when the name is "-". Its formula is checked when a calculator is
+// made for it, so that commands can replace it first.
+func (c *cli) loadDataset(filePath string) (*geo.Dataset, error) {
+	if filePath == "-" {
+		return geo.Load(c.stdin)
 	}
+	return geo.LoadFile(filePath)
+}
 
-	if !slices.Contains(validFormulas, formula) {
-		fmt.Println("Invalid formula.")
-		return
+// withColumns returns the writer of format, extended with the comma-separated
+// extra columns named in names.
+func withColumns(format output.Format, names string) (output.Writer, error) {
+	if names == "" {
+		return format.Writer, nil
+	}
+	writer, ok := format.Writer.(output.ColumnWriter)
+	if !ok {
+		return nil, fmt.Errorf("the %s format does not support extra columns", format.Name)
 	}
 
-	fmt.Printf("Data imported from %s:\n", filePath)
+	var columns []output.Column
+	for _, name := range strings.Split(names, ",") {
+		column, ok := output.LookupColumn(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q, use any of: %s", strings.TrimSpace(name), strings.Join(output.ColumnNames(), ", "))
+		}
+		columns = append(columns, column)
+	}
+	return writer.WithColumns(columns...), nil
 }
 
-// main is the entry point of the program. It asks the user whether to import
-// data from a file or enter it manually, then calculates and displays the
-// circular distances between the points using the specified formula.
+// describeError turns an error returned by the geo package into a message
+// suitable for showing to the user.
+func describeError(err error) string {
+	switch {
+	case errors.Is(err, geo.ErrUnknownFormula):
+		return "Invalid formula."
+	case errors.Is(err, geo.ErrTooFewPoints):
+		return "At least two points are required to calculate circular distances."
+	case errors.Is(err, spatial.ErrNotMetric):
+		return "Approximate formulas cannot be used to search or index places, as they do not satisfy the triangle inequality."
+	}
+
+	var parseErr *utils.ParseError
+	if errors.As(err, &parseErr) {
+		if parseErr.Index < 0 {
+			return fmt.Sprintf("The document has an invalid %s location: %q is not a recognised location.", parseErr.Field, parseErr.Value)
+		}
+		place := fmt.Sprintf("Place %d", parseErr.Index+1)
+		if parseErr.Name != "" {
+			place += fmt.Sprintf(" (%s)", parseErr.Name)
+		}
+		if errors.Is(err, geo.ErrUnknownUnit) {
+			return fmt.Sprintf(`%s has an elevation, but the unit of the radius is not known to convert it from metres. Give it next to earthRadius, such as "unit": "km".`, place)
+		}
+		if errors.Is(err, utils.ErrNoReference) {
+			return fmt.Sprintf("%s has the short plus code %q, but the document has no reference location to recover it near.", place, parseErr.Value)
+		}
+		if errors.Is(err, utils.ErrOutOfRange) {
+			switch parseErr.Field {
+			case "latitude":
+				return fmt.Sprintf("%s has an invalid latitude: %s is outside [-90, 90] degrees.", place, parseErr.Value)
+			case "longitude":
+				return fmt.Sprintf("%s has an invalid longitude: %s is outside [-180, 180] degrees.", place, parseErr.Value)
+			}
+			return fmt.Sprintf("%s has an invalid %s: %s is not a finite number.", place, parseErr.Field, parseErr.Value)
+		}
+		switch parseErr.Field {
+		case "location":
+			return fmt.Sprintf("%s has an invalid location: %q is not a recognised location.", place, parseErr.Value)
+		case "elevation":
+			return fmt.Sprintf("%s has an invalid elevation: %q is not a number in metres.", place, parseErr.Value)
+		case "time":
+			return fmt.Sprintf("%s has an invalid time: %q is not an RFC 3339 time such as 2024-05-01T09:30:00Z.", place, parseErr.Value)
+		}
+		return fmt.Sprintf("%s has an invalid %s: %q is not a number in degrees.", place, parseErr.Field, parseErr.Value)
+	}
+
+	if errors.Is(err, geo.ErrUnknownUnit) {
+		return `The places have times, but the unit of the radius is not known to tell moving from stopped legs. Give it next to earthRadius, such as "unit": "km".`
+	}
+
+	var fileErr *utils.FileError
+	if errors.As(err, &fileErr) {
+		source := "the file " + fileErr.Path
+		if fileErr.Path == "" {
+			source = "standard input"
+		}
+		switch {
+		case errors.Is(err, utils.ErrNotFound):
+			return fmt.Sprintf("Could not find %s.", source)
+		case errors.Is(err, utils.ErrUnknownFormat):
+			return fmt.Sprintf("The format of %s is not supported. Use JSON, GeoJSON, CSV or GPX.", source)
+		case errors.Is(err, utils.ErrSyntax):
+			return fmt.Sprintf("The contents of %s are not a valid places document: %v.", source, fileErr.Err)
+		default:
+			return fmt.Sprintf("Could not read %s: %v.", source, fileErr.Err)
+		}
+	}
+
+	return err.Error()
+}
+
+// main is the entry point of the program. It runs the command line on the
+// process's standard streams and exits with its exit code.
 func main() {
-	fmt.Print("Do you want to import points from a file? (y/n): ")
-	fmt.Scan(&importFile)
+	os.Exit(newCLI(os.Stdin, os.Stdout, os.Stderr).run(os.Args[1:]))
+}
+
+// run runs the program with the given arguments, without the program name,
+// and returns the process exit code. Subcommands such as search are
+// dispatched on the first argument.
+func (c *cli) run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "search":
+			return c.runSearch(args[1:])
+		case "index":
+			return c.runIndex(args[1:])
+		case "simplify":
+			return c.runSimplify(args[1:])
+		case "serve":
+			return c.runServe(args[1:])
+		case "batch":
+			return c.runBatch(args[1:])
+		}
+	}
+	return c.runMain(args)
+}
 
-	if importFile == "y" {
-		importDataFromFile()
+// runMain implements the program without a subcommand. Unless a places file
+// is given with the -file flag, it asks the user whether to import data from
+// a file or enter it manually. It then calculates and displays the circular
+// distances between the points using the specified formula, in the format
+// chosen with -format, or compares every formula when -compare is set.
+// Errors are logged on standard error, and metrics are written to the file
+// named with -metrics-file, if any, when the run ends. It returns the process
+// exit code.
+func (c *cli) runMain(args []string) int {
+	flags := c.flagSet("go-distances")
+	format := flags.String("format", "text", "output format: "+strings.Join(output.Names(), ", "))
+	file := flags.String("file", "", "read places from this file, or - for standard input, instead of prompting")
+	formula := flags.String("formula", "", "formula to calculate with (default: the formula of the places)")
+	compare := flags.Bool("compare", false, "compare every formula instead of calculating with one")
+	reference := flags.String("reference", "", "formula the comparison is measured against (default: the chosen formula)")
+	threshold := flags.Float64("threshold", 1e-6, "relative difference above which formulas disagree in a comparison")
+	errorThreshold := flags.Float64("error-threshold", 1e-9, "estimated relative numerical error above which a leg is warned about")
+	columns := flags.String("columns", "", "extra columns to write for every place, comma-separated: "+strings.Join(output.ColumnNames(), ", "))
+	metricsFile := flags.String("metrics-file", "", "write metrics in the Prometheus text format to this file when done")
+	logging := addLogFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	logger, err := logging.logger(c.stderr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Invalid -log-format value: %v.\n", err)
+		return 2
+	}
+	writer, ok := output.Lookup(*format)
+	if !ok {
+		logger.Error(fmt.Sprintf("Invalid output format %q. Use one of: %s.", *format, strings.Join(output.Names(), ", ")))
+		return 2
+	}
+	resultWriter, err := withColumns(writer, *columns)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Invalid -columns value: %v.", err))
+		return 2
+	}
+
+	m := metrics.NewDistances()
+	if *metricsFile != "" {
+		defer func() {
+			if err := writeMetrics(*metricsFile, m); err != nil {
+				logger.Error("Could not write the metrics file.", "error", err)
+			}
+		}()
+	}
+
+	var dataset *geo.Dataset
+	if *file != "" {
+		dataset, err = c.loadDataset(*file)
 	} else {
-		importDataFromUser()
+		var importFile string
+		fmt.Fprint(c.stdout, "Do you want to import points from a file? (y/n): ")
+		fmt.Fscan(c.stdin, &importFile)
+
+		if importFile == "y" {
+			dataset, err = c.importDataFromFile()
+		} else {
+			dataset, err = c.importDataFromUser()
+		}
+	}
+	if err != nil {
+		m.ParseFailure(err)
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	if *formula != "" {
+		dataset.Formula = *formula
 	}
 
-	calculateCircularDistance(latitudes, longitudes, earthRadius, formula)
+	if *compare {
+		return c.compareFormulas(dataset, *reference, *threshold, logger)
+	}
+	return c.calculateCircularDistance(dataset, resultWriter, *errorThreshold, logger, m)
 }
@@ -1,38 +1,113 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/dickeyy/go-distances/formulas"
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/metrics"
+	"github.com/dickeyy/go-distances/output"
+	"github.com/dickeyy/go-distances/utils"
 )
 
 // Test data for various scenarios
-var testLatitudes = []float64{40.7128, 34.0522, 41.8781}
-var testLongitudes = []float64{-74.0060, -118.2437, -87.6298}
-var testEarthRadius = 6371.0
+var testPath = geo.Path{
+	{Name: "New York", Lat: 40.7128, Lon: -74.0060},
+	{Name: "Los Angeles", Lat: 34.0522, Lon: -118.2437},
+	{Name: "Chicago", Lat: 41.8781, Lon: -87.6298},
+}
+var testBody = geo.Earth
+var testFormat, _ = output.Lookup("text")
+var testLogger = slog.New(slog.DiscardHandler)
+
+// testCLI returns a cli reading stdin and writing to the returned buffers.
+func testCLI(stdin string) (c *cli, stdout, stderr *bytes.Buffer) {
+	stdout, stderr = new(bytes.Buffer), new(bytes.Buffer)
+	return newCLI(strings.NewReader(stdin), stdout, stderr), stdout, stderr
+}
+
+// quiet returns a cli with nothing on standard input that discards its
+// output.
+func quiet() *cli {
+	return newCLI(strings.NewReader(""), io.Discard, io.Discard)
+}
 
 func TestCalculateCircularDistanceHaversine(t *testing.T) {
-	calculateCircularDistance(testLatitudes, testLongitudes, testEarthRadius, "haversine")
+	c, stdout, _ := testCLI("")
+	if code := c.calculateCircularDistance(&geo.Dataset{Path: testPath, Body: testBody, Formula: "haversine"}, testFormat.Writer, 1e-9, testLogger, metrics.NewDistances()); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if !strings.Contains(stdout.String(), "haversine") || !strings.Contains(stdout.String(), "3936") {
+		t.Errorf("Unexpected output:\n%s", stdout)
+	}
 }
 
 func TestCalculateCircularDistanceVincenty(t *testing.T) {
-	calculateCircularDistance(testLatitudes, testLongitudes, testEarthRadius, "vincenty")
+	c, stdout, _ := testCLI("")
+	if code := c.calculateCircularDistance(&geo.Dataset{Path: testPath, Body: testBody, Formula: "vincenty"}, testFormat.Writer, 1e-9, testLogger, metrics.NewDistances()); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if !strings.Contains(stdout.String(), "vincenty") {
+		t.Errorf("Unexpected output:\n%s", stdout)
+	}
 }
 
 func TestCalculateCircularDistanceSloc(t *testing.T) {
-	calculateCircularDistance(testLatitudes, testLongitudes, testEarthRadius, "sloc")
+	c, stdout, _ := testCLI("")
+	if code := c.calculateCircularDistance(&geo.Dataset{Path: testPath, Body: testBody, Formula: "sloc"}, testFormat.Writer, 1e-9, testLogger, metrics.NewDistances()); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if !strings.Contains(stdout.String(), "sloc") {
+		t.Errorf("Unexpected output:\n%s", stdout)
+	}
 }
 
 func TestCalculateCircularDistanceInvalidFormula(t *testing.T) {
-	calculateCircularDistance(testLatitudes, testLongitudes, testEarthRadius, "invalid")
+	c, stdout, stderr := testCLI("")
+	logger := slog.New(slog.NewTextHandler(stderr, nil))
+	if code := c.calculateCircularDistance(&geo.Dataset{Path: testPath, Body: testBody, Formula: "invalid"}, testFormat.Writer, 1e-9, logger, metrics.NewDistances()); code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), "Invalid formula.") {
+		t.Errorf("Unexpected output %q, errors %q", stdout, stderr)
+	}
 }
 
 func TestCalculateCircularDistanceInsufficientPoints(t *testing.T) {
-	calculateCircularDistance([]float64{40.7128}, []float64{-74.0060}, testEarthRadius, "haversine")
+	c, _, stderr := testCLI("")
+	logger := slog.New(slog.NewTextHandler(stderr, nil))
+	if code := c.calculateCircularDistance(&geo.Dataset{Path: testPath[:1], Body: testBody, Formula: "haversine"}, testFormat.Writer, 1e-9, logger, metrics.NewDistances()); code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "At least two points are required") {
+		t.Errorf("Unexpected errors %q", stderr)
+	}
+}
+
+func TestLoadDatasetInvalidReference(t *testing.T) {
+	c, _, _ := testCLI(`{"reference": "nowhere!", "places": [{"location": "CWC8+R9"}]}`)
+	_, err := c.loadDataset("-")
+	var parseErr *utils.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "reference" {
+		t.Fatalf("Expected *utils.ParseError for the reference, got %v", err)
+	}
+	if got, want := describeError(err), `The document has an invalid reference location: "nowhere!" is not a recognised location.`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
 }
 
 func TestImportDataFromFileValidJSON(t *testing.T) {
-	// Create a temporary test file
 	fileContent := `{
 		"places": [
 			{
@@ -49,20 +124,36 @@ func TestImportDataFromFileValidJSON(t *testing.T) {
 		"earthRadius": 6371.0,
 		"formula": "haversine"
 	}`
+	filePath := filepath.Join(t.TempDir(), "valid.json")
+	if err := os.WriteFile(filePath, []byte(fileContent), 0644); err != nil {
+		t.Fatalf("Error creating test file: %v", err)
+	}
 
-	filePath := "test_valid.json"
-	err := os.WriteFile(filePath, []byte(fileContent), 0644)
+	c, stdout, _ := testCLI(filePath + "\n")
+	dataset, err := c.importDataFromFile()
 	if err != nil {
-		t.Fatalf("Error creating test file: %v", err)
+		t.Fatal(err)
+	}
+	if len(dataset.Path) != 2 || dataset.Formula != "haversine" || dataset.Path[1].Name != "Los Angeles" {
+		t.Errorf("Unexpected dataset %+v", dataset)
 	}
-	defer os.Remove(filePath)
+	if want := "Enter the path to the file (or - for standard input): Data imported from " + filePath + ":\n\n"; stdout.String() != want {
+		t.Errorf("got prompts %q, want %q", stdout, want)
+	}
+}
 
-	// Test the function
-	importDataFromFile()
+func TestImportDataFromFileStandardInput(t *testing.T) {
+	c, _, _ := testCLI("-\nname,lat,lon\nNew York,40.7128,-74.0060\nChicago,41.8781,-87.6298\n")
+	dataset, err := c.importDataFromFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset.Path) != 2 || dataset.Path[1].Name != "Chicago" {
+		t.Errorf("Unexpected dataset %+v", dataset)
+	}
 }
 
 func TestImportDataFromFileInvalidFormat(t *testing.T) {
-	// Create a temporary test file with invalid format
 	fileContent := `{
 		"places": [
 			{
@@ -74,47 +165,155 @@ func TestImportDataFromFileInvalidFormat(t *testing.T) {
 		"earthRadius": 6371.0,
 		"formula": "haversine"
 	}`
-
-	filePath := "test_invalid.json"
-	err := os.WriteFile(filePath, []byte(fileContent), 0644)
-	if err != nil {
+	filePath := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(filePath, []byte(fileContent), 0644); err != nil {
 		t.Fatalf("Error creating test file: %v", err)
 	}
-	defer os.Remove(filePath)
 
-	// Test the function
-	importDataFromFile()
+	c, _, _ := testCLI(filePath + "\n")
+	_, err := c.importDataFromFile()
+	var parseErr *utils.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "latitude" {
+		t.Errorf("Expected *utils.ParseError for latitude, got %v", err)
+	}
 }
 
 func TestImportDataFromFileNonJSON(t *testing.T) {
-	// Create a temporary test file with non-JSON content
-	fileContent := "This is not JSON"
-	filePath := "test.txt"
-	err := os.WriteFile(filePath, []byte(fileContent), 0644)
-	if err != nil {
+	filePath := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(filePath, []byte("<kml>This is not JSON</kml>"), 0644); err != nil {
 		t.Fatalf("Error creating test file: %v", err)
 	}
-	defer os.Remove(filePath)
 
-	// Test the function
-	importDataFromFile()
+	c, _, _ := testCLI(filePath + "\n")
+	if _, err := c.importDataFromFile(); !errors.Is(err, utils.ErrUnknownFormat) {
+		t.Errorf("Expected utils.ErrUnknownFormat, got %v", err)
+	}
 }
 
 func TestImportDataFromFileNonexistent(t *testing.T) {
-	// Test with a nonexistent file
-	importDataFromFile()
+	c, _, _ := testCLI("nonexistent.json\n")
+	if _, err := c.importDataFromFile(); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("Expected utils.ErrNotFound, got %v", err)
+	}
+}
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{
+			&utils.ParseError{Index: 6, Name: "Denver", Field: "latitude", Value: "abc", Err: strconv.ErrSyntax},
+			`Place 7 (Denver) has an invalid latitude: "abc" is not a number in degrees.`,
+		},
+		{
+			&utils.ParseError{Index: 0, Field: "elevation", Value: "12", Err: geo.ErrUnknownUnit},
+			`Place 1 has an elevation, but the unit of the radius is not known to convert it from metres. Give it next to earthRadius, such as "unit": "km".`,
+		},
+		{
+			fmt.Errorf("cannot tell moving legs from stopped ones: %w", geo.ErrUnknownUnit),
+			`The places have times, but the unit of the radius is not known to tell moving from stopped legs. Give it next to earthRadius, such as "unit": "km".`,
+		},
+		{
+			&utils.ParseError{Index: -1, Field: "reference", Value: "nowhere!", Err: utils.ErrUnknownLocation},
+			`The document has an invalid reference location: "nowhere!" is not a recognised location.`,
+		},
+		{
+			&utils.ParseError{Index: 1, Field: "location", Value: "9q8a", Err: utils.ErrUnknownLocation},
+			`Place 2 has an invalid location: "9q8a" is not a recognised location.`,
+		},
+		{
+			&utils.ParseError{Index: 3, Field: "longitude", Value: "190", Err: utils.ErrOutOfRange},
+			"Place 4 has an invalid longitude: 190 is outside [-180, 180] degrees.",
+		},
+		{
+			&utils.ParseError{Index: 0, Field: "elevation", Value: "Inf", Err: utils.ErrOutOfRange},
+			"Place 1 has an invalid elevation: Inf is not a finite number.",
+		},
+		{
+			&utils.ParseError{Index: 2, Name: "Summit", Field: "elevation", Value: "high", Err: strconv.ErrSyntax},
+			`Place 3 (Summit) has an invalid elevation: "high" is not a number in metres.`,
+		},
+		{
+			&utils.ParseError{Index: 0, Field: "time", Value: "noon", Err: errors.New("cannot parse")},
+			`Place 1 has an invalid time: "noon" is not an RFC 3339 time such as 2024-05-01T09:30:00Z.`,
+		},
+		{
+			&utils.ParseError{Index: 0, Name: "Office", Field: "location", Value: "CWC8+R9", Err: utils.ErrNoReference},
+			`Place 1 (Office) has the short plus code "CWC8+R9", but the document has no reference location to recover it near.`,
+		},
+		{
+			&utils.FileError{Path: "places.json", Kind: utils.ErrNotFound, Err: fs.ErrNotExist},
+			"Could not find the file places.json.",
+		},
+		{
+			&utils.FileError{Path: "places.json", Kind: utils.ErrSyntax, Err: errors.New("unexpected EOF")},
+			"The contents of the file places.json are not a valid places document: unexpected EOF.",
+		},
+		{
+			&utils.FileError{Kind: utils.ErrSyntax, Err: errors.New("unexpected EOF")},
+			"The contents of standard input are not a valid places document: unexpected EOF.",
+		},
+		{
+			fmt.Errorf("%w: %q", geo.ErrUnknownFormula, "invalid"),
+			"Invalid formula.",
+		},
+		{
+			errors.New("boom"),
+			"boom",
+		},
+	}
+	for _, tt := range tests {
+		if got := describeError(tt.err); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
 }
 
 func TestValidFormulas(t *testing.T) {
-	// Test that all valid formulas are in the slice
+	// Test that all valid formulas are registered
 	expectedFormulas := []string{"haversine", "vincenty", "sloc"}
 	for _, formula := range expectedFormulas {
-		if !slices.Contains(validFormulas, formula) {
-			t.Errorf("Expected formula %s to be in validFormulas", formula)
+		if !slices.Contains(formulas.Names(), formula) {
+			t.Errorf("Expected formula %s to be registered", formula)
 		}
 	}
 }
 
 func TestImportDataFromUser(t *testing.T) {
-	importDataFromUser()
+	c, stdout, _ := testCLI("2\n40.7128 -74.0060\n34.0522\n-118.2437\n6371\nhaversine\n")
+	dataset, err := c.importDataFromUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geo.Path{{Lat: 40.7128, Lon: -74.0060}, {Lat: 34.0522, Lon: -118.2437}}
+	if !slices.Equal(dataset.Path, want) || dataset.Body.Radius != 6371 || dataset.Formula != "haversine" {
+		t.Errorf("Unexpected dataset %+v", dataset)
+	}
+	if !strings.HasPrefix(stdout.String(), "Enter the number of points: ") || !strings.Contains(stdout.String(), "Enter the formula to use (haversine or vincenty") {
+		t.Errorf("Unexpected prompts %q", stdout)
+	}
+
+	c, _, _ = testCLI("1\n0\n0\n6371\nflat\n")
+	if _, err := c.importDataFromUser(); !errors.Is(err, geo.ErrUnknownFormula) {
+		t.Errorf("Expected geo.ErrUnknownFormula, got %v", err)
+	}
+}
+
+func TestWithColumns(t *testing.T) {
+	format, _ := output.Lookup("csv")
+	if _, err := withColumns(format, ""); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := withColumns(format, "utm, mgrs"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := withColumns(format, "utm,w3w"); err == nil || !strings.Contains(err.Error(), `"w3w"`) {
+		t.Errorf("Expected an unknown column error, got %v", err)
+	}
+
+	plain := output.Format{Name: "plain", Writer: output.WriterFunc(func(w io.Writer, result *geo.Result) error { return nil })}
+	if _, err := withColumns(plain, "utm"); err == nil {
+		t.Error("Expected an error for a format without column support")
+	}
 }
@@ -0,0 +1,73 @@
+// Package metrics collects counters and histograms and writes them in the
+// Prometheus text exposition format, for scraping from a /metrics endpoint or
+// for the textfile collector of a node exporter after a batch run.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// Distances holds the metrics recorded by the server and the command line.
+type Distances struct {
+	Registry *Registry
+
+	// Requests counts HTTP requests by endpoint, formula and status code.
+	Requests *Counter
+	// RequestDuration measures HTTP request latency by endpoint and formula.
+	RequestDuration *Histogram
+	// Points counts the places measured, by formula.
+	Points *Counter
+	// ParseFailures counts places documents that could not be read, by the
+	// kind returned by FailureKind.
+	ParseFailures *Counter
+	// Files counts the places files of batch runs, by whether they
+	// "succeeded" or "failed".
+	Files *Counter
+}
+
+// NewDistances registers the go-distances metrics in a new registry.
+func NewDistances() *Distances {
+	r := NewRegistry()
+	return &Distances{
+		Registry:        r,
+		Requests:        r.NewCounter("go_distances_requests_total", "HTTP requests handled, by endpoint, formula and status code.", "endpoint", "formula", "code"),
+		RequestDuration: r.NewHistogram("go_distances_request_duration_seconds", "Time taken to handle HTTP requests, by endpoint and formula.", DefaultBuckets, "endpoint", "formula"),
+		Points:          r.NewCounter("go_distances_points_processed_total", "Places measured, by formula.", "formula"),
+		ParseFailures:   r.NewCounter("go_distances_parse_failures_total", "Places documents that could not be read, by kind of failure.", "kind"),
+		Files:           r.NewCounter("go_distances_batch_files_total", "Places files processed by batch runs, by result.", "result"),
+	}
+}
+
+// ParseFailure counts err in ParseFailures when FailureKind classifies it.
+func (d *Distances) ParseFailure(err error) {
+	if kind := FailureKind(err); kind != "" {
+		d.ParseFailures.Inc(kind)
+	}
+}
+
+// FailureKind classifies an error returned while reading a places document:
+// the offending field, such as "latitude" or "time", for invalid places, and
+// one of "not_found", "read", "too_large", "unknown_format" or "syntax" for
+// documents as a whole. It returns "" for other errors.
+func FailureKind(err error) string {
+	var parseErr *utils.ParseError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &parseErr):
+		return parseErr.Field
+	case errors.As(err, &maxBytesErr):
+		return "too_large"
+	case errors.Is(err, utils.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, utils.ErrRead):
+		return "read"
+	case errors.Is(err, utils.ErrUnknownFormat):
+		return "unknown_format"
+	case errors.Is(err, utils.ErrSyntax):
+		return "syntax"
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/utils"
+)
+
+func TestFailureKind(t *testing.T) {
+	parseErr := &utils.ParseError{Field: "latitude", Value: "north", Err: errors.New("not a number")}
+	_, syntaxErr := utils.Decode(strings.NewReader("{"))
+	_, formatErr := utils.Decode(strings.NewReader("<kml></kml>"))
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{parseErr, "latitude"},
+		{syntaxErr, "syntax"},
+		{formatErr, "unknown_format"},
+		{&utils.FileError{Path: "places.json", Kind: utils.ErrNotFound, Err: errors.New("no such file")}, "not_found"},
+		{&utils.FileError{Kind: utils.ErrRead, Err: &http.MaxBytesError{Limit: 10}}, "too_large"},
+		{&utils.FileError{Kind: utils.ErrRead, Err: errors.New("reset")}, "read"},
+		{fmt.Errorf("some other error"), ""},
+	}
+	for _, tt := range tests {
+		if got := FailureKind(tt.err); got != tt.want {
+			t.Errorf("FailureKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+
+	d := NewDistances()
+	d.ParseFailure(parseErr)
+	d.ParseFailure(errors.New("not a parse failure"))
+	if got := d.ParseFailures.Value("latitude"); got != 1 {
+		t.Errorf("ParseFailures = %v, want 1", got)
+	}
+}
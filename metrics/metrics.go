@@ -0,0 +1,311 @@
+// Package metrics collects counters and histograms and writes them in the
+// Prometheus text exposition format, for scraping from a /metrics endpoint or
+// for the textfile collector of a node exporter after a batch run.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of latency
+// histograms.
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds metrics and writes them in registration order.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// metric is a family of time series with one name.
+type metric interface {
+	write(w io.Writer) error
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// Write writes every metric of the registry to w in the Prometheus text
+// format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler returns an HTTP handler serving the metrics of the registry.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// family holds the series of a metric, keyed by their label values.
+type family[S any] struct {
+	name, help, kind string
+	labels           []string
+	mu               sync.Mutex
+	series           map[string]*S
+	values           map[string][]string
+}
+
+func newFamily[S any](name, help, kind string, labels []string) *family[S] {
+	return &family[S]{name: name, help: help, kind: kind, labels: labels, series: map[string]*S{}, values: map[string][]string{}}
+}
+
+// get returns the series with the given label values, creating it with
+// create when it does not exist yet. It panics when the number of values
+// does not match the labels of the family, which is a programming error.
+func (f *family[S]) get(values []string, create func() *S) *S {
+	key := f.key(values)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = create()
+		f.series[key] = s
+		f.values[key] = slices.Clone(values)
+	}
+	return s
+}
+
+// lookup returns the series with the given label values, without creating
+// it, so that reading a metric does not add a series to its output. ok is
+// false when the series does not exist yet.
+func (f *family[S]) lookup(values []string) (s *S, ok bool) {
+	key := f.key(values)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok = f.series[key]
+	return s, ok
+}
+
+// key returns the key of the series with the given label values. It panics
+// when the number of values does not match the labels of the family, which
+// is a programming error.
+func (f *family[S]) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", f.name, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// each calls fn for every series, sorted by label values.
+func (f *family[S]) each(fn func(labels string, s *S) error) error {
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	f.mu.Unlock()
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		f.mu.Lock()
+		s, values := f.series[key], f.values[key]
+		f.mu.Unlock()
+		if err := fn(formatLabels(f.labels, values), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *family[S]) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+	return err
+}
+
+// Counter is a family of monotonically increasing counters.
+type Counter struct {
+	family *family[counterSeries]
+}
+
+type counterSeries struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// NewCounter registers a counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{family: newFamily[counterSeries](name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+// Add adds delta, which must not be negative, to the counter with the given
+// label values.
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	s := c.family.get(values, func() *counterSeries { return &counterSeries{} })
+	s.mu.Lock()
+	s.value += delta
+	s.mu.Unlock()
+}
+
+// Inc adds one to the counter with the given label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Value returns the value of the counter with the given label values, which
+// is zero for a counter that has not been added to.
+func (c *Counter) Value(values ...string) float64 {
+	s, ok := c.family.lookup(values)
+	if !ok {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
+
+func (c *Counter) write(w io.Writer) error {
+	if err := c.family.writeHeader(w); err != nil {
+		return err
+	}
+	return c.family.each(func(labels string, s *counterSeries) error {
+		s.mu.Lock()
+		value := s.value
+		s.mu.Unlock()
+		_, err := fmt.Fprintf(w, "%s%s %s\n", c.family.name, labels, formatValue(value))
+		return err
+	})
+}
+
+// Histogram is a family of histograms with shared bucket bounds.
+type Histogram struct {
+	family  *family[histogramSeries]
+	buckets []float64
+}
+
+type histogramSeries struct {
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds, in
+// increasing order, and label names.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{family: newFamily[histogramSeries](name, help, "histogram", labels), buckets: slices.Clone(buckets)}
+	r.register(h)
+	return h
+}
+
+// Observe records value in the histogram with the given label values.
+func (h *Histogram) Observe(value float64, values ...string) {
+	s := h.family.get(values, func() *histogramSeries {
+		return &histogramSeries{counts: make([]uint64, len(h.buckets)+1)}
+	})
+	i, _ := slices.BinarySearch(h.buckets, value)
+	s.mu.Lock()
+	s.counts[i]++
+	s.sum += value
+	s.count++
+	s.mu.Unlock()
+}
+
+// Count returns the number of values observed by the histogram with the
+// given label values.
+func (h *Histogram) Count(values ...string) uint64 {
+	s, ok := h.family.lookup(values)
+	if !ok {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	if err := h.family.writeHeader(w); err != nil {
+		return err
+	}
+	return h.family.each(func(labels string, s *histogramSeries) error {
+		s.mu.Lock()
+		counts, sum, count := slices.Clone(s.counts), s.sum, s.count
+		s.mu.Unlock()
+
+		var cumulative uint64
+		for i, n := range counts {
+			cumulative += n
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = formatValue(h.buckets[i])
+			}
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.family.name, withLabel(labels, "le", le), cumulative); err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.family.name, labels, formatValue(sum), h.family.name, labels, count)
+		return err
+	})
+}
+
+// formatLabels formats label names and values as {name="value",...}, or ""
+// when there are none.
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel adds a label to labels formatted by formatLabels.
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + labelEscaper.Replace(value) + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// formatValue formats a sample value as Prometheus expects.
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
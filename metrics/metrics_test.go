@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests handled.", "endpoint", "code")
+	latency := r.NewHistogram("latency_seconds", "Request latency.", []float64{0.1, 1}, "endpoint")
+	runs := r.NewCounter("runs_total", "Runs.\nWith a second line.")
+
+	requests.Inc("route", "200")
+	requests.Add(2, "distance", "200")
+	requests.Inc("route", `4"0\0`)
+	latency.Observe(0.05, "route")
+	latency.Observe(0.1, "route")
+	latency.Observe(3, "route")
+	runs.Inc()
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{endpoint="distance",code="200"} 2
+requests_total{endpoint="route",code="200"} 1
+requests_total{endpoint="route",code="4\"0\\0"} 1
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{endpoint="route",le="0.1"} 2
+latency_seconds_bucket{endpoint="route",le="1"} 2
+latency_seconds_bucket{endpoint="route",le="+Inf"} 3
+latency_seconds_sum{endpoint="route"} 3.15
+latency_seconds_count{endpoint="route"} 3
+# HELP runs_total Runs.\nWith a second line.
+# TYPE runs_total counter
+runs_total 1
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("points_total", "Points.", "formula")
+	h := r.NewHistogram("latency_seconds", "Latency.", DefaultBuckets, "formula")
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				c.Inc("haversine")
+				h.Observe(0.01, "haversine")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := c.Value("haversine"); got != 8000 {
+		t.Errorf("Value = %v, want 8000", got)
+	}
+	if got := h.Count("haversine"); got != 8000 {
+		t.Errorf("Count = %v, want 8000", got)
+	}
+}
+
+func TestReadingCreatesNoSeries(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("points_total", "Points.", "formula")
+	h := r.NewHistogram("latency_seconds", "Latency.", DefaultBuckets, "formula")
+	if c.Value("haversine") != 0 || h.Count("haversine") != 0 {
+		t.Fatal("unobserved series are not zero")
+	}
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "haversine") {
+		t.Errorf("reading added series:\n%s", b.String())
+	}
+}
+
+func TestCounterPanics(t *testing.T) {
+	c := NewRegistry().NewCounter("points_total", "Points.", "formula")
+	for name, f := range map[string]func(){
+		"negative":     func() { c.Add(-1, "haversine") },
+		"label values": func() { c.Inc() },
+		"read values":  func() { c.Value("haversine", "200") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("runs_total", "Runs.").Inc()
+	recorder := httptest.NewRecorder()
+	r.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if !strings.Contains(recorder.Body.String(), "runs_total 1\n") {
+		t.Errorf("Unexpected body %q", recorder.Body.String())
+	}
+}
@@ -0,0 +1,113 @@
+// Package nvector represents horizontal positions as n-vectors, the unit
+// normals to the Earth's surface, which make great-circle distance,
+// interpolation and averaging free of the singularities of latitude and
+// longitude at the poles and antimeridian.
+//
+// See Gade, "A Non-singular Horizontal Position Representation" (2010).
+package nvector
+
+import (
+	"errors"
+	"math"
+
+	"github.com/dickeyy/go-distances/ecef"
+	"github.com/dickeyy/go-distances/ellipsoid"
+	"github.com/dickeyy/go-distances/utils"
+)
+
+var (
+	// ErrAntipodal is returned when interpolating between antipodal
+	// positions, which have no unique great circle between them.
+	ErrAntipodal = errors.New("positions are antipodal")
+	// ErrNoMean is returned when positions have no mean, because there are
+	// none or they are spread evenly around the globe.
+	ErrNoMean = errors.New("positions have no mean")
+)
+
+// Vector is an n-vector, a unit vector in the axes of ecef.Vector.
+type Vector ecef.Vector
+
+// FromLatLon returns the n-vector of a latitude and longitude in degrees.
+// For a geodetic latitude, the vector is normal to the ellipsoid; for a
+// latitude on a sphere, to the sphere.
+func FromLatLon(lat, lon float64) Vector {
+	phi, lambda := utils.DegreeToRad(lat), utils.DegreeToRad(lon)
+	return Vector{
+		X: math.Cos(phi) * math.Cos(lambda),
+		Y: math.Cos(phi) * math.Sin(lambda),
+		Z: math.Sin(phi),
+	}
+}
+
+// LatLon returns the latitude and longitude of n in degrees.
+func (n Vector) LatLon() (lat, lon float64) {
+	lat = utils.RadToDegree(math.Atan2(n.Z, math.Hypot(n.X, n.Y)))
+	lon = utils.RadToDegree(math.Atan2(n.Y, n.X))
+	return lat, lon
+}
+
+// FromECEF returns the n-vector of an ECEF position on the ellipsoid, along
+// with its height above the ellipsoid.
+func FromECEF(e ellipsoid.Ellipsoid, v ecef.Vector) (n Vector, height float64) {
+	lat, lon, height := v.Geodetic(e)
+	return FromLatLon(lat, lon), height
+}
+
+// ECEF returns the ECEF position of n at the given height above the
+// ellipsoid.
+func (n Vector) ECEF(e ellipsoid.Ellipsoid, height float64) ecef.Vector {
+	// scale the normal onto the ellipsoid, following Gade's equation 22
+	b := e.B()
+	ratio := e.A / b
+	denominator := math.Sqrt(n.Z*n.Z + ratio*ratio*(n.X*n.X+n.Y*n.Y))
+	surface := b / denominator
+	return ecef.Vector{
+		X: surface*ratio*ratio*n.X + height*n.X,
+		Y: surface*ratio*ratio*n.Y + height*n.Y,
+		Z: surface*n.Z + height*n.Z,
+	}
+}
+
+// Angle returns the great-circle angle between n and m in radians. It is
+// well-conditioned for all angles, unlike formulas based on acos or asin.
+func (n Vector) Angle(m Vector) float64 {
+	a, b := ecef.Vector(n), ecef.Vector(m)
+	return math.Atan2(a.Cross(b).Norm(), a.Dot(b))
+}
+
+// Distance returns the great-circle distance between n and m on a sphere of
+// the given radius.
+func Distance(n, m Vector, radius float64) float64 {
+	return n.Angle(m) * radius
+}
+
+// Interpolate returns the position a fraction t of the way from n to m along
+// the great circle between them. t outside [0, 1] extrapolates along the
+// same great circle.
+func Interpolate(n, m Vector, t float64) (Vector, error) {
+	angle := n.Angle(m)
+	if angle == 0 {
+		return n, nil
+	}
+	sin := math.Sin(angle)
+	if sin < 1e-15 {
+		return Vector{}, ErrAntipodal
+	}
+	a, b := ecef.Vector(n), ecef.Vector(m)
+	v := a.Scale(math.Sin((1-t)*angle) / sin).Add(b.Scale(math.Sin(t*angle) / sin))
+	return Vector(v.Scale(1 / v.Norm())), nil
+}
+
+// Mean returns the geographical mean of positions, the normalised sum of
+// their n-vectors.
+func Mean(positions ...Vector) (Vector, error) {
+	var sum ecef.Vector
+	for _, n := range positions {
+		sum = sum.Add(ecef.Vector(n))
+	}
+	norm := sum.Norm()
+	if norm < 1e-12*float64(len(positions)) || len(positions) == 0 {
+		return Vector{}, ErrNoMean
+	}
+	return Vector(sum.Scale(1 / norm)), nil
+}
@@ -0,0 +1,101 @@
+package nvector
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/dickeyy/go-distances/ecef"
+	"github.com/dickeyy/go-distances/ellipsoid"
+)
+
+func TestLatLonRoundTrip(t *testing.T) {
+	for _, p := range [][2]float64{{0, 0}, {45, 90}, {-33.86, 151.2}, {89.999999, -179.5}} {
+		lat, lon := FromLatLon(p[0], p[1]).LatLon()
+		if math.Abs(lat-p[0]) > 1e-12 || math.Abs(lon-p[1]) > 1e-12 {
+			t.Errorf("%v -> %v, %v", p, lat, lon)
+		}
+	}
+}
+
+func TestECEF(t *testing.T) {
+	for _, p := range [][3]float64{{0, 0, 0}, {53.80939444, 2.12955, 73}, {-90, 0, 100}, {45, -120, -400}} {
+		n := FromLatLon(p[0], p[1])
+		got := n.ECEF(ellipsoid.WGS84, p[2])
+		want := ecef.FromGeodetic(ellipsoid.WGS84, p[0], p[1], p[2])
+		if got.Distance(want) > 1e-6 {
+			t.Errorf("%v: ECEF = %+v, want %+v", p, got, want)
+		}
+
+		back, height := FromECEF(ellipsoid.WGS84, got)
+		if ecef.Vector(back).Distance(ecef.Vector(n)) > 1e-12 || math.Abs(height-p[2]) > 1e-6 {
+			t.Errorf("%v: FromECEF = %+v, %v", p, back, height)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// example 5 of Gade's paper
+	d := Distance(FromLatLon(88, 0), FromLatLon(89, -170), 6371e3)
+	if math.Abs(d-332456.4) > 0.1 {
+		t.Errorf("Distance = %f, want 332456.4", d)
+	}
+
+	// coincident and antipodal points are well-conditioned
+	if d := Distance(FromLatLon(10, 20), FromLatLon(10, 20), 1); d != 0 {
+		t.Errorf("Distance between coincident points = %v", d)
+	}
+	if d := Distance(FromLatLon(10, 20), FromLatLon(-10, -160), 1); math.Abs(d-math.Pi) > 1e-15 {
+		t.Errorf("Distance between antipodal points = %v, want π", d)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	// example 6 of Gade's paper: 6/10 of the way over the north pole
+	n, err := Interpolate(FromLatLon(89, 0), FromLatLon(89, 180), 0.6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, lon := n.LatLon()
+	if math.Abs(lat-89.8) > 1e-9 || math.Abs(math.Abs(lon)-180) > 1e-9 {
+		t.Errorf("Interpolate = %v, %v, want 89.8, 180", lat, lon)
+	}
+
+	n, _ = Interpolate(FromLatLon(0, 0), FromLatLon(0, 90), 0.5)
+	if lat, lon := n.LatLon(); math.Abs(lat) > 1e-12 || math.Abs(lon-45) > 1e-12 {
+		t.Errorf("Interpolate midpoint = %v, %v, want 0, 45", lat, lon)
+	}
+	n, _ = Interpolate(FromLatLon(0, 0), FromLatLon(0, 90), 2)
+	if lat, lon := n.LatLon(); math.Abs(lat) > 1e-12 || math.Abs(lon-180) > 1e-12 {
+		t.Errorf("Interpolate extrapolated = %v, %v, want 0, 180", lat, lon)
+	}
+
+	if _, err := Interpolate(FromLatLon(0, 0), FromLatLon(0, 180), 0.5); !errors.Is(err, ErrAntipodal) {
+		t.Errorf("Interpolate between antipodes error = %v, want ErrAntipodal", err)
+	}
+}
+
+func TestMean(t *testing.T) {
+	// example 7 of Gade's paper
+	n, err := Mean(FromLatLon(90, 0), FromLatLon(60, 10), FromLatLon(50, -20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, lon := n.LatLon()
+	if math.Abs(lat-67.2362) > 1e-4 || math.Abs(lon-(-6.9175)) > 1e-4 {
+		t.Errorf("Mean = %v, %v, want 67.2362, -6.9175", lat, lon)
+	}
+
+	// the mean does not break at the antimeridian
+	n, _ = Mean(FromLatLon(0, 179), FromLatLon(0, -179))
+	if lat, lon := n.LatLon(); math.Abs(lat) > 1e-12 || math.Abs(math.Abs(lon)-180) > 1e-12 {
+		t.Errorf("Mean across the antimeridian = %v, %v, want 0, 180", lat, lon)
+	}
+
+	if _, err := Mean(); !errors.Is(err, ErrNoMean) {
+		t.Errorf("Mean() error = %v, want ErrNoMean", err)
+	}
+	if _, err := Mean(FromLatLon(0, 0), FromLatLon(0, 180)); !errors.Is(err, ErrNoMean) {
+		t.Errorf("Mean of antipodes error = %v, want ErrNoMean", err)
+	}
+}
@@ -0,0 +1,359 @@
+// Package olc encodes and decodes Open Location Codes, also known as Plus
+// Codes, such as "849VCWC8+R9". Full codes name an area on their own; short
+// codes such as "CWC8+R9" omit leading digits and are recovered relative to
+// a nearby reference location.
+//
+// See https://github.com/google/open-location-code/blob/main/Documentation/Specification/specification.md.
+package olc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// Separator follows the eighth digit of a code.
+	Separator = '+'
+	// Padding fills the digits of a full code shorter than eight digits.
+	Padding = '0'
+
+	// alphabet is the base-20 digit alphabet.
+	alphabet = "23456789CFGHJMPQRVWX"
+
+	// separatorPosition is the number of digits before the separator.
+	separatorPosition = 8
+	// pairCodeLength is the number of digits encoded as latitude/longitude
+	// pairs; further digits each refine a 4×5 grid.
+	pairCodeLength = 10
+	// MinLength and MaxLength bound the number of digits of a code.
+	MinLength = 2
+	MaxLength = 15
+	// DefaultLength names an area about 14 metres across.
+	DefaultLength = 10
+
+	base     = 20
+	gridRows = 5
+	gridCols = 4
+
+	// pairFirstPlaceValue is the value of the first pair digit in units of
+	// the last pair digit, base⁴.
+	pairFirstPlaceValue = 160000
+	// pairPrecision is the number of last pair digits in a degree, base³.
+	pairPrecision = 8000
+	// gridLatFullValue and gridLngFullValue are the number of grid cells,
+	// in each direction, in a pair cell: gridRows⁵ and gridCols⁵.
+	gridLatFullValue = 3125
+	gridLngFullValue = 1024
+	// gridLatFirstPlaceValue and gridLngFirstPlaceValue are the values of
+	// the first grid digit in units of the last one.
+	gridLatFirstPlaceValue = 625
+	gridLngFirstPlaceValue = 256
+	// finalLatPrecision and finalLngPrecision are the number of cells of a
+	// MaxLength code in a degree.
+	finalLatPrecision = pairPrecision * gridLatFullValue
+	finalLngPrecision = pairPrecision * gridLngFullValue
+)
+
+var (
+	// ErrInvalid is wrapped by errors for strings that are not codes.
+	ErrInvalid = errors.New("invalid plus code")
+	// ErrNotFull is wrapped by errors for operations that need a full code.
+	ErrNotFull = errors.New("not a full plus code")
+	// ErrNotShort is wrapped by errors for operations that need a short code.
+	ErrNotShort = errors.New("not a short plus code")
+)
+
+// CodeArea is the area named by a code, in degrees.
+type CodeArea struct {
+	LatLo, LngLo, LatHi, LngHi float64
+	// Len is the number of digits of the code, without padding.
+	Len int
+}
+
+// Center returns the point in the middle of the area. It is clipped to the
+// poles and antimeridian, so it is inside the area even for codes whose
+// nominal area extends past them.
+func (a CodeArea) Center() (lat, lng float64) {
+	lat = min((a.LatLo+a.LatHi)/2, 90)
+	lng = min((a.LngLo+a.LngHi)/2, 180)
+	return lat, lng
+}
+
+// Encode returns the code of the given length, in digits, for the area that
+// contains the point. length is clamped to [MinLength, MaxLength], and odd
+// lengths below pairCodeLength are rounded up. Latitudes are clamped to
+// [-90, 90] and longitudes are wrapped into [-180, 180).
+func Encode(lat, lng float64, length int) string {
+	length = max(MinLength, min(length, MaxLength))
+	if length < pairCodeLength && length%2 == 1 {
+		length++
+	}
+	lat = max(-90, min(lat, 90))
+	lng = normalizeLongitude(lng)
+	// the area of a code on the north pole would extend past it
+	if lat == 90 {
+		lat -= latitudePrecision(length)
+	}
+
+	// work in integer units of the smallest cell to avoid rounding errors
+	latVal := int64(math.Round((lat+90)*finalLatPrecision*1e6) / 1e6)
+	lngVal := int64(math.Round((lng+180)*finalLngPrecision*1e6) / 1e6)
+
+	var digits [MaxLength]byte
+	if length > pairCodeLength {
+		for i := MaxLength - 1; i >= pairCodeLength; i-- {
+			digits[i] = alphabet[latVal%gridRows*gridCols+lngVal%gridCols]
+			latVal /= gridRows
+			lngVal /= gridCols
+		}
+	} else {
+		latVal /= gridLatFullValue
+		lngVal /= gridLngFullValue
+	}
+	for i := pairCodeLength - 1; i > 0; i -= 2 {
+		digits[i-1] = alphabet[latVal%base]
+		digits[i] = alphabet[lngVal%base]
+		latVal /= base
+		lngVal /= base
+	}
+
+	var code strings.Builder
+	if length < separatorPosition {
+		code.Write(digits[:length])
+		code.WriteString(strings.Repeat(string(Padding), separatorPosition-length))
+		code.WriteByte(Separator)
+		return code.String()
+	}
+	code.Write(digits[:separatorPosition])
+	code.WriteByte(Separator)
+	code.Write(digits[separatorPosition:length])
+	return code.String()
+}
+
+// Decode returns the area named by a full code.
+func Decode(code string) (CodeArea, error) {
+	if err := checkFull(code); err != nil {
+		return CodeArea{}, err
+	}
+	digits := strings.ToUpper(code)
+	digits = strings.ReplaceAll(digits, string(Separator), "")
+	digits = strings.TrimRight(digits, string(Padding))
+	digits = digits[:min(len(digits), MaxLength)]
+
+	latVal := int64(-90 * finalLatPrecision)
+	lngVal := int64(-180 * finalLngPrecision)
+	place := int64(pairFirstPlaceValue)
+	pairs := min(len(digits), pairCodeLength)
+	for i := 0; i < pairs-1; i += 2 {
+		latVal += int64(strings.IndexByte(alphabet, digits[i])) * place * gridLatFullValue
+		lngVal += int64(strings.IndexByte(alphabet, digits[i+1])) * place * gridLngFullValue
+		if i < pairs-2 {
+			place /= base
+		}
+	}
+	latPrecision := float64(place) / pairPrecision
+	lngPrecision := float64(place) / pairPrecision
+
+	if len(digits) > pairCodeLength {
+		rowPlace := int64(gridLatFirstPlaceValue)
+		colPlace := int64(gridLngFirstPlaceValue)
+		for i := pairCodeLength; i < len(digits); i++ {
+			value := int64(strings.IndexByte(alphabet, digits[i]))
+			latVal += value / gridCols * rowPlace
+			lngVal += value % gridCols * colPlace
+			if i < len(digits)-1 {
+				rowPlace /= gridRows
+				colPlace /= gridCols
+			}
+		}
+		latPrecision = float64(rowPlace) / finalLatPrecision
+		lngPrecision = float64(colPlace) / finalLngPrecision
+	}
+
+	lat := float64(latVal) / finalLatPrecision
+	lng := float64(lngVal) / finalLngPrecision
+	return CodeArea{
+		LatLo: lat,
+		LngLo: lng,
+		LatHi: lat + latPrecision,
+		LngHi: lng + lngPrecision,
+		Len:   len(digits),
+	}, nil
+}
+
+// Valid reports whether code is a full or short code. Codes are
+// case-insensitive.
+func Valid(code string) bool {
+	return check(code) == nil
+}
+
+// IsFull reports whether code is a valid full code.
+func IsFull(code string) bool {
+	return checkFull(code) == nil
+}
+
+// IsShort reports whether code is a valid short code.
+func IsShort(code string) bool {
+	return check(code) == nil && strings.IndexByte(code, Separator) < separatorPosition
+}
+
+// Shorten removes as many leading digits from a full code as possible while
+// keeping it recoverable from the reference location. Codes with padding
+// cannot be shortened and are returned unchanged.
+func Shorten(code string, lat, lng float64) (string, error) {
+	area, err := Decode(code)
+	if err != nil {
+		return "", err
+	}
+	code = strings.ToUpper(code)
+	if strings.IndexByte(code, Padding) >= 0 {
+		return code, nil
+	}
+
+	// a code can lose 2, 4 or 6 more leading digits the closer the reference
+	// is to its center, keeping a safety margin of 30% of the resolution
+	centerLat, centerLng := area.Center()
+	distance := max(math.Abs(centerLat-max(-90, min(lat, 90))), math.Abs(centerLng-normalizeLongitude(lng)))
+	for pairs := 4; pairs >= 2; pairs-- {
+		if distance < pairResolution(pairs)*0.3 {
+			return code[pairs*2:], nil
+		}
+	}
+	return code, nil
+}
+
+// RecoverNearest returns the full code nearest to the reference location
+// that ends with the short code. Full codes are returned unchanged.
+func RecoverNearest(code string, lat, lng float64) (string, error) {
+	if IsFull(code) {
+		return strings.ToUpper(code), nil
+	}
+	if err := check(code); err != nil {
+		return "", err
+	}
+	if !IsShort(code) {
+		return "", fmt.Errorf("%w: %q", ErrNotShort, code)
+	}
+	code = strings.ToUpper(code)
+	lat = max(-90, min(lat, 90))
+	lng = normalizeLongitude(lng)
+
+	// pad the short code with the leading digits of the reference, then move
+	// the result by one cell if that brings it closer to the reference
+	missing := separatorPosition - strings.IndexByte(code, Separator)
+	resolution := math.Pow(base, 2-float64(missing/2))
+	half := resolution / 2
+	area, err := Decode(Encode(lat, lng, pairCodeLength)[:missing] + code)
+	if err != nil {
+		return "", err
+	}
+	centerLat, centerLng := area.Center()
+	if lat+half < centerLat && centerLat-resolution >= -90 {
+		centerLat -= resolution
+	} else if lat-half > centerLat && centerLat+resolution <= 90 {
+		centerLat += resolution
+	}
+	if lng+half < centerLng {
+		centerLng -= resolution
+	} else if lng-half > centerLng {
+		centerLng += resolution
+	}
+	return Encode(centerLat, centerLng, area.Len), nil
+}
+
+// check reports whether code is syntactically a full or short code.
+func check(code string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %q %s", ErrInvalid, code, reason)
+	}
+
+	separator := strings.IndexByte(code, Separator)
+	switch {
+	case separator < 0:
+		return invalid("has no separator")
+	case strings.LastIndexByte(code, Separator) != separator:
+		return invalid("has more than one separator")
+	case separator > separatorPosition || separator%2 == 1:
+		return invalid("has the separator in the wrong place")
+	case len(code)-separator == 2:
+		return invalid("has a single digit after the separator")
+	}
+
+	if padding := strings.IndexByte(code, Padding); padding >= 0 {
+		end := strings.LastIndexByte(code, Padding)
+		switch {
+		case separator < separatorPosition:
+			return invalid("is short and padded")
+		case padding == 0 || padding%2 == 1:
+			return invalid("has padding in the wrong place")
+		case end != separator-1 || strings.Count(code[padding:end+1], string(Padding)) != end-padding+1:
+			return invalid("has padding that does not end at the separator")
+		case len(code) > separator+1:
+			return invalid("has digits after padding")
+		}
+	}
+
+	digits := 0
+	for i := range len(code) {
+		c := code[i]
+		if c == Separator || c == Padding {
+			continue
+		}
+		if strings.IndexByte(alphabet, upper(c)) < 0 {
+			return invalid(fmt.Sprintf("has invalid character %q", c))
+		}
+		digits++
+	}
+	if digits < MinLength {
+		return invalid("has too few digits")
+	}
+	return nil
+}
+
+// checkFull reports whether code is a full code.
+func checkFull(code string) error {
+	if err := check(code); err != nil {
+		return err
+	}
+	if strings.IndexByte(code, Separator) < separatorPosition {
+		return fmt.Errorf("%w: %q", ErrNotFull, code)
+	}
+	// the first latitude digit cannot exceed 180° and the first longitude
+	// digit 360°
+	if strings.IndexByte(alphabet, upper(code[0]))*base >= 180 ||
+		strings.IndexByte(alphabet, upper(code[1]))*base >= 360 {
+		return fmt.Errorf("%w: %q is outside the globe", ErrInvalid, code)
+	}
+	return nil
+}
+
+// latitudePrecision returns the height, in degrees, of the area of a code of
+// the given length.
+func latitudePrecision(length int) float64 {
+	if length <= pairCodeLength {
+		return math.Pow(base, float64(2-length/2))
+	}
+	return math.Pow(base, -3) / math.Pow(gridRows, float64(length-pairCodeLength))
+}
+
+// pairResolution returns the size, in degrees, of the area of a code with
+// the given number of digit pairs.
+func pairResolution(pairs int) float64 {
+	return math.Pow(base, float64(2-pairs))
+}
+
+func normalizeLongitude(lng float64) float64 {
+	if lng >= -180 && lng < 180 {
+		return lng
+	}
+	return math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
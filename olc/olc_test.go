@@ -0,0 +1,212 @@
+package olc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		length   int
+		want     string
+	}{
+		{20.375, 2.775, 6, "7FG49Q00+"},
+		{20.3700625, 2.7821875, 10, "7FG49QCJ+2V"},
+		{20.3701125, 2.782234375, 11, "7FG49QCJ+2VX"},
+		{20.3701135, 2.78223535156, 13, "7FG49QCJ+2VXGJ"},
+		{47.0000625, 8.0000625, 10, "8FVC2222+22"},
+		{-41.2730625, 174.7859375, 10, "4VCPPQGP+Q9"},
+		{0.5, -179.5, 4, "62G20000+"},
+		{-89.5, -179.5, 4, "22220000+"},
+		{-89.9999375, -179.9999375, 10, "22222222+22"},
+		{0.5, 179.5, 4, "6VGX0000+"},
+		{1, 1, 11, "6FH32222+222"},
+		{90, 1, 4, "CFX30000+"},
+		{92, 1, 4, "CFX30000+"},
+		{90, 1, 10, "CFX3X2X2+X2"},
+		{1, 180, 4, "62H20000+"},
+		{1, 181, 4, "62H30000+"},
+		{20.375, 2.775, 5, "7FG49Q00+"},
+		{20.375, 2.775, 1, "7F000000+"},
+	}
+	for _, tt := range tests {
+		if got := Encode(tt.lat, tt.lng, tt.length); got != tt.want {
+			t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		code string
+		want CodeArea
+	}{
+		{"7FG49Q00+", CodeArea{20.35, 2.75, 20.4, 2.8, 6}},
+		{"7fg49qcj+2v", CodeArea{20.37, 2.782125, 20.370125, 2.78225, 10}},
+		{"8FVC2222+22", CodeArea{47.0, 8.0, 47.000125, 8.000125, 10}},
+		{"CFX30000+", CodeArea{89, 1, 90, 2, 4}},
+	}
+	for _, tt := range tests {
+		got, err := Decode(tt.code)
+		if err != nil {
+			t.Errorf("Decode(%q): %v", tt.code, err)
+			continue
+		}
+		if got.Len != tt.want.Len ||
+			math.Abs(got.LatLo-tt.want.LatLo) > 1e-10 || math.Abs(got.LngLo-tt.want.LngLo) > 1e-10 ||
+			math.Abs(got.LatHi-tt.want.LatHi) > 1e-10 || math.Abs(got.LngHi-tt.want.LngHi) > 1e-10 {
+			t.Errorf("Decode(%q) = %+v, want %+v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestValidity(t *testing.T) {
+	tests := []struct {
+		code         string
+		valid, short bool
+	}{
+		{"8FWC2345+G6", true, false},
+		{"8FWC2345+G6G", true, false},
+		{"8fwc2345+", true, false},
+		{"8FWCX400+", true, false},
+		{"WC2345+G6g", true, true},
+		{"2345+G6", true, true},
+		{"45+G6", true, true},
+		{"+G6", true, true},
+		{"G+", false, false},
+		{"+", false, false},
+		{"8FWC2345+G", false, false},
+		{"8FWC2_45+G6", false, false},
+		{"8FWC2η45+G6", false, false},
+		{"8FWC2345+G6+", false, false},
+		{"8FWC2345G6+", false, false},
+		{"8FWC2300+G6", false, false},
+		{"WC2300+G6g", false, false},
+		{"WC2345+G", false, false},
+		{"WC2300+", false, false},
+		{"8F0C2300+", false, false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.code); got != tt.valid {
+			t.Errorf("Valid(%q) = %v, want %v", tt.code, got, tt.valid)
+		}
+		if got := IsShort(tt.code); got != tt.short {
+			t.Errorf("IsShort(%q) = %v, want %v", tt.code, got, tt.short)
+		}
+		if got := IsFull(tt.code); got != (tt.valid && !tt.short) {
+			t.Errorf("IsFull(%q) = %v", tt.code, got)
+		}
+	}
+
+	// the first digits cannot be outside the globe
+	if IsFull("XFWC2345+G6") || IsFull("8XWC2345+G6") {
+		t.Error("codes outside the globe are full")
+	}
+	if _, err := Decode("WC2345+G6"); !errors.Is(err, ErrNotFull) {
+		t.Errorf("Decode of a short code error = %v, want ErrNotFull", err)
+	}
+}
+
+func TestShortenAndRecover(t *testing.T) {
+	tests := []struct {
+		code     string
+		lat, lng float64
+		short    string
+	}{
+		{"9C3W9QCJ+2VX", 51.3701125, -1.217765625, "+2VX"},
+		{"9C3W9QCJ+2VX", 51.3708675, -1.217765625, "CJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.3701125, -1.217010625, "CJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.3852125, -1.217765625, "9QCJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.1851125, -1.217765625, "9QCJ+2VX"},
+		{"9C3W9QCJ+2VX", 53.3701125, -1.217765625, "9C3W9QCJ+2VX"},
+	}
+	for _, tt := range tests {
+		short, err := Shorten(tt.code, tt.lat, tt.lng)
+		if err != nil || short != tt.short {
+			t.Errorf("Shorten(%q, %v, %v) = %q, %v, want %q", tt.code, tt.lat, tt.lng, short, err, tt.short)
+		}
+		full, err := RecoverNearest(short, tt.lat, tt.lng)
+		if err != nil || full != tt.code {
+			t.Errorf("RecoverNearest(%q, %v, %v) = %q, %v, want %q", short, tt.lat, tt.lng, full, err, tt.code)
+		}
+	}
+}
+
+func TestRecoverNearest(t *testing.T) {
+	tests := []struct {
+		short    string
+		lat, lng float64
+		want     string
+	}{
+		// the nearest match is across the antimeridian or a cell boundary
+		{"2222+22", 1, 179.9, "62H22222+22"},
+		{"XXXX+XX", -81, -179.9, "2VCXXXXX+XX"},
+		{"CWC8+R9", 37.4, -122.1, "849VCWC8+R9"},
+		{"cwc8+r9", 37.4, -122.1, "849VCWC8+R9"},
+		// a full code is returned as is
+		{"849VCWC8+R9", 0, 0, "849VCWC8+R9"},
+	}
+	for _, tt := range tests {
+		got, err := RecoverNearest(tt.short, tt.lat, tt.lng)
+		if err != nil || got != tt.want {
+			t.Errorf("RecoverNearest(%q, %v, %v) = %q, %v, want %q", tt.short, tt.lat, tt.lng, got, err, tt.want)
+		}
+	}
+
+	if _, err := RecoverNearest("CWC8R9", 37.4, -122.1); !errors.Is(err, ErrInvalid) {
+		t.Errorf("RecoverNearest of an invalid code error = %v, want ErrInvalid", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for length := MinLength; length <= MaxLength; length++ {
+		for _, p := range [][2]float64{{51.5, -0.12}, {-33.86, 151.2}, {0, 0}, {89.99, 179.99}, {-89.99, -179.99}} {
+			code := Encode(p[0], p[1], length)
+			area, err := Decode(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p[0] < area.LatLo || p[0] > area.LatHi || p[1] < area.LngLo || p[1] > area.LngHi {
+				t.Errorf("%v not inside the area of %q, %+v", p, code, area)
+			}
+		}
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, code string) {
+		area, err := Decode(code)
+		if err != nil {
+			return
+		}
+		lat, lng := area.Center()
+		if !(math.Abs(lat) <= 90) || !(math.Abs(lng) <= 180) {
+			t.Fatalf("Decode(%q) has center %v, %v, out of range", code, lat, lng)
+		}
+		encoded := Encode(lat, lng, area.Len)
+		if again, err := Decode(encoded); err != nil || again != area {
+			t.Fatalf("Decode(Encode(Decode(%q))) = Decode(%q) = %+v, %v, want %+v", code, encoded, again, err, area)
+		}
+	})
+}
+
+func FuzzRecoverNearest(f *testing.F) {
+	f.Fuzz(func(t *testing.T, code string, lat, lng float64) {
+		if math.IsNaN(lat) || math.IsNaN(lng) || math.IsInf(lng, 0) {
+			return
+		}
+		full, err := RecoverNearest(code, lat, lng)
+		if err != nil {
+			return
+		}
+		area, err := Decode(full)
+		if err != nil {
+			t.Fatalf("RecoverNearest(%q, %v, %v) = %q, which does not decode: %v", code, lat, lng, full, err)
+		}
+		if centerLat, centerLng := area.Center(); !(math.Abs(centerLat) <= 90) || !(math.Abs(centerLng) <= 180) {
+			t.Fatalf("RecoverNearest(%q, %v, %v) = %q, centered out of range", code, lat, lng, full)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"io"
+	"slices"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/geohash"
+	"github.com/dickeyy/go-distances/olc"
+	"github.com/dickeyy/go-distances/utm"
+)
+
+// Column is an extra value written for every place of a result, such as its
+// UTM coordinate.
+type Column struct {
+	// Name is the key of the column in machine-readable formats.
+	Name string
+	// Title is the heading of the column in tables.
+	Title string
+	// Value returns the value of the column for a place, or "" when it has
+	// none.
+	Value func(point geo.Point) string
+}
+
+// columns holds the predefined columns in registration order.
+var columns = []Column{
+	{Name: "utm", Title: "UTM", Value: utmValue},
+	{Name: "mgrs", Title: "MGRS", Value: mgrsValue},
+	{Name: "geohash", Title: "Geohash", Value: geohashValue},
+	{Name: "pluscode", Title: "Plus Code", Value: plusCodeValue},
+}
+
+// LookupColumn returns the predefined column named name.
+func LookupColumn(name string) (Column, bool) {
+	i := slices.IndexFunc(columns, func(c Column) bool { return c.Name == name })
+	if i < 0 {
+		return Column{}, false
+	}
+	return columns[i], true
+}
+
+// ColumnNames returns the names of the predefined columns.
+func ColumnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// ColumnWriter is a Writer that can add extra columns for the places of
+// every leg. Every predefined format is a ColumnWriter.
+type ColumnWriter interface {
+	Writer
+	// WithColumns returns a Writer that also writes the given columns.
+	WithColumns(columns ...Column) Writer
+}
+
+// columnWriterFunc adapts a function that writes a result with extra
+// columns to the ColumnWriter interface.
+type columnWriterFunc func(w io.Writer, result *geo.Result, columns []Column) error
+
+// WriteResult calls f(w, result, nil).
+func (f columnWriterFunc) WriteResult(w io.Writer, result *geo.Result) error {
+	return f(w, result, nil)
+}
+
+// WithColumns returns a Writer that calls f(w, result, columns).
+func (f columnWriterFunc) WithColumns(columns ...Column) Writer {
+	return WriterFunc(func(w io.Writer, result *geo.Result) error {
+		return f(w, result, columns)
+	})
+}
+
+// columnValues returns the values of columns for point.
+func columnValues(columns []Column, point geo.Point) []string {
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		values[i] = c.Value(point)
+	}
+	return values
+}
+
+func utmValue(point geo.Point) string {
+	c, err := utm.FromLatLon(point.Lat, point.Lon)
+	if err != nil {
+		return ""
+	}
+	return c.String()
+}
+
+func mgrsValue(point geo.Point) string {
+	reference, err := utm.MGRSFromLatLon(point.Lat, point.Lon, utm.MaxPrecision)
+	if err != nil {
+		return ""
+	}
+	return reference
+}
+
+func geohashValue(point geo.Point) string {
+	// nine characters name a cell about 5 m across
+	return geohash.Encode(point.Lat, point.Lon, 9)
+}
+
+func plusCodeValue(point geo.Point) string {
+	return olc.Encode(point.Lat, point.Lon, olc.DefaultLength)
+}
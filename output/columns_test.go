@@ -0,0 +1,97 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+func testColumns(t *testing.T, names ...string) []Column {
+	t.Helper()
+	var columns []Column
+	for _, name := range names {
+		c, ok := LookupColumn(name)
+		if !ok {
+			t.Fatalf("LookupColumn(%q) failed", name)
+		}
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+func TestColumnValues(t *testing.T) {
+	columns := testColumns(t, ColumnNames()...)
+	got := columnValues(columns, geo.Point{Lat: 40.7128, Lon: -74.006})
+	want := []string{"18T 583959 4507351", "18TWL8395907350", "dr5regw3p", "87G7PX7V+4J"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, ok := LookupColumn("w3w"); ok {
+		t.Error(`LookupColumn("w3w") succeeded`)
+	}
+}
+
+func TestWriteWithColumns(t *testing.T) {
+	format, _ := Lookup("text")
+	writer := format.Writer.(ColumnWriter).WithColumns(testColumns(t, "mgrs")...)
+	var b strings.Builder
+	if err := writer.WriteResult(&b, testResult); err != nil {
+		t.Fatal(err)
+	}
+	want := `Circular distances using haversine formula:
+
+Leg  From      MGRS             To        MGRS             Distance (km)
+  1  New York  18TWL8395907350  Point 2   11SLT8521368641           3936
+  2  Point 2   11SLT8521368641  New York  18TWL8395907350           3936
+     Total                                                          7872
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteCSVWithColumns(t *testing.T) {
+	var b strings.Builder
+	if err := writeCSV(&b, testResult, testColumns(t, "utm")); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if lines[0] != "formula,unit,leg,from_index,from_name,from_lat,from_lon,from_utm,to_index,to_name,to_lat,to_lon,to_utm,distance" {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if lines[1] != "haversine,km,1,1,New York,40.7128,-74.006,18T 583959 4507351,2,,34.0522,-118.2437,11S 385214 3768641,3935.75" {
+		t.Errorf("Unexpected record: %s", lines[1])
+	}
+}
+
+func TestWriteJSONWithColumns(t *testing.T) {
+	var b strings.Builder
+	if err := writeNDJSON(&b, testResult, testColumns(t, "geohash", "pluscode")); err != nil {
+		t.Fatal(err)
+	}
+	var leg struct {
+		From map[string]any `json:"from"`
+	}
+	if err := json.Unmarshal([]byte(strings.Split(b.String(), "\n")[0]), &leg); err != nil {
+		t.Fatal(err)
+	}
+	if leg.From["geohash"] != "dr5regw3p" || leg.From["pluscode"] != "87G7PX7V+4J" || leg.From["name"] != "New York" {
+		t.Errorf("Unexpected place: %v", leg.From)
+	}
+}
+
+func TestWriteMarkdownWithColumns(t *testing.T) {
+	var b strings.Builder
+	if err := writeMarkdown(&b, testResult, testColumns(t, "geohash")); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if lines[2] != "| Leg | From | Geohash | To | Geohash | Distance (km) |" ||
+		lines[3] != "| ---: | --- | --- | --- | --- | ---: |" ||
+		lines[4] != "| 1 | New York | dr5regw3p | Point 2 | 9q5ctr186 | 3936 |" ||
+		lines[6] != "| | **Total** | | | | **7872** |" {
+		t.Errorf("Unexpected table:\n%s", b.String())
+	}
+}
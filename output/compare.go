@@ -0,0 +1,64 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// WriteComparison writes a formula comparison as an aligned plain-text
+// table: the distance of every leg according to each formula, followed by
+// the absolute and relative difference of every other formula from the
+// reference. Legs where the formulas disagree are flagged with "!".
+func WriteComparison(w io.Writer, comparison *geo.Comparison) error {
+	reference := comparison.ReferenceIndex()
+
+	header := []string{"Leg", "From", "To"}
+	rightAlign := []bool{true, false, false}
+	for i, name := range comparison.Formulas {
+		if i == reference {
+			name += " (ref)"
+		}
+		header = append(header, name)
+		rightAlign = append(rightAlign, true)
+	}
+	for i, name := range comparison.Formulas {
+		if i != reference {
+			header = append(header, "Δ "+name, "Δ% "+name)
+			rightAlign = append(rightAlign, true, true)
+		}
+	}
+	header = append(header, "")
+	rightAlign = append(rightAlign, false)
+
+	rows := [][]string{header}
+	disagreements := 0
+	for i, leg := range comparison.Legs {
+		row := []string{strconv.Itoa(i + 1), placeLabel(leg.FromIndex, leg.From), placeLabel(leg.ToIndex, leg.To)}
+		for _, distance := range leg.Distances {
+			row = append(row, strconv.FormatFloat(distance, 'f', 3, 64))
+		}
+		for j := range comparison.Formulas {
+			if j != reference {
+				absolute, relative := comparison.Difference(leg, j)
+				row = append(row, strconv.FormatFloat(absolute, 'g', 3, 64), strconv.FormatFloat(relative*100, 'g', 3, 64))
+			}
+		}
+		flag := ""
+		if leg.Disagrees {
+			flag = "!"
+			disagreements++
+		}
+		rows = append(rows, append(row, flag))
+	}
+
+	fmt.Fprintf(w, "Formula comparison against %s (distances in %s):\n\n", comparison.Reference, comparison.Body.UnitName())
+	io.WriteString(w, alignColumns(rows, rightAlign))
+	_, err := fmt.Fprintf(w, "\n%d of %d legs differ from %s by more than %s%%.\n",
+		disagreements, len(comparison.Legs), comparison.Reference, strconv.FormatFloat(comparison.Threshold*100, 'g', 3, 64))
+	return err
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+func TestWriteComparison(t *testing.T) {
+	comparison := &geo.Comparison{
+		Body:      geo.Earth,
+		Formulas:  []string{"haversine", "sloc"},
+		Reference: "haversine",
+		Threshold: 0.001,
+		Legs: []geo.ComparedLeg{
+			{FromIndex: 0, ToIndex: 1, From: geo.Point{Name: "A"}, To: geo.Point{Name: "B"}, Distances: []float64{100, 100}},
+			{FromIndex: 1, ToIndex: 0, From: geo.Point{Name: "B"}, To: geo.Point{Name: "A"}, Distances: []float64{100, 101}, Disagrees: true},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteComparison(&b, comparison); err != nil {
+		t.Fatal(err)
+	}
+	want := `Formula comparison against haversine (distances in km):
+
+Leg  From  To  haversine (ref)     sloc  Δ sloc  Δ% sloc
+  1  A     B           100.000  100.000       0        0
+  2  B     A           100.000  101.000       1        1  !
+
+1 of 2 legs differ from haversine by more than 0.1%.
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
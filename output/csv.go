@@ -0,0 +1,105 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+var csvHeader = []string{
+	"formula", "unit", "leg",
+	"from_index", "from_name", "from_lat", "from_lon",
+	"to_index", "to_name", "to_lat", "to_lon",
+	"distance",
+}
+
+// writeCSV writes the result as CSV with a header row and one row per leg.
+// Indexes are one-based. Extra columns are named from_<name> and to_<name>
+// and follow the coordinates of the place they describe. Results with
+// elevations also have from_elevation and to_elevation columns and a slant
+// column after the distance. Results with times have from_time and to_time
+// columns, and duration (in seconds), speed (in units per hour) and pace (in
+// seconds per unit) columns that are empty for legs without a duration.
+func writeCSV(w io.Writer, result *geo.Result, columns []Column) error {
+	elevation, timed := result.HasElevation(), result.HasTime()
+	columns = withElevation(result, columns, -1)
+	if timed {
+		columns = append([]Column{timeColumn}, columns...)
+	}
+	writer := csv.NewWriter(w)
+	header := slices.Clone(csvHeader[:7])
+	for _, c := range columns {
+		header = append(header, "from_"+c.Name)
+	}
+	header = append(header, csvHeader[7:11]...)
+	for _, c := range columns {
+		header = append(header, "to_"+c.Name)
+	}
+	header = append(header, csvHeader[11:]...)
+	if elevation {
+		header = append(header, "slant")
+	}
+	if timed {
+		header = append(header, "duration", "speed", "pace")
+	}
+	writer.Write(header)
+
+	for i, leg := range result.Legs {
+		record := []string{
+			result.Formula, result.Body.UnitName(), strconv.Itoa(i + 1),
+			strconv.Itoa(leg.FromIndex + 1), leg.From.Name, formatFloat(leg.From.Lat), formatFloat(leg.From.Lon),
+		}
+		record = append(record, columnValues(columns, leg.From)...)
+		record = append(record, strconv.Itoa(leg.ToIndex+1), leg.To.Name, formatFloat(leg.To.Lat), formatFloat(leg.To.Lon))
+		record = append(record, columnValues(columns, leg.To)...)
+		record = append(record, formatFloat(leg.Distance))
+		if elevation {
+			record = append(record, formatFloat(leg.Slant))
+		}
+		if timed {
+			record = append(record, csvTimes(leg)...)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// timeColumn is the time of every place in RFC 3339 format.
+var timeColumn = Column{
+	Name:  "time",
+	Title: "Time",
+	Value: func(point geo.Point) string {
+		if point.Time == nil {
+			return ""
+		}
+		return point.Time.Format(time.RFC3339)
+	},
+}
+
+// csvTimes returns the duration, speed and pace of leg, or empty values when
+// its duration is unknown.
+func csvTimes(leg geo.Leg) []string {
+	values := make([]string, 3)
+	if d, ok := leg.Duration(); ok {
+		values[0] = formatFloat(d.Seconds())
+	}
+	if speed, ok := leg.Speed(); ok {
+		values[1] = formatFloat(speed)
+	}
+	if pace, ok := leg.Pace(); ok {
+		values[2] = formatFloat(pace.Seconds())
+	}
+	return values
+}
+
+// formatFloat formats f with the fewest digits that represent it exactly.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,20 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	var b strings.Builder
+	if err := writeCSV(&b, testResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `formula,unit,leg,from_index,from_name,from_lat,from_lon,to_index,to_name,to_lat,to_lon,distance
+haversine,km,1,1,New York,40.7128,-74.006,2,,34.0522,-118.2437,3935.75
+haversine,km,2,2,,34.0522,-118.2437,1,New York,40.7128,-74.006,3935.75
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,34 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"strconv"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// elevationColumn returns a column with the elevation of every place,
+// formatted with the given number of decimals, or with the fewest digits
+// that represent it exactly when decimals is negative.
+func elevationColumn(result *geo.Result, decimals int) Column {
+	return Column{
+		Name:  "elevation",
+		Title: "Elevation (" + result.Body.UnitName() + ")",
+		Value: func(point geo.Point) string {
+			if point.Elevation == nil {
+				return ""
+			}
+			return strconv.FormatFloat(*point.Elevation, 'f', decimals, 64)
+		},
+	}
+}
+
+// withElevation prepends the elevation column to columns when the result
+// has elevations.
+func withElevation(result *geo.Result, columns []Column, decimals int) []Column {
+	if !result.HasElevation() {
+		return columns
+	}
+	return append([]Column{elevationColumn(result, decimals)}, columns...)
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// elevationResult is a short out-and-back climb, with elevations in
+// kilometres.
+var elevationResult = func() *geo.Result {
+	low, high := 0.1, 0.5
+	a := geo.Point{Name: "Base", Lat: 46.5, Lon: 8, Elevation: &low}
+	b := geo.Point{Name: "Summit", Lat: 46.52, Lon: 8.02, Elevation: &high}
+	slant := math.Hypot(3, 0.4)
+	return &geo.Result{
+		Formula: "haversine",
+		Body:    geo.Earth,
+		Legs: []geo.Leg{
+			{FromIndex: 0, ToIndex: 1, From: a, To: b, Distance: 3, Slant: slant},
+			{FromIndex: 1, ToIndex: 0, From: b, To: a, Distance: 3, Slant: slant},
+		},
+		Total:      6,
+		SlantTotal: 2 * slant,
+		Ascent:     0.4,
+		Descent:    0.4,
+	}
+}()
+
+func TestWriteTextElevation(t *testing.T) {
+	var b strings.Builder
+	if err := writeText(&b, elevationResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `Circular distances using haversine formula:
+
+Leg  From    Elevation (km)  To      Elevation (km)  Distance (km)  Slant (km)
+  1  Base             0.100  Summit           0.500          3.000       3.027
+  2  Summit           0.500  Base             0.100          3.000       3.027
+     Total                                                   6.000       6.053
+
+Ascent: 0.400 km, descent: 0.400 km
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteMarkdownElevation(t *testing.T) {
+	var b strings.Builder
+	if err := writeMarkdown(&b, elevationResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if lines[2] != "| Leg | From | Elevation (km) | To | Elevation (km) | Distance (km) | Slant (km) |" ||
+		lines[3] != "| ---: | --- | --- | --- | --- | ---: | ---: |" ||
+		lines[4] != "| 1 | Base | 0.100 | Summit | 0.500 | 3.000 | 3.027 |" ||
+		lines[6] != "| | **Total** | | | | **6.000** | **6.053** |" ||
+		lines[8] != "Ascent: 0.400 km, descent: 0.400 km." {
+		t.Errorf("Unexpected table:\n%s", b.String())
+	}
+}
+
+func TestWriteCSVElevation(t *testing.T) {
+	var b strings.Builder
+	if err := writeCSV(&b, elevationResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if lines[0] != "formula,unit,leg,from_index,from_name,from_lat,from_lon,from_elevation,to_index,to_name,to_lat,to_lon,to_elevation,distance,slant" {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "haversine,km,1,1,Base,46.5,8,0.1,2,Summit,46.52,8.02,0.5,3,3.026") {
+		t.Errorf("Unexpected record: %s", lines[1])
+	}
+}
+
+func TestWriteJSONElevation(t *testing.T) {
+	var b strings.Builder
+	if err := writeJSON(&b, elevationResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Legs []struct {
+			From  map[string]any `json:"from"`
+			Slant float64        `json:"slant"`
+		} `json:"legs"`
+		SlantTotal float64 `json:"slantTotal"`
+		Ascent     float64 `json:"ascent"`
+		Descent    float64 `json:"descent"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Legs[0].From["elevation"] != 0.1 || doc.Legs[0].Slant != elevationResult.Legs[0].Slant {
+		t.Errorf("Unexpected leg: %+v", doc.Legs[0])
+	}
+	if doc.SlantTotal != elevationResult.SlantTotal || doc.Ascent != 0.4 || doc.Descent != 0.4 {
+		t.Errorf("Unexpected totals: %+v", doc)
+	}
+
+	b.Reset()
+	if err := writeJSON(&b, testResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range []string{"elevation", "slant", "ascent", "descent"} {
+		if strings.Contains(b.String(), `"`+member) {
+			t.Errorf("Result without elevations has %q: %s", member, b.String())
+		}
+	}
+}
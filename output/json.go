@@ -0,0 +1,175 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// jsonPlace is a place as written by the JSON and NDJSON formats. Index is
+// the one-based position of the place in the input.
+type jsonPlace struct {
+	Index int     `json:"index"`
+	Name  string  `json:"name,omitempty"`
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+	// Elevation is omitted when it is unknown.
+	Elevation *float64 `json:"elevation,omitempty"`
+	// Time is omitted when it is unknown.
+	Time *time.Time `json:"time,omitempty"`
+	// columns holds the values of extra columns, written as further members
+	// named after their column.
+	columns []Column
+	values  []string
+}
+
+// MarshalJSON writes the place with its extra columns after the coordinates.
+func (p jsonPlace) MarshalJSON() ([]byte, error) {
+	type plain jsonPlace
+	b, err := json.Marshal(plain(p))
+	if err != nil || len(p.columns) == 0 {
+		return b, err
+	}
+	b = b[:len(b)-1]
+	for i, c := range p.columns {
+		name, _ := json.Marshal(c.Name)
+		value, _ := json.Marshal(p.values[i])
+		b = append(append(append(append(b, ','), name...), ':'), value...)
+	}
+	return append(b, '}'), nil
+}
+
+func newJSONPlace(index int, point geo.Point, columns []Column) jsonPlace {
+	return jsonPlace{
+		Index:     index + 1,
+		Name:      point.Name,
+		Lat:       point.Lat,
+		Lon:       point.Lon,
+		Elevation: point.Elevation,
+		Time:      point.Time,
+		columns:   columns,
+		values:    columnValues(columns, point),
+	}
+}
+
+// jsonLeg is a leg as written by the JSON format. Leg is one-based.
+type jsonLeg struct {
+	Leg      int       `json:"leg"`
+	From     jsonPlace `json:"from"`
+	To       jsonPlace `json:"to"`
+	Distance float64   `json:"distance"`
+	// Slant is only written for results with elevations.
+	Slant *float64 `json:"slant,omitempty"`
+	// Duration (in seconds), Speed (in units per hour) and Pace (in seconds
+	// per unit) are only written when they are known.
+	Duration *float64 `json:"duration,omitempty"`
+	Speed    *float64 `json:"speed,omitempty"`
+	Pace     *float64 `json:"pace,omitempty"`
+}
+
+type jsonResult struct {
+	Formula string    `json:"formula"`
+	Body    string    `json:"body"`
+	Radius  float64   `json:"radius"`
+	Unit    string    `json:"unit"`
+	Legs    []jsonLeg `json:"legs"`
+	Total   float64   `json:"total"`
+	// SlantTotal, Ascent and Descent are only written for results with
+	// elevations.
+	SlantTotal *float64 `json:"slantTotal,omitempty"`
+	Ascent     *float64 `json:"ascent,omitempty"`
+	Descent    *float64 `json:"descent,omitempty"`
+	// Duration and MovingTime (in seconds), MovingSpeed and MaxSpeed (in
+	// units per hour) are only written for results with times.
+	Duration    *float64 `json:"duration,omitempty"`
+	MovingTime  *float64 `json:"movingTime,omitempty"`
+	MovingSpeed *float64 `json:"movingSpeed,omitempty"`
+	MaxSpeed    *float64 `json:"maxSpeed,omitempty"`
+}
+
+// ndjsonLeg is a self-contained leg as written by the NDJSON format.
+type ndjsonLeg struct {
+	Formula string `json:"formula"`
+	Unit    string `json:"unit"`
+	jsonLeg
+}
+
+func newJSONLeg(i int, leg geo.Leg, columns []Column, elevation bool) jsonLeg {
+	l := jsonLeg{
+		Leg:      i + 1,
+		From:     newJSONPlace(leg.FromIndex, leg.From, columns),
+		To:       newJSONPlace(leg.ToIndex, leg.To, columns),
+		Distance: leg.Distance,
+	}
+	if elevation {
+		l.Slant = &leg.Slant
+	}
+	if d, ok := leg.Duration(); ok {
+		l.Duration = jsonNumber(d.Seconds())
+	}
+	if speed, ok := leg.Speed(); ok {
+		l.Speed = &speed
+	}
+	if pace, ok := leg.Pace(); ok {
+		l.Pace = jsonNumber(pace.Seconds())
+	}
+	return l
+}
+
+// jsonNumber returns a pointer to f, for optional members.
+func jsonNumber(f float64) *float64 {
+	return &f
+}
+
+// writeJSON writes the result as a single indented JSON document. Extra
+// columns are written as members of the places. Results with elevations
+// also have the elevations of the places, the slant distances of the legs
+// and the total slant distance, ascent and descent, and results with times
+// the times of the places, the duration, speed and pace of the legs and the
+// elapsed and moving time and speeds.
+func writeJSON(w io.Writer, result *geo.Result, columns []Column) error {
+	elevation := result.HasElevation()
+	doc := jsonResult{
+		Formula: result.Formula,
+		Body:    result.Body.Name,
+		Radius:  result.Body.Radius,
+		Unit:    result.Body.UnitName(),
+		Legs:    make([]jsonLeg, len(result.Legs)),
+		Total:   result.Total,
+	}
+	for i, leg := range result.Legs {
+		doc.Legs[i] = newJSONLeg(i, leg, columns, elevation)
+	}
+	if elevation {
+		doc.SlantTotal, doc.Ascent, doc.Descent = &result.SlantTotal, &result.Ascent, &result.Descent
+	}
+	if result.HasTime() {
+		doc.Duration = jsonNumber(result.Duration.Seconds())
+		doc.MovingTime = jsonNumber(result.MovingTime.Seconds())
+		doc.MaxSpeed = &result.MaxSpeed
+		if speed, ok := result.MovingSpeed(); ok {
+			doc.MovingSpeed = &speed
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// writeNDJSON writes one JSON object per line for every leg of the result.
+func writeNDJSON(w io.Writer, result *geo.Result, columns []Column) error {
+	elevation := result.HasElevation()
+	encoder := json.NewEncoder(w)
+	for i, leg := range result.Legs {
+		line := ndjsonLeg{Formula: result.Formula, Unit: result.Body.UnitName(), jsonLeg: newJSONLeg(i, leg, columns, elevation)}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var b strings.Builder
+	if err := writeJSON(&b, testResult, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc jsonResult
+	if err := json.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Formula != "haversine" || doc.Unit != "km" || doc.Radius != 6371 || doc.Total != 7871.5 {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+	if len(doc.Legs) != 2 || doc.Legs[0].From.Name != "New York" || doc.Legs[0].To.Index != 2 || doc.Legs[1].Leg != 2 {
+		t.Errorf("Unexpected legs: %+v", doc.Legs)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var b strings.Builder
+	if err := writeNDJSON(&b, testResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"formula":"haversine","unit":"km","leg":1,"from":{"index":1,"name":"New York","lat":40.7128,"lon":-74.006},"to":{"index":2,"lat":34.0522,"lon":-118.2437},"distance":3935.75}
+{"formula":"haversine","unit":"km","leg":2,"from":{"index":2,"lat":34.0522,"lon":-118.2437},"to":{"index":1,"name":"New York","lat":40.7128,"lon":-74.006},"distance":3935.75}
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
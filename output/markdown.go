@@ -0,0 +1,77 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// markdownEscaper escapes characters that would break a Markdown table cell.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// writeMarkdown writes the result as a Markdown table, with distances
+// rounded to whole units. Extra columns follow the place they describe.
+// Results with elevations or times have the same extra columns and summary
+// as the text format.
+func writeMarkdown(w io.Writer, result *geo.Result, columns []Column) error {
+	decimals := tableDecimals(result)
+	columns = withElevation(result, columns, decimals)
+	legs := legColumns(result, decimals)
+
+	var titles, alignments, blanks strings.Builder
+	for _, c := range columns {
+		titles.WriteString(" " + markdownEscaper.Replace(c.Title) + " |")
+		alignments.WriteString(" --- |")
+		blanks.WriteString(" |")
+	}
+	var legTitles, legAlignments, totals strings.Builder
+	for _, c := range legs {
+		legTitles.WriteString(" " + markdownEscaper.Replace(c.title) + " |")
+		legAlignments.WriteString(" ---: |")
+		if c.total == "" {
+			totals.WriteString(" |")
+		} else {
+			totals.WriteString(" **" + c.total + "** |")
+		}
+	}
+
+	fmt.Fprintf(w, "Circular distances using the **%s** formula.\n\n", result.Formula)
+	fmt.Fprintf(w, "| Leg | From |%s To |%s%s\n", titles.String(), titles.String(), legTitles.String())
+	fmt.Fprintf(w, "| ---: | --- |%s --- |%s%s\n", alignments.String(), alignments.String(), legAlignments.String())
+	for i, leg := range result.Legs {
+		var values strings.Builder
+		for _, c := range legs {
+			if value := c.value(leg); value == "" {
+				values.WriteString(" |")
+			} else {
+				values.WriteString(" " + value + " |")
+			}
+		}
+		fmt.Fprintf(w, "| %d | %s |%s %s |%s%s\n", i+1,
+			markdownEscaper.Replace(placeLabel(leg.FromIndex, leg.From)), markdownCells(columns, leg.From),
+			markdownEscaper.Replace(placeLabel(leg.ToIndex, leg.To)), markdownCells(columns, leg.To),
+			values.String())
+	}
+	_, err := fmt.Fprintf(w, "| | **Total** |%s |%s%s\n", blanks.String(), blanks.String(), totals.String())
+	for _, line := range summaryLines(result, decimals) {
+		if err != nil {
+			break
+		}
+		_, err = fmt.Fprintf(w, "\n%s.\n", line)
+	}
+	return err
+}
+
+// markdownCells returns the cells of columns for point, each followed by a
+// separator.
+func markdownCells(columns []Column, point geo.Point) string {
+	var b strings.Builder
+	for _, value := range columnValues(columns, point) {
+		b.WriteString(" " + markdownEscaper.Replace(value) + " |")
+	}
+	return b.String()
+}
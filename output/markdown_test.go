@@ -0,0 +1,41 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	var b strings.Builder
+	if err := writeMarkdown(&b, testResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `Circular distances using the **haversine** formula.
+
+| Leg | From | To | Distance (km) |
+| ---: | --- | --- | ---: |
+| 1 | New York | Point 2 | 3936 |
+| 2 | Point 2 | New York | 3936 |
+| | **Total** | | **7872** |
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteMarkdownEscapesNames(t *testing.T) {
+	result := &geo.Result{
+		Formula: "sloc",
+		Body:    geo.Earth.WithRadius(1, ""),
+		Legs:    []geo.Leg{{From: geo.Point{Name: "A|B"}, To: geo.Point{Name: "C"}}},
+	}
+	var b strings.Builder
+	if err := writeMarkdown(&b, result, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `| 1 | A\|B | C | 0 |`) || !strings.Contains(b.String(), "Distance (units)") {
+		t.Errorf("got:\n%s", b.String())
+	}
+}
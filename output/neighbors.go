@@ -0,0 +1,34 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/spatial"
+)
+
+// WriteNeighbors writes the result of a spatial query as an aligned
+// plain-text table, nearest first. Distances are in the unit of body.
+func WriteNeighbors(w io.Writer, neighbors []spatial.Neighbor, body geo.Body) error {
+	if len(neighbors) == 0 {
+		_, err := fmt.Fprintln(w, "No places found.")
+		return err
+	}
+
+	rows := [][]string{{"Rank", "Place", "Latitude", "Longitude", "Distance (" + body.UnitName() + ")"}}
+	for i, neighbor := range neighbors {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			placeLabel(neighbor.Index, neighbor.Point),
+			formatFloat(neighbor.Point.Lat),
+			formatFloat(neighbor.Point.Lon),
+			strconv.FormatFloat(neighbor.Distance, 'f', 3, 64),
+		})
+	}
+	_, err := io.WriteString(w, alignColumns(rows, []bool{true, false, true, true, true}))
+	return err
+}
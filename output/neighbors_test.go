@@ -0,0 +1,37 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/spatial"
+)
+
+func TestWriteNeighbors(t *testing.T) {
+	neighbors := []spatial.Neighbor{
+		{Index: 4, Point: geo.Point{Name: "Depot", Lat: 40.5, Lon: -74}, Distance: 1.5},
+		{Index: 0, Point: geo.Point{Lat: 41, Lon: -73.25}, Distance: 12.25},
+	}
+	var b strings.Builder
+	if err := WriteNeighbors(&b, neighbors, geo.Earth); err != nil {
+		t.Fatal(err)
+	}
+	want := `Rank  Place    Latitude  Longitude  Distance (km)
+   1  Depot        40.5        -74          1.500
+   2  Point 1        41     -73.25         12.250
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteNeighborsEmpty(t *testing.T) {
+	var b strings.Builder
+	if err := WriteNeighbors(&b, nil, geo.Earth); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "No places found.\n" {
+		t.Errorf("got %q", b.String())
+	}
+}
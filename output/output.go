@@ -0,0 +1,77 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// Writer renders a route result to w.
+type Writer interface {
+	WriteResult(w io.Writer, result *geo.Result) error
+}
+
+// WriterFunc adapts an ordinary function to the Writer interface.
+type WriterFunc func(w io.Writer, result *geo.Result) error
+
+// WriteResult calls f(w, result).
+func (f WriterFunc) WriteResult(w io.Writer, result *geo.Result) error {
+	return f(w, result)
+}
+
+// Format is a Writer registered under a name.
+type Format struct {
+	Name   string
+	Writer Writer
+}
+
+// registry holds the registered formats in registration order.
+var registry = []Format{
+	{Name: "text", Writer: columnWriterFunc(writeText)},
+	{Name: "json", Writer: columnWriterFunc(writeJSON)},
+	{Name: "ndjson", Writer: columnWriterFunc(writeNDJSON)},
+	{Name: "csv", Writer: columnWriterFunc(writeCSV)},
+	{Name: "markdown", Writer: columnWriterFunc(writeMarkdown)},
+}
+
+// Register adds a format, replacing any format already registered under the
+// same name. It is meant to be called from init functions.
+func Register(format Format) {
+	i := slices.IndexFunc(registry, func(f Format) bool { return f.Name == format.Name })
+	if i >= 0 {
+		registry[i] = format
+		return
+	}
+	registry = append(registry, format)
+}
+
+// Lookup returns the format registered under name.
+func Lookup(name string) (Format, bool) {
+	i := slices.IndexFunc(registry, func(f Format) bool { return f.Name == name })
+	if i < 0 {
+		return Format{}, false
+	}
+	return registry[i], true
+}
+
+// Names returns the names of every registered format in registration order.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, f := range registry {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// placeLabel returns the name of the place at the zero-based index, or a
+// numbered stand-in when it has none.
+func placeLabel(index int, point geo.Point) string {
+	if point.Name != "" {
+		return point.Name
+	}
+	return fmt.Sprintf("Point %d", index+1)
+}
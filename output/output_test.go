@@ -0,0 +1,41 @@
+package output
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// testResult is a small route with one unnamed place.
+var testResult = &geo.Result{
+	Formula: "haversine",
+	Body:    geo.Earth,
+	Legs: []geo.Leg{
+		{FromIndex: 0, ToIndex: 1, From: geo.Point{Name: "New York", Lat: 40.7128, Lon: -74.006}, To: geo.Point{Lat: 34.0522, Lon: -118.2437}, Distance: 3935.75},
+		{FromIndex: 1, ToIndex: 0, From: geo.Point{Lat: 34.0522, Lon: -118.2437}, To: geo.Point{Name: "New York", Lat: 40.7128, Lon: -74.006}, Distance: 3935.75},
+	},
+	Total: 7871.5,
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"text", "json", "ndjson", "csv", "markdown"} {
+		if format, ok := Lookup(name); !ok || format.Writer == nil {
+			t.Errorf("Lookup(%q) = %+v, %t", name, format, ok)
+		}
+	}
+	if _, ok := Lookup("xml"); ok {
+		t.Errorf("Lookup(\"xml\") succeeded")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	saved := slices.Clone(registry)
+	defer func() { registry = saved }()
+
+	Register(Format{Name: "none", Writer: WriterFunc(func(w io.Writer, result *geo.Result) error { return nil })})
+	if got := Names(); got[len(got)-1] != "none" {
+		t.Fatalf("got %v, want none last", got)
+	}
+}
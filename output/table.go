@@ -0,0 +1,129 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// legColumn is a numeric column of the text and Markdown tables, with a
+// value for every leg and, optionally, a total.
+type legColumn struct {
+	title string
+	value func(leg geo.Leg) string
+	total string
+}
+
+// legColumns returns the numeric columns of the text and Markdown tables:
+// the distance, followed by the slant distance for results with elevations
+// and the duration, speed and pace for results with times.
+func legColumns(result *geo.Result, decimals int) []legColumn {
+	unit := result.Body.UnitName()
+	columns := []legColumn{{
+		title: "Distance (" + unit + ")",
+		value: func(leg geo.Leg) string { return formatDistance(leg.Distance, decimals) },
+		total: formatDistance(result.Total, decimals),
+	}}
+	if result.HasElevation() {
+		columns = append(columns, legColumn{
+			title: "Slant (" + unit + ")",
+			value: func(leg geo.Leg) string { return formatDistance(leg.Slant, decimals) },
+			total: formatDistance(result.SlantTotal, decimals),
+		})
+	}
+	if result.HasTime() {
+		columns = append(columns,
+			legColumn{
+				title: "Duration",
+				value: func(leg geo.Leg) string {
+					d, ok := leg.Duration()
+					return formatKnown(formatDuration(d), ok)
+				},
+				total: formatDuration(result.Duration),
+			},
+			legColumn{
+				title: "Speed (" + unit + "/h)",
+				value: func(leg geo.Leg) string {
+					speed, ok := leg.Speed()
+					return formatKnown(formatSpeed(speed), ok)
+				},
+			},
+			legColumn{
+				title: "Pace (min/" + unit + ")",
+				value: func(leg geo.Leg) string {
+					pace, ok := leg.Pace()
+					return formatKnown(formatPace(pace), ok)
+				},
+			},
+		)
+	}
+	return columns
+}
+
+// summaryLines returns the lines written under the text and Markdown tables:
+// the ascent and descent for results with elevations, and the elapsed and
+// moving time and speeds for results with times.
+func summaryLines(result *geo.Result, decimals int) []string {
+	unit := result.Body.UnitName()
+	var lines []string
+	if result.HasElevation() {
+		lines = append(lines, fmt.Sprintf("Ascent: %s %s, descent: %s %s",
+			formatDistance(result.Ascent, decimals), unit, formatDistance(result.Descent, decimals), unit))
+	}
+	if result.HasTime() {
+		line := fmt.Sprintf("Elapsed time: %s, moving time: %s", formatDuration(result.Duration), formatDuration(result.MovingTime))
+		if speed, ok := result.MovingSpeed(); ok {
+			line += fmt.Sprintf(", moving speed: %s %s/h", formatSpeed(speed), unit)
+		}
+		lines = append(lines, line+fmt.Sprintf(", max speed: %s %s/h", formatSpeed(result.MaxSpeed), unit))
+	}
+	return lines
+}
+
+// tableDecimals returns the number of decimals distances are rounded to in
+// tables: whole units, unless the result has elevations or times, which are
+// mostly recorded along tracks with legs much shorter than a unit.
+func tableDecimals(result *geo.Result) int {
+	if result.HasElevation() || result.HasTime() {
+		return 3
+	}
+	return 0
+}
+
+// formatDistance rounds a distance to the given number of decimals.
+func formatDistance(distance float64, decimals int) string {
+	if decimals == 0 {
+		return strconv.Itoa(int(math.Round(distance)))
+	}
+	return strconv.FormatFloat(distance, 'f', decimals, 64)
+}
+
+// formatSpeed rounds a speed to one decimal.
+func formatSpeed(speed float64) string {
+	return strconv.FormatFloat(speed, 'f', 1, 64)
+}
+
+// formatDuration formats d as hours, minutes and seconds, like "1:05:09".
+func formatDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// formatPace formats a time per unit as minutes and seconds, like "5:42".
+func formatPace(pace time.Duration) string {
+	seconds := int64(pace.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// formatKnown returns s, or "" when the value it formats is unknown.
+func formatKnown(s string, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return s
+}
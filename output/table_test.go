@@ -0,0 +1,133 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// timedResult is a short walk with a ten-minute stop, whose closing leg goes
+// back in time.
+var timedResult = func() *geo.Result {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		t := start.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+	path := geo.Path{
+		{Name: "Gate", Lat: 0, Lon: 0, Time: at(0)},
+		{Name: "Cafe", Lat: 0.01, Lon: 0, Time: at(10)},
+		{Name: "Cafe", Lat: 0.01, Lon: 0, Time: at(20)},
+	}
+	calculator, _ := geo.NewCalculator("haversine", geo.Earth)
+	result, _ := calculator.Circular(path)
+	return result
+}()
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00:00"},
+		{65*time.Minute + 9400*time.Millisecond, "1:05:09"},
+		{27 * time.Hour, "27:00:00"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+	if got := formatPace(5*time.Minute + 42*time.Second); got != "5:42" {
+		t.Errorf("formatPace = %q, want 5:42", got)
+	}
+}
+
+func TestWriteTextTime(t *testing.T) {
+	var b strings.Builder
+	if err := writeText(&b, timedResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `Circular distances using haversine formula:
+
+Leg  From   To    Distance (km)  Duration  Speed (km/h)  Pace (min/km)
+  1  Gate   Cafe          1.112   0:10:00           6.7           9:00
+  2  Cafe   Cafe          0.000   0:10:00           0.0
+  3  Cafe   Gate          1.112
+     Total                2.224   0:20:00
+
+Elapsed time: 0:20:00, moving time: 0:10:00, moving speed: 6.7 km/h, max speed: 6.7 km/h
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteMarkdownTime(t *testing.T) {
+	var b strings.Builder
+	if err := writeMarkdown(&b, timedResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if lines[2] != "| Leg | From | To | Distance (km) | Duration | Speed (km/h) | Pace (min/km) |" ||
+		lines[5] != "| 2 | Cafe | Cafe | 0.000 | 0:10:00 | 0.0 | |" ||
+		lines[7] != "| | **Total** | | **2.224** | **0:20:00** | | |" ||
+		!strings.HasPrefix(lines[9], "Elapsed time: 0:20:00, moving time: 0:10:00") {
+		t.Errorf("Unexpected table:\n%s", b.String())
+	}
+}
+
+func TestWriteCSVTime(t *testing.T) {
+	var b strings.Builder
+	if err := writeCSV(&b, timedResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if lines[0] != "formula,unit,leg,from_index,from_name,from_lat,from_lon,from_time,to_index,to_name,to_lat,to_lon,to_time,distance,duration,speed,pace" {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "haversine,km,1,1,Gate,0,0,2024-05-01T09:00:00Z,2,Cafe,0.01,0,2024-05-01T09:10:00Z,1.11") ||
+		!strings.Contains(lines[1], ",600,6.67") {
+		t.Errorf("Unexpected record: %s", lines[1])
+	}
+	if !strings.HasSuffix(lines[3], ",,,") {
+		t.Errorf("Unexpected closing record: %s", lines[3])
+	}
+}
+
+func TestWriteJSONTime(t *testing.T) {
+	var b strings.Builder
+	if err := writeJSON(&b, timedResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Legs []struct {
+			From     map[string]any `json:"from"`
+			Duration *float64       `json:"duration"`
+			Speed    *float64       `json:"speed"`
+			Pace     *float64       `json:"pace"`
+		} `json:"legs"`
+		Duration    float64 `json:"duration"`
+		MovingTime  float64 `json:"movingTime"`
+		MovingSpeed float64 `json:"movingSpeed"`
+		MaxSpeed    float64 `json:"maxSpeed"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if leg := doc.Legs[0]; leg.From["time"] != "2024-05-01T09:00:00Z" || leg.Duration == nil || *leg.Duration != 600 || leg.Speed == nil || leg.Pace == nil {
+		t.Errorf("Unexpected leg: %+v", leg)
+	}
+	if leg := doc.Legs[1]; leg.Duration == nil || *leg.Duration != 600 || leg.Speed == nil || *leg.Speed != 0 || leg.Pace != nil {
+		t.Errorf("Unexpected stopped leg: %+v", leg)
+	}
+	if leg := doc.Legs[2]; leg.Duration != nil {
+		t.Errorf("Unexpected closing leg: %+v", leg)
+	}
+	if doc.Duration != 1200 || doc.MovingTime != 600 || doc.MaxSpeed != timedResult.MaxSpeed || doc.MovingSpeed != timedResult.MaxSpeed {
+		t.Errorf("Unexpected totals: %+v", doc)
+	}
+}
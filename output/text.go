@@ -0,0 +1,105 @@
+// Package output renders the results of go-distances calculations in
+// human- and machine-readable formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// writeText writes the result as an aligned plain-text table, with distances
+// rounded to whole units. Text columns are left-aligned and numbers
+// right-aligned. Extra columns follow the place they describe. Results with
+// elevations also show the elevation of every place, the slant distance of
+// every leg and the total ascent and descent, and results with times the
+// duration, speed and pace of every leg and the elapsed and moving time;
+// distances in both are rounded to three decimals.
+func writeText(w io.Writer, result *geo.Result, columns []Column) error {
+	decimals := tableDecimals(result)
+	columns = withElevation(result, columns, decimals)
+	legs := legColumns(result, decimals)
+
+	header := []string{"Leg", "From"}
+	for _, c := range columns {
+		header = append(header, c.Title)
+	}
+	header = append(header, "To")
+	for _, c := range columns {
+		header = append(header, c.Title)
+	}
+	for _, c := range legs {
+		header = append(header, c.title)
+	}
+
+	rows := [][]string{header}
+	for i, leg := range result.Legs {
+		row := []string{strconv.Itoa(i + 1), placeLabel(leg.FromIndex, leg.From)}
+		row = append(row, columnValues(columns, leg.From)...)
+		row = append(row, placeLabel(leg.ToIndex, leg.To))
+		row = append(row, columnValues(columns, leg.To)...)
+		for _, c := range legs {
+			row = append(row, c.value(leg))
+		}
+		rows = append(rows, row)
+	}
+	total := make([]string, len(header)-len(legs), len(header))
+	total[1] = "Total"
+	for _, c := range legs {
+		total = append(total, c.total)
+	}
+	rows = append(rows, total)
+
+	rightAlign := make([]bool, len(header))
+	rightAlign[0] = true
+	for i := len(header) - len(legs); i < len(header); i++ {
+		rightAlign[i] = true
+	}
+	if result.HasElevation() {
+		rightAlign[2] = true
+		rightAlign[3+len(columns)] = true
+	}
+
+	fmt.Fprintf(w, "Circular distances using %s formula:\n\n", result.Formula)
+	if _, err := io.WriteString(w, alignColumns(rows, rightAlign)); err != nil {
+		return err
+	}
+	if lines := summaryLines(result, decimals); len(lines) > 0 {
+		_, err := fmt.Fprintf(w, "\n%s\n", strings.Join(lines, "\n"))
+		return err
+	}
+	return nil
+}
+
+// alignColumns lays rows out in columns separated by two spaces. Columns
+// whose entry in rightAlign is true are padded on the left.
+func alignColumns(rows [][]string, rightAlign []bool) string {
+	widths := make([]int, len(rightAlign))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			padding := strings.Repeat(" ", widths[i]-len([]rune(cell)))
+			if rightAlign[i] {
+				line.WriteString(padding + cell)
+			} else {
+				line.WriteString(cell + padding)
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
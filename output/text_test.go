@@ -0,0 +1,23 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	var b strings.Builder
+	if err := writeText(&b, testResult, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `Circular distances using haversine formula:
+
+Leg  From      To        Distance (km)
+  1  New York  Point 2            3936
+  2  Point 2   New York           3936
+     Total                        7872
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dickeyy/go-distances/formulas"
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/output"
+	"github.com/dickeyy/go-distances/spatial"
+)
+
+// queryFlags are the flags shared by the commands that query a spatial index.
+type queryFlags struct {
+	at     *string
+	k      *int
+	radius *float64
+	box    *string
+}
+
+// addQueryFlags registers the query flags on flags.
+func addQueryFlags(flags *flag.FlagSet) *queryFlags {
+	return &queryFlags{
+		at:     flags.String("at", "", "location to search around, as latitude,longitude in degrees"),
+		k:      flags.Int("k", 0, "number of nearest places to return (default 5 without -radius)"),
+		radius: flags.Float64("radius", 0, "return every place within this distance, in the unit of the places file"),
+		box:    flags.String("box", "", "return every place inside the bounding box minLat,minLon,maxLat,maxLon instead of searching around -at"),
+	}
+}
+
+// valid reports whether the flags describe a query.
+func (q *queryFlags) valid() bool {
+	return (*q.at != "") != (*q.box != "")
+}
+
+// run answers the query described by the flags and prints the places found
+// on the standard output of c. Errors are reported with logger. It returns
+// the process exit code.
+func (q *queryFlags) run(c *cli, index *spatial.Index, logger *slog.Logger) int {
+	var neighbors []spatial.Neighbor
+	if *q.box != "" {
+		box, err := parseBox(*q.box)
+		if err != nil {
+			logger.Error("Invalid -box value.", "error", err)
+			return 2
+		}
+		neighbors = index.InBox(box)
+	} else {
+		query, err := parseLatLon(*q.at)
+		if err != nil {
+			logger.Error("Invalid -at value.", "error", err)
+			return 2
+		}
+		if *q.radius > 0 {
+			neighbors = index.Within(query, *q.radius)
+		} else {
+			k := *q.k
+			if k <= 0 {
+				k = 5
+			}
+			neighbors = index.Nearest(query, k)
+		}
+	}
+	if *q.k > 0 && len(neighbors) > *q.k {
+		neighbors = neighbors[:*q.k]
+	}
+
+	if err := output.WriteNeighbors(c.stdout, neighbors, index.Calculator().Body); err != nil {
+		logger.Error("Could not write the places found.", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// runSearch implements the search subcommand, which finds the places of a
+// places file nearest to a location. It returns the process exit code.
+//
+//	go-distances search -at 40.71,-74.00 [-k 5] [-radius 50] [-formula haversine] [-log-format json] places.json
+func (c *cli) runSearch(args []string) int {
+	flags := c.flagSet("search")
+	query := addQueryFlags(flags)
+	formula := flags.String("formula", "", "formula used as the distance metric: "+strings.Join(formulas.ExactNames(), ", ")+" (default: the formula of the places file)")
+	logging := addLogFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-distances search -at lat,lon | -box minLat,minLon,maxLat,maxLon [flags] <places file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || !query.valid() {
+		flags.Usage()
+		return 2
+	}
+	logger, err := logging.logger(c.stderr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Invalid -log-format value: %v.\n", err)
+		return 2
+	}
+
+	dataset, err := c.loadDataset(flags.Arg(0))
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	if *formula != "" {
+		dataset.Formula = *formula
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+
+	index, err := spatial.NewIndex(dataset.Path, calculator)
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	return query.run(c, index, logger)
+}
+
+// parseLatLon parses a location written as "latitude,longitude" in degrees.
+// Locations off the globe are rejected.
+func parseLatLon(s string) (geo.Point, error) {
+	latitude, longitude, ok := strings.Cut(s, ",")
+	if !ok {
+		return geo.Point{}, fmt.Errorf("invalid location %q: expected latitude,longitude", s)
+	}
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+	lon, lonErr := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+	if err := errors.Join(latErr, lonErr); err != nil {
+		return geo.Point{}, fmt.Errorf("invalid location %q: %w", s, err)
+	}
+	if !(math.Abs(lat) <= 90) || !(math.Abs(lon) <= 180) {
+		return geo.Point{}, fmt.Errorf("invalid location %q: latitude must be within [-90, 90] and longitude within [-180, 180]", s)
+	}
+	return geo.NewPoint(lat, lon), nil
+}
+
+// parseBox parses a bounding box written as "minLat,minLon,maxLat,maxLon" in
+// degrees.
+func parseBox(s string) (geo.Box, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return geo.Box{}, fmt.Errorf("invalid box %q: expected minLat,minLon,maxLat,maxLon", s)
+	}
+	var values [4]float64
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return geo.Box{}, fmt.Errorf("invalid box %q: %w", s, err)
+		}
+		values[i] = value
+	}
+	box := geo.Box{MinLat: values[0], MinLon: values[1], MaxLat: values[2], MaxLon: values[3]}
+	if err := box.Validate(); err != nil {
+		return geo.Box{}, err
+	}
+	return box, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestParseLatLon(t *testing.T) {
+	point, err := parseLatLon(" 40.7128, -74.0060")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if point.Lat != 40.7128 || point.Lon != -74.0060 {
+		t.Errorf("got %+v", point)
+	}
+	for _, invalid := range []string{"40.7128", "north,-74", "40,", "91,0", "0,-180.5", "NaN,0"} {
+		if _, err := parseLatLon(invalid); err == nil {
+			t.Errorf("parseLatLon(%q) succeeded", invalid)
+		}
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "places.csv")
+	err := os.WriteFile(filePath, []byte("name,lat,lon\nNew York,40.7128,-74.0060\nChicago,41.8781,-87.6298\n"), 0644)
+	if err != nil {
+		t.Fatalf("Error creating test file: %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"-at", "40,-100", filePath}, 0},
+		{[]string{"-at", "40,-100", "-radius", "2000", "-k", "1", filePath}, 0},
+		{[]string{"-at", "40,-100", "-formula", "invalid", filePath}, 1},
+		{[]string{"-at", "40,-100", "-formula", "equirectangular", filePath}, 1},
+		{[]string{"-at", "40,-100", "nonexistent.json"}, 1},
+		{[]string{"-at", "north", filePath}, 2},
+		{[]string{filePath}, 2},
+		{[]string{"-at", "40,-100", "-log-format", "xml", filePath}, 2},
+	}
+	for _, tt := range tests {
+		if got := quiet().runSearch(tt.args); got != tt.want {
+			t.Errorf("runSearch(%v) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestParseBox(t *testing.T) {
+	box, err := parseBox("40, -75, 41, -73")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if box.MinLat != 40 || box.MinLon != -75 || box.MaxLat != 41 || box.MaxLon != -73 {
+		t.Errorf("got %+v", box)
+	}
+	for _, invalid := range []string{"40,-75,41", "40,-75,41,east", "41,-75,40,-73"} {
+		if _, err := parseBox(invalid); err == nil {
+			t.Errorf("parseBox(%q) succeeded", invalid)
+		}
+	}
+}
+
+func FuzzParseLatLon(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		point, err := parseLatLon(s)
+		if err != nil {
+			return
+		}
+		if !(math.Abs(point.Lat) <= 90) || !(math.Abs(point.Lon) <= 180) {
+			t.Fatalf("parseLatLon(%q) = %+v, out of range", s, point)
+		}
+		written := strconv.FormatFloat(point.Lat, 'g', -1, 64) + "," + strconv.FormatFloat(point.Lon, 'g', -1, 64)
+		if again, err := parseLatLon(written); err != nil || again != point {
+			t.Fatalf("parseLatLon(%q) = %+v, %v, want %+v", written, again, err, point)
+		}
+	})
+}
+
+func FuzzParseBox(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		box, err := parseBox(s)
+		if err != nil {
+			return
+		}
+		if box.Validate() != nil {
+			t.Fatalf("parseBox(%q) = %+v, which is invalid", s, box)
+		}
+		written := fmt.Sprintf("%v,%v,%v,%v", box.MinLat, box.MinLon, box.MaxLat, box.MaxLon)
+		if again, err := parseBox(written); err != nil || again != box {
+			t.Fatalf("parseBox(%q) = %+v, %v, want %+v", written, again, err, box)
+		}
+	})
+}
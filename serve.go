@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dickeyy/go-distances/metrics"
+	"github.com/dickeyy/go-distances/server"
+)
+
+// runServe implements the serve subcommand, which answers distance, route,
+// matrix and bearing requests over HTTP until interrupted, logging every
+// request and serving metrics at /metrics. It returns the process exit code.
+//
+//	go-distances serve [-addr :8080] [-max-body 1048576] [-max-matrix-places 1000] [-shutdown-timeout 10s] [-log-format json]
+func (c *cli) runServe(args []string) int {
+	flags := c.flagSet("serve")
+	addr := flags.String("addr", ":8080", "address to listen on")
+	maxBody := flags.Int64("max-body", server.DefaultMaxBodyBytes, "largest request body accepted, in bytes")
+	maxMatrixPlaces := flags.Int("max-matrix-places", server.DefaultMaxMatrixPlaces, "most places accepted by a matrix request")
+	grace := flags.Duration("shutdown-timeout", 10*time.Second, "how long to wait for requests in flight when shutting down")
+	logging := addLogFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-distances serve [flags]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 || *maxBody <= 0 || *maxMatrixPlaces <= 0 {
+		flags.Usage()
+		return 2
+	}
+	logger, err := logging.logger(c.stderr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Invalid -log-format value: %v.\n", err)
+		return 2
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		logger.Error("Could not listen.", "addr", *addr, "error", err)
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	handler := server.New(server.Options{
+		MaxBodyBytes:    *maxBody,
+		MaxMatrixPlaces: *maxMatrixPlaces,
+		Metrics:         metrics.NewDistances(),
+		Logger:          logger,
+	})
+	logger.Info("Listening. The API is described at /openapi.json and metrics are served at /metrics.", "addr", listener.Addr().String())
+	if err := server.Serve(ctx, listener, handler, *grace); err != nil {
+		logger.Error("Could not serve.", "error", err)
+		return 1
+	}
+	logger.Info("Shut down.")
+	return 0
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestRunServeInvalid(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer listener.Close()
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"-max-body", "0"}, 2},
+		{[]string{"extra"}, 2},
+		{[]string{"-log-format", "xml"}, 2},
+		{[]string{"-log-level", "loud"}, 2},
+		{[]string{"-addr", listener.Addr().String()}, 1}, // already in use
+	}
+	for _, tt := range tests {
+		if got := quiet().runServe(tt.args); got != tt.want {
+			t.Errorf("runServe(%v) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package server exposes the go-distances calculations as an HTTP JSON API.
+// Every endpoint accepts a places document, in any format geo.Load reads, as
+// the request body, and answers with JSON. An OpenAPI description of the API
+// is served at /openapi.json.
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Serve serves handler on l until ctx is done, then shuts down gracefully:
+// it stops accepting connections and waits up to grace for requests in
+// flight to finish. It returns nil after a clean shutdown.
+func Serve(ctx context.Context, l net.Listener, handler http.Handler, grace time.Duration) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(l) }()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil {
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
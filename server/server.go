@@ -0,0 +1,339 @@
+// Package server exposes the go-distances calculations as an HTTP JSON API.
+// Every endpoint accepts a places document, in any format geo.Load reads, as
+// the request body, and answers with JSON. An OpenAPI description of the API
+// is served at /openapi.json.
+package server
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/metrics"
+	"github.com/dickeyy/go-distances/output"
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// DefaultMaxBodyBytes is the default limit on the size of request bodies.
+const DefaultMaxBodyBytes = 1 << 20
+
+// DefaultMaxMatrixPlaces is the default limit on the number of places of a
+// matrix request, whose response grows with the square of it: a million
+// distances for the default.
+const DefaultMaxMatrixPlaces = 1000
+
+var (
+	// errPlaceCount is wrapped by errors for documents with the wrong number
+	// of places for an endpoint.
+	errPlaceCount = errors.New("wrong number of places")
+	// errTooManyPlaces is wrapped by errors for documents with more places
+	// than an endpoint accepts.
+	errTooManyPlaces = errors.New("too many places")
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Options configure a Handler.
+type Options struct {
+	// MaxBodyBytes limits the size of request bodies. Larger requests are
+	// rejected with 413 Request Entity Too Large. Zero means
+	// DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// MaxMatrixPlaces limits the number of places of matrix requests. Larger
+	// requests are rejected with 413 Request Entity Too Large. Zero means
+	// DefaultMaxMatrixPlaces.
+	MaxMatrixPlaces int
+	// Metrics, if set, records requests, points and parse failures, and is
+	// served at /metrics.
+	Metrics *metrics.Distances
+	// Logger, if set, logs every request: at the info level when it
+	// succeeds, warn when it is rejected and error when it fails.
+	Logger *slog.Logger
+}
+
+// New returns a handler serving the API:
+//
+//	POST /distance      distance between the two places of the document
+//	POST /route         legs of the circular route through the places
+//	POST /matrix        distance between every pair of places
+//	POST /bearing       initial and final bearing between two places
+//	GET  /openapi.json  OpenAPI description of the API
+//	GET  /metrics       metrics in the Prometheus text format, if enabled
+//
+// The formula of the document can be overridden with a formula query
+// parameter, as in /route?formula=haversine.
+func New(options Options) http.Handler {
+	if options.MaxBodyBytes <= 0 {
+		options.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if options.MaxMatrixPlaces <= 0 {
+		options.MaxMatrixPlaces = DefaultMaxMatrixPlaces
+	}
+	if options.Logger == nil {
+		options.Logger = slog.New(slog.DiscardHandler)
+	}
+	s := &server{options: options}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /distance", s.handle("distance", distance))
+	mux.HandleFunc("POST /route", s.handle("route", route))
+	mux.HandleFunc("POST /matrix", s.handle("matrix", s.matrix))
+	mux.HandleFunc("POST /bearing", s.handle("bearing", bearing))
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	if options.Metrics != nil {
+		mux.Handle("GET /metrics", options.Metrics.Registry.Handler())
+	}
+	return mux
+}
+
+type server struct {
+	options Options
+}
+
+// endpoint answers a request for a dataset. It returns the response body,
+// which is written as JSON, or an error.
+type endpoint func(dataset *geo.Dataset, calculator *geo.Calculator) (any, error)
+
+// handle adapts an endpoint to an http.HandlerFunc, loading the dataset from
+// the request body, reporting errors as JSON, and recording metrics and logs
+// under the endpoint's name.
+func (s *server) handle(name string, e endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		o := s.answer(w, r, e)
+		elapsed := time.Since(start)
+
+		formula := o.formula
+		if formula == "" {
+			formula = "none"
+		}
+		if m := s.options.Metrics; m != nil {
+			m.Requests.Inc(name, formula, strconv.Itoa(o.status))
+			m.RequestDuration.Observe(elapsed.Seconds(), name, formula)
+			if o.points > 0 {
+				m.Points.Add(float64(o.points), formula)
+			}
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case o.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case o.status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("endpoint", name),
+			slog.String("formula", o.formula),
+			slog.Int("status", o.status),
+			slog.Int("points", o.points),
+			slog.Duration("duration", elapsed),
+		}
+		if o.err != nil {
+			attrs = append(attrs, slog.Any("error", o.err))
+		}
+		s.options.Logger.LogAttrs(r.Context(), level, "request", attrs...)
+	}
+}
+
+// outcome is the outcome of a request, as recorded by handle.
+type outcome struct {
+	formula string // canonical name of the formula, empty if unknown
+	points  int    // places measured
+	status  int
+	err     error
+}
+
+// answer loads the dataset from the request body, answers it with e and
+// writes the response.
+func (s *server) answer(w http.ResponseWriter, r *http.Request, e endpoint) outcome {
+	dataset, err := geo.Load(http.MaxBytesReader(w, r.Body, s.options.MaxBodyBytes))
+	if err != nil {
+		if s.options.Metrics != nil {
+			s.options.Metrics.ParseFailure(err)
+		}
+		return outcome{status: writeError(w, err), err: err}
+	}
+	if formula := r.URL.Query().Get("formula"); formula != "" {
+		dataset.Formula = formula
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		return outcome{status: writeError(w, err), err: err}
+	}
+
+	formula := calculator.Formula.Name
+	response, err := e(dataset, calculator)
+	if err != nil {
+		return outcome{formula: formula, status: writeError(w, err), err: err}
+	}
+	writeJSON(w, http.StatusOK, response)
+	return outcome{formula: formula, points: len(dataset.Path), status: http.StatusOK}
+}
+
+// place is a place as written in responses. Index is the one-based position
+// of the place in the request.
+type place struct {
+	Index int     `json:"index"`
+	Name  string  `json:"name,omitempty"`
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+}
+
+func newPlace(index int, point geo.Point) place {
+	return place{Index: index + 1, Name: point.Name, Lat: point.Lat, Lon: point.Lon}
+}
+
+// measurement holds the members shared by the responses.
+type measurement struct {
+	Formula string `json:"formula"`
+	Body    string `json:"body"`
+	Unit    string `json:"unit"`
+}
+
+func newMeasurement(calculator *geo.Calculator) measurement {
+	return measurement{Formula: calculator.Formula.Name, Body: calculator.Body.Name, Unit: calculator.Body.UnitName()}
+}
+
+type distanceResponse struct {
+	measurement
+	From     place   `json:"from"`
+	To       place   `json:"to"`
+	Distance float64 `json:"distance"`
+}
+
+func distance(dataset *geo.Dataset, calculator *geo.Calculator) (any, error) {
+	if err := requirePair(dataset); err != nil {
+		return nil, err
+	}
+	a, b := dataset.Path[0], dataset.Path[1]
+	return distanceResponse{
+		measurement: newMeasurement(calculator),
+		From:        newPlace(0, a),
+		To:          newPlace(1, b),
+		Distance:    calculator.Distance(a, b),
+	}, nil
+}
+
+// route answers with the document written by the json output format, which
+// is already encoded, so it is returned as a json.RawMessage.
+func route(dataset *geo.Dataset, calculator *geo.Calculator) (any, error) {
+	result, err := calculator.Circular(dataset.Path)
+	if err != nil {
+		return nil, err
+	}
+	format, _ := output.Lookup("json")
+	var b bytes.Buffer
+	if err := format.Writer.WriteResult(&b, result); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b.Bytes()), nil
+}
+
+type matrixResponse struct {
+	measurement
+	Places    []place     `json:"places"`
+	Distances [][]float64 `json:"distances"`
+}
+
+func (s *server) matrix(dataset *geo.Dataset, calculator *geo.Calculator) (any, error) {
+	if len(dataset.Path) > s.options.MaxMatrixPlaces {
+		return nil, fmt.Errorf("%w: a matrix has at most %d places, got %d", errTooManyPlaces, s.options.MaxMatrixPlaces, len(dataset.Path))
+	}
+	response := matrixResponse{
+		measurement: newMeasurement(calculator),
+		Places:      make([]place, len(dataset.Path)),
+		Distances:   calculator.Matrix(dataset.Path),
+	}
+	for i, point := range dataset.Path {
+		response.Places[i] = newPlace(i, point)
+	}
+	return response, nil
+}
+
+type bearingResponse struct {
+	From           place   `json:"from"`
+	To             place   `json:"to"`
+	InitialBearing float64 `json:"initialBearing"`
+	FinalBearing   float64 `json:"finalBearing"`
+}
+
+func bearing(dataset *geo.Dataset, _ *geo.Calculator) (any, error) {
+	if err := requirePair(dataset); err != nil {
+		return nil, err
+	}
+	a, b := dataset.Path[0], dataset.Path[1]
+	return bearingResponse{
+		From:           newPlace(0, a),
+		To:             newPlace(1, b),
+		InitialBearing: geo.InitialBearing(a, b),
+		FinalBearing:   geo.FinalBearing(a, b),
+	}, nil
+}
+
+// requirePair checks that the dataset has exactly two places.
+func requirePair(dataset *geo.Dataset) error {
+	if len(dataset.Path) != 2 {
+		return fmt.Errorf("%w: expected 2, got %d", errPlaceCount, len(dataset.Path))
+	}
+	return nil
+}
+
+// errorResponse is the body of every error response.
+type errorResponse struct {
+	Error string `json:"error"`
+	// Place is the one-based index of the place the error is about, if any.
+	Place int `json:"place,omitempty"`
+	// Field is the field of the place the error is about, if any.
+	Field string `json:"field,omitempty"`
+}
+
+// writeError reports err with the status code matching its kind: 413 for
+// bodies over the size limit and documents with too many places, 400 for
+// invalid documents and 500 otherwise.
+// It returns the status code.
+func writeError(w http.ResponseWriter, err error) int {
+	response := errorResponse{Error: err.Error()}
+	status := http.StatusInternalServerError
+
+	var maxBytesErr *http.MaxBytesError
+	var parseErr *utils.ParseError
+	var fileErr *utils.FileError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		status = http.StatusRequestEntityTooLarge
+		response.Error = fmt.Sprintf("request body is larger than %d bytes", maxBytesErr.Limit)
+	case errors.Is(err, errTooManyPlaces):
+		status = http.StatusRequestEntityTooLarge
+	case errors.As(err, &parseErr):
+		status = http.StatusBadRequest
+		response.Place, response.Field = parseErr.Index+1, parseErr.Field
+	case errors.As(err, &fileErr) && !errors.Is(err, utils.ErrRead),
+		errors.Is(err, geo.ErrUnknownFormula),
+		errors.Is(err, geo.ErrTooFewPoints),
+		errors.Is(err, geo.ErrUnknownUnit),
+		errors.Is(err, errPlaceCount):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, response)
+	return status
+}
+
+func writeJSON(w http.ResponseWriter, status int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
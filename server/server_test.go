@@ -0,0 +1,227 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/metrics"
+)
+
+const twoPlaces = `{
+	"places": [
+		{"name": "New York", "latitude": "40.7128", "longitude": "-74.0060"},
+		{"name": "Los Angeles", "latitude": "34.0522", "longitude": "-118.2437"}
+	],
+	"formula": "haversine"
+}`
+
+// post sends body to path on a new handler and decodes the JSON response.
+func post(t *testing.T, options Options, path, body string, response any) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	New(options).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("%s: Content-Type = %q", path, got)
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatalf("%s: invalid JSON response %q: %v", path, recorder.Body.String(), err)
+	}
+	return recorder.Code
+}
+
+func TestDistance(t *testing.T) {
+	var response distanceResponse
+	if code := post(t, Options{}, "/distance", twoPlaces, &response); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if response.Formula != "haversine" || response.Unit != "km" || response.From.Name != "New York" || response.To.Index != 2 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if math.Abs(response.Distance-3935.75) > 0.01 {
+		t.Errorf("Distance = %v, want 3935.75", response.Distance)
+	}
+
+	if code := post(t, Options{}, "/distance?formula=vincenty", twoPlaces, &response); code != http.StatusOK || response.Formula != "vincenty" {
+		t.Errorf("formula parameter ignored: %d %+v", code, response)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	var response struct {
+		Formula string `json:"formula"`
+		Legs    []struct {
+			Distance float64 `json:"distance"`
+		} `json:"legs"`
+		Total float64 `json:"total"`
+	}
+	if code := post(t, Options{}, "/route", twoPlaces, &response); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(response.Legs) != 2 || math.Abs(response.Total-2*response.Legs[0].Distance) > 1e-9 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	body := "name,lat,lon\nNew York,40.7128,-74.0060\nLos Angeles,34.0522,-118.2437\nChicago,41.8781,-87.6298\n"
+	var response matrixResponse
+	if code := post(t, Options{}, "/matrix?formula=haversine", body, &response); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(response.Places) != 3 || len(response.Distances) != 3 || response.Places[2].Name != "Chicago" {
+		t.Fatalf("Unexpected response: %+v", response)
+	}
+	if response.Distances[0][0] != 0 || response.Distances[0][1] != response.Distances[1][0] || math.Abs(response.Distances[0][1]-3935.75) > 0.01 {
+		t.Errorf("Unexpected distances: %v", response.Distances)
+	}
+}
+
+func TestMatrixDefaultLimit(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("lat,lon\n")
+	for i := range DefaultMaxMatrixPlaces + 1 {
+		fmt.Fprintf(&b, "%d,%d\n", i%90, i%180)
+	}
+	var response errorResponse
+	if code := post(t, Options{}, "/matrix", b.String(), &response); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("got %d %+v for %d places, want 413", code, response, DefaultMaxMatrixPlaces+1)
+	}
+}
+
+func TestBearing(t *testing.T) {
+	var response bearingResponse
+	if code := post(t, Options{}, "/bearing", twoPlaces, &response); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if math.Abs(response.InitialBearing-273.7) > 0.1 || math.Abs(response.FinalBearing-245.9) > 0.1 {
+		t.Errorf("Unexpected bearings: %+v", response)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		path, body string
+		options    Options
+		status     int
+		field      string
+	}{
+		{"/distance", `{"places": [{"latitude": "north", "longitude": "1"}, {"latitude": "1", "longitude": "1"}]}`, Options{}, http.StatusBadRequest, "latitude"},
+		{"/distance", `{"places": [`, Options{}, http.StatusBadRequest, ""},
+		{"/route", `{"earthRadius": 3959, "places": [{"latitude": "1", "longitude": "1", "elevation": "10"}, {"latitude": "2", "longitude": "2"}]}`, Options{}, http.StatusBadRequest, "elevation"},
+		{"/route", `{"earthRadius": 3959, "places": [{"latitude": "1", "longitude": "1", "time": "2024-05-01T09:00:00Z"}, {"latitude": "2", "longitude": "2", "time": "2024-05-01T10:00:00Z"}]}`, Options{}, http.StatusBadRequest, ""},
+		{"/distance", `{"reference": "nowhere!", "places": [{"location": "CWC8+R9"}, {"latitude": "1", "longitude": "1"}]}`, Options{}, http.StatusBadRequest, "reference"},
+		{"/distance", "", Options{}, http.StatusBadRequest, ""},
+		{"/distance", "name,lat,lon\nA,1,1\nB,2,2\nC,3,3\n", Options{}, http.StatusBadRequest, ""},
+		{"/bearing", "name,lat,lon\nA,1,1\n", Options{}, http.StatusBadRequest, ""},
+		{"/route", "name,lat,lon\nA,1,1\n", Options{}, http.StatusBadRequest, ""},
+		{"/route?formula=flat", twoPlaces, Options{}, http.StatusBadRequest, ""},
+		{"/route", twoPlaces, Options{MaxBodyBytes: 16}, http.StatusRequestEntityTooLarge, ""},
+		{"/matrix", "name,lat,lon\nA,1,1\nB,2,2\nC,3,3\n", Options{MaxMatrixPlaces: 2}, http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, tt := range tests {
+		var response errorResponse
+		if code := post(t, tt.options, tt.path, tt.body, &response); code != tt.status || response.Error == "" || response.Field != tt.field {
+			t.Errorf("%s %q: got %d %+v, want %d", tt.path, tt.body, code, response, tt.status)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	New(Options{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/distance", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /distance: status %d, want 405", recorder.Code)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	New(Options{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/distance", "/route", "/matrix", "/bearing", "/metrics"} {
+		if doc.Paths[path] == nil {
+			t.Errorf("OpenAPI description has no %s path", path)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := metrics.NewDistances()
+	options := Options{Metrics: m}
+	var response map[string]any
+	post(t, options, "/distance?formula=sloc", twoPlaces, &response)
+	post(t, options, "/distance", twoPlaces, &response)
+	post(t, options, "/distance", `{"places": [{"latitude": "north", "longitude": "1"}]}`, &response)
+	post(t, options, "/route?formula=flat", twoPlaces, &response)
+
+	for _, tt := range []struct {
+		counter *metrics.Counter
+		values  []string
+		want    float64
+	}{
+		{m.Requests, []string{"distance", "sloc", "200"}, 1},
+		{m.Requests, []string{"distance", "haversine", "200"}, 1},
+		{m.Requests, []string{"distance", "none", "400"}, 1},
+		{m.Requests, []string{"route", "none", "400"}, 1},
+		{m.Points, []string{"haversine"}, 2},
+		{m.ParseFailures, []string{"latitude"}, 1},
+	} {
+		if got := tt.counter.Value(tt.values...); got != tt.want {
+			t.Errorf("%v = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+	if got := m.RequestDuration.Count("distance", "haversine"); got != 1 {
+		t.Errorf("RequestDuration count = %d, want 1", got)
+	}
+
+	recorder := httptest.NewRecorder()
+	New(options).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if want := `go_distances_requests_total{endpoint="distance",formula="sloc",code="200"} 1`; !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("/metrics has no %s in\n%s", want, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	New(Options{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("/metrics without metrics: status %d, want 404", recorder.Code)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var b bytes.Buffer
+	options := Options{Logger: slog.New(slog.NewJSONHandler(&b, nil))}
+	var response map[string]any
+	post(t, options, "/distance", twoPlaces, &response)
+	post(t, options, "/distance", `{"places": [`, &response)
+
+	var entries []map[string]any
+	for line := range strings.Lines(b.String()) {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d log entries, want 2", len(entries))
+	}
+	if e := entries[0]; e["level"] != "INFO" || e["endpoint"] != "distance" || e["formula"] != "haversine" || e["status"] != 200.0 || e["points"] != 2.0 {
+		t.Errorf("Unexpected entry %v", e)
+	}
+	if e := entries[1]; e["level"] != "WARN" || e["status"] != 400.0 || e["error"] == nil {
+		t.Errorf("Unexpected entry %v", e)
+	}
+}
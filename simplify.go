@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/simplify"
+	"github.com/dickeyy/go-distances/utils"
+)
+
+// runSimplify implements the simplify subcommand, which drops the points of
+// a track that lie within a tolerance of the simplified track, writes the
+// simplified places file and reports how the length of the track changed.
+// It returns the process exit code.
+//
+//	go-distances simplify -tolerance 0.01 [-algorithm visvalingam] [-format geojson] [-o out.gpx] [-log-format json] track.gpx
+func (c *cli) runSimplify(args []string) int {
+	flags := c.flagSet("simplify")
+	tolerance := flags.Float64("tolerance", 0, "largest distance a dropped point may lie from the simplified track, in the unit of the places file")
+	algorithm := flags.String("algorithm", "douglas-peucker", "simplification algorithm: "+strings.Join(simplify.Names(), ", "))
+	format := flags.String("format", "", "format of the simplified places file: json, geojson, csv or gpx (default: the format of the input)")
+	out := flags.String("o", "", "path of the simplified places file to write (default: standard output)")
+	logging := addLogFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-distances simplify -tolerance <distance> [flags] <places file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *tolerance <= 0 {
+		flags.Usage()
+		return 2
+	}
+	logger, err := logging.logger(c.stderr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Invalid -log-format value: %v.\n", err)
+		return 2
+	}
+	simplifier, ok := simplify.Lookup(*algorithm)
+	if !ok {
+		logger.Error(fmt.Sprintf("Invalid algorithm %q. Use one of: %s.", *algorithm, strings.Join(simplify.Names(), ", ")))
+		return 2
+	}
+	var outputFormat utils.Format
+	if *format != "" {
+		if outputFormat, err = utils.ParseFormat(*format); err != nil {
+			logger.Error(fmt.Sprintf("Invalid format %q. Use json, geojson, csv or gpx.", *format))
+			return 2
+		}
+	}
+
+	dataset, err := c.loadDataset(flags.Arg(0))
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	calculator, err := dataset.Calculator()
+	if err != nil {
+		logger.Error(describeError(err), "error", err)
+		return 1
+	}
+	if outputFormat == "" {
+		outputFormat = dataset.Format
+	}
+
+	simplified := *dataset
+	simplified.Path = simplifier.Simplify(dataset.Path, *tolerance, dataset.Body)
+
+	var w io.Writer = c.stdout
+	var file *os.File
+	if *out != "" {
+		if file, err = os.Create(*out); err != nil {
+			logger.Error("Could not create the simplified places file.", "file", *out, "error", err)
+			return 1
+		}
+		w = file
+	}
+	err = geo.Write(w, &simplified, outputFormat)
+	if file != nil {
+		// a failed close can lose the end of the file
+		err = errors.Join(err, file.Close())
+	}
+	if err != nil {
+		logger.Error("Could not write the simplified places.", "error", err)
+		return 1
+	}
+
+	fmt.Fprintln(c.stderr, describeSimplification(dataset, &simplified, calculator))
+	return 0
+}
+
+// describeSimplification summarises how simplifying a dataset changed its
+// number of points and the length of its track.
+func describeSimplification(original, simplified *geo.Dataset, calculator *geo.Calculator) string {
+	before, after := calculator.Length(original.Path), calculator.Length(simplified.Path)
+	unit := original.Body.UnitName()
+	summary := fmt.Sprintf("Kept %d of %d points. Length changed from %.3f %s to %.3f %s", len(simplified.Path), len(original.Path), before, unit, after, unit)
+	if before == 0 {
+		return summary + "."
+	}
+	return summary + fmt.Sprintf(" (%+.3g%%).", (after-before)/before*100)
+}
@@ -0,0 +1,135 @@
+// Package simplify reduces the number of points of a path while keeping its
+// shape, for GPS tracks with thousands of nearly collinear points. Deviation
+// from the simplified path is measured on the sphere, with great-circle
+// cross-track distances and spherical areas rather than planar geometry, so
+// tolerances are real distances in the unit of the body's radius.
+package simplify
+
+import (
+	"math"
+	"slices"
+
+	"github.com/dickeyy/go-distances/ecef"
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/nvector"
+)
+
+// Func simplifies path with the given tolerance, in the unit of the body's
+// radius. It always keeps the first and last points, and returns the kept
+// points in their original order.
+type Func func(path geo.Path, tolerance float64, body geo.Body) geo.Path
+
+// Algorithm is a simplification algorithm registered under a name.
+type Algorithm struct {
+	Name     string
+	Simplify Func
+}
+
+// registry holds the registered algorithms in registration order.
+var registry = []Algorithm{
+	{Name: "douglas-peucker", Simplify: DouglasPeucker},
+	{Name: "visvalingam", Simplify: Visvalingam},
+}
+
+// Register adds an algorithm, replacing any algorithm already registered
+// under the same name. It is meant to be called from init functions.
+func Register(algorithm Algorithm) {
+	i := slices.IndexFunc(registry, func(a Algorithm) bool { return a.Name == algorithm.Name })
+	if i >= 0 {
+		registry[i] = algorithm
+		return
+	}
+	registry = append(registry, algorithm)
+}
+
+// Lookup returns the algorithm registered under name.
+func Lookup(name string) (Algorithm, bool) {
+	i := slices.IndexFunc(registry, func(a Algorithm) bool { return a.Name == name })
+	if i < 0 {
+		return Algorithm{}, false
+	}
+	return registry[i], true
+}
+
+// Names returns the names of every registered algorithm in registration
+// order.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, a := range registry {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// DouglasPeucker simplifies path with the Ramer–Douglas–Peucker algorithm:
+// starting from the segment between the first and last points, it keeps the
+// point farthest from the segment and splits it there, until every dropped
+// point is within tolerance of the great-circle segment between the kept
+// points on either side of it.
+func DouglasPeucker(path geo.Path, tolerance float64, body geo.Body) geo.Path {
+	if len(path) <= 2 {
+		return slices.Clone(path)
+	}
+	vectors := nvectors(path)
+	limit := tolerance / body.Radius
+
+	keep := make([]bool, len(path))
+	keep[0], keep[len(path)-1] = true, true
+	// spans are split iteratively, as tracks may be too long to recurse on
+	spans := [][2]int{{0, len(path) - 1}}
+	for len(spans) > 0 {
+		first, last := spans[len(spans)-1][0], spans[len(spans)-1][1]
+		spans = spans[:len(spans)-1]
+
+		farthest, distance := -1, limit
+		for i := first + 1; i < last; i++ {
+			if d := SegmentAngle(vectors[i], vectors[first], vectors[last]); d > distance {
+				farthest, distance = i, d
+			}
+		}
+		if farthest >= 0 {
+			keep[farthest] = true
+			spans = append(spans, [2]int{first, farthest}, [2]int{farthest, last})
+		}
+	}
+	return kept(path, keep)
+}
+
+// SegmentAngle returns the angle, in radians, between p and the nearest
+// point of the shorter great-circle segment from a to b: the cross-track
+// angle when p lies beside the segment, or the angle to the nearer endpoint
+// when it lies beyond either end.
+func SegmentAngle(p, a, b nvector.Vector) float64 {
+	va, vb, vp := ecef.Vector(a), ecef.Vector(b), ecef.Vector(p)
+	normal := va.Cross(vb)
+	norm := normal.Norm()
+	if norm < 1e-15 {
+		// a and b coincide, or are antipodal and have no unique segment
+		return min(p.Angle(a), p.Angle(b))
+	}
+	normal = normal.Scale(1 / norm)
+	if va.Cross(vp).Dot(normal) >= 0 && vp.Cross(vb).Dot(normal) >= 0 {
+		return math.Abs(math.Asin(max(-1, min(1, normal.Dot(vp)))))
+	}
+	return min(p.Angle(a), p.Angle(b))
+}
+
+// nvectors returns the n-vector of every point of path.
+func nvectors(path geo.Path) []nvector.Vector {
+	vectors := make([]nvector.Vector, len(path))
+	for i, point := range path {
+		vectors[i] = nvector.FromLatLon(point.Lat, point.Lon)
+	}
+	return vectors
+}
+
+// kept returns the points of path whose entry in keep is true.
+func kept(path geo.Path, keep []bool) geo.Path {
+	var simplified geo.Path
+	for i, point := range path {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
@@ -0,0 +1,111 @@
+package simplify
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/nvector"
+)
+
+// wiggle is a track along the equator whose points stray about a metre
+// north and south of it, with a spike 111 m north at index 5.
+var wiggle = func() geo.Path {
+	var path geo.Path
+	for i := 0; i <= 10; i++ {
+		lat := 0.00001 * float64(i%2*2-1)
+		if i == 5 {
+			lat = 0.001
+		}
+		path = append(path, geo.Point{Lat: lat, Lon: 0.01 * float64(i)})
+	}
+	return path
+}()
+
+func TestSegmentAngle(t *testing.T) {
+	a, b := nvector.FromLatLon(0, 0), nvector.FromLatLon(0, 1)
+	tests := []struct {
+		lat, lon float64
+		want     float64 // degrees
+	}{
+		{0.5, 0.5, 0.5},  // beside the segment
+		{-0.5, 0.5, 0.5}, // on the other side
+		{0, 2, 1},        // beyond the end
+		{0, -3, 3},       // before the start
+		{0, 0.3, 0},      // on the segment
+	}
+	for _, tt := range tests {
+		got := SegmentAngle(nvector.FromLatLon(tt.lat, tt.lon), a, b) * 180 / math.Pi
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("SegmentAngle(%v, %v) = %v°, want %v°", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+	// a segment of zero length is a point
+	if got := SegmentAngle(nvector.FromLatLon(0, 2), a, a) * 180 / math.Pi; math.Abs(got-2) > 1e-9 {
+		t.Errorf("SegmentAngle to a point = %v°, want 2°", got)
+	}
+}
+
+func TestDouglasPeucker(t *testing.T) {
+	got := DouglasPeucker(wiggle, 0.01, geo.Earth) // 10 m
+	// the spike and the points at its foot, which are far from the
+	// segments to the top of the spike
+	want := geo.Path{wiggle[0], wiggle[4], wiggle[5], wiggle[6], wiggle[10]}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// with a tolerance below the wiggles every point is kept
+	if got := DouglasPeucker(wiggle, 0.0001, geo.Earth); len(got) != len(wiggle) {
+		t.Errorf("Kept %d of %d points with a 10 cm tolerance", len(got), len(wiggle))
+	}
+	if got := DouglasPeucker(wiggle, 1, geo.Earth); !slices.Equal(got, geo.Path{wiggle[0], wiggle[10]}) {
+		t.Errorf("Kept %v with a 1 km tolerance", got)
+	}
+}
+
+func TestDouglasPeuckerAntimeridian(t *testing.T) {
+	// a straight track across the antimeridian, which planar geometry
+	// would see as a detour around the globe
+	path := geo.Path{{Lat: 10, Lon: 179.99}, {Lat: 10.00001, Lon: 180}, {Lat: 10, Lon: -179.99}}
+	if got := DouglasPeucker(path, 0.01, geo.Earth); len(got) != 2 {
+		t.Errorf("Kept %d points across the antimeridian, want 2", len(got))
+	}
+}
+
+func TestShortPaths(t *testing.T) {
+	for _, algorithm := range registry {
+		for _, path := range []geo.Path{nil, wiggle[:1], wiggle[:2]} {
+			if got := algorithm.Simplify(path, 1, geo.Earth); !slices.Equal(got, path) {
+				t.Errorf("%s(%v) = %v", algorithm.Name, path, got)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"douglas-peucker", "visvalingam"} {
+		if algorithm, ok := Lookup(name); !ok || algorithm.Simplify == nil {
+			t.Errorf("Lookup(%q) failed", name)
+		}
+	}
+	if _, ok := Lookup("bezier"); ok {
+		t.Error(`Lookup("bezier") succeeded`)
+	}
+	if names := Names(); !slices.Equal(names[:2], []string{"douglas-peucker", "visvalingam"}) {
+		t.Errorf("Names() = %v", names)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func(saved []Algorithm) { registry = saved }(slices.Clone(registry))
+
+	endpoints := func(path geo.Path, tolerance float64, body geo.Body) geo.Path {
+		return geo.Path{path[0], path[len(path)-1]}
+	}
+	Register(Algorithm{Name: "endpoints", Simplify: endpoints})
+	algorithm, ok := Lookup("endpoints")
+	if !ok || len(algorithm.Simplify(wiggle, 0, geo.Earth)) != 2 {
+		t.Error("Registered algorithm not found")
+	}
+}
@@ -0,0 +1,103 @@
+// Package simplify reduces the number of points of a path while keeping its
+// shape, for GPS tracks with thousands of nearly collinear points. Deviation
+// from the simplified path is measured on the sphere, with great-circle
+// cross-track distances and spherical areas rather than planar geometry, so
+// tolerances are real distances in the unit of the body's radius.
+package simplify
+
+import (
+	"container/heap"
+	"math"
+	"slices"
+
+	"github.com/dickeyy/go-distances/ecef"
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/nvector"
+)
+
+// Visvalingam simplifies path with the Visvalingam–Whyatt algorithm: it
+// repeatedly drops the point whose triangle with its two neighbours has the
+// smallest area on the sphere, while that area is less than the square of
+// tolerance. A 10 m tolerance, for example, drops points whose triangles are
+// smaller than 100 m². The area of a point never decreases below that of a
+// point dropped before it, so points are dropped in order of significance.
+func Visvalingam(path geo.Path, tolerance float64, body geo.Body) geo.Path {
+	if len(path) <= 2 {
+		return slices.Clone(path)
+	}
+	vectors := nvectors(path)
+	// compare spherical excesses, in steradians, rather than areas
+	limit := tolerance * tolerance / (body.Radius * body.Radius)
+
+	prev := make([]int, len(path))
+	next := make([]int, len(path))
+	areas := make([]float64, len(path))
+	queue := &areaQueue{}
+	for i := 1; i < len(path)-1; i++ {
+		prev[i], next[i] = i-1, i+1
+		areas[i] = SphericalExcess(vectors[i-1], vectors[i], vectors[i+1])
+		heap.Push(queue, areaEntry{index: i, area: areas[i]})
+	}
+
+	keep := make([]bool, len(path))
+	for i := range keep {
+		keep[i] = true
+	}
+	for queue.Len() > 0 {
+		entry := heap.Pop(queue).(areaEntry)
+		i := entry.index
+		if !keep[i] || entry.area != areas[i] {
+			// a stale entry for a point dropped or updated since
+			continue
+		}
+		if entry.area >= limit {
+			break
+		}
+		keep[i] = false
+		p, n := prev[i], next[i]
+		next[p], prev[n] = n, p
+		for _, j := range []int{p, n} {
+			if j == 0 || j == len(path)-1 {
+				continue
+			}
+			areas[j] = max(entry.area, SphericalExcess(vectors[prev[j]], vectors[j], vectors[next[j]]))
+			heap.Push(queue, areaEntry{index: j, area: areas[j]})
+		}
+	}
+	return kept(path, keep)
+}
+
+// SphericalExcess returns the area, in steradians, of the spherical
+// triangle with corners a, b and c, following Van Oosterom and Strackee.
+// Multiply by the square of a radius for the area on a sphere.
+func SphericalExcess(a, b, c nvector.Vector) float64 {
+	va, vb, vc := ecef.Vector(a), ecef.Vector(b), ecef.Vector(c)
+	numerator := math.Abs(va.Dot(vb.Cross(vc)))
+	denominator := 1 + va.Dot(vb) + vb.Dot(vc) + vc.Dot(va)
+	return 2 * math.Atan2(numerator, denominator)
+}
+
+// areaEntry is a point in an areaQueue, with its area when it was queued.
+type areaEntry struct {
+	index int
+	area  float64
+}
+
+// areaQueue is a min-heap of points by area, ties broken by position.
+type areaQueue []areaEntry
+
+func (q areaQueue) Len() int { return len(q) }
+func (q areaQueue) Less(i, j int) bool {
+	if q[i].area != q[j].area {
+		return q[i].area < q[j].area
+	}
+	return q[i].index < q[j].index
+}
+func (q areaQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *areaQueue) Push(x any)   { *q = append(*q, x.(areaEntry)) }
+func (q *areaQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
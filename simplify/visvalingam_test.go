@@ -0,0 +1,52 @@
+package simplify
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+	"github.com/dickeyy/go-distances/nvector"
+)
+
+func TestSphericalExcess(t *testing.T) {
+	// the octant between the equator and two meridians 90° apart covers an
+	// eighth of the sphere
+	a, b, c := nvector.FromLatLon(0, 0), nvector.FromLatLon(0, 90), nvector.FromLatLon(90, 0)
+	if got := SphericalExcess(a, b, c); math.Abs(got-math.Pi/2) > 1e-12 {
+		t.Errorf("SphericalExcess of an octant = %v, want π/2", got)
+	}
+	// small triangles approach their planar area
+	d := 1e-4
+	a, b, c = nvector.FromLatLon(0, 0), nvector.FromLatLon(0, d*180/math.Pi), nvector.FromLatLon(d*180/math.Pi, 0)
+	if got, want := SphericalExcess(a, b, c), d*d/2; math.Abs(got-want)/want > 1e-6 {
+		t.Errorf("SphericalExcess of a small triangle = %v, want %v", got, want)
+	}
+	if got := SphericalExcess(a, a, b); got != 0 {
+		t.Errorf("SphericalExcess of a degenerate triangle = %v, want 0", got)
+	}
+}
+
+func TestVisvalingam(t *testing.T) {
+	// each wiggle forms a triangle of about 2.2 km × 2.2 m / 2 ≈ 2,500 m²,
+	// while the spike and its feet form triangles of over 50,000 m²
+	got := Visvalingam(wiggle, 0.1, geo.Earth) // 10,000 m²
+	want := geo.Path{wiggle[0], wiggle[4], wiggle[5], wiggle[6], wiggle[10]}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := Visvalingam(wiggle, 0.001, geo.Earth); len(got) != len(wiggle) {
+		t.Errorf("Kept %d of %d points with a 1 m² threshold", len(got), len(wiggle))
+	}
+	if got := Visvalingam(wiggle, 1, geo.Earth); !slices.Equal(got, geo.Path{wiggle[0], wiggle[10]}) {
+		t.Errorf("Kept %v with a 1 km² threshold", got)
+	}
+}
+
+func TestVisvalingamKeepsOrder(t *testing.T) {
+	path := geo.Path{{Lat: 0, Lon: 0}, {Lat: 1, Lon: 1}, {Lat: 0, Lon: 2}, {Lat: 1, Lon: 3}, {Lat: 0, Lon: 4}}
+	got := Visvalingam(path, 0, geo.Earth)
+	if !slices.Equal(got, path) {
+		t.Errorf("A zero tolerance dropped points: %v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+func TestRunSimplify(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "track.csv")
+	track := "lat,lon\n-0.00001,0\n0.00001,0.01\n-0.00001,0.02\n0.001,0.03\n-0.00001,0.04\n"
+	if err := os.WriteFile(filePath, []byte(track), 0644); err != nil {
+		t.Fatalf("Error creating test file: %v", err)
+	}
+	out := filepath.Join(dir, "simplified.geojson")
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"-tolerance", "0.01", "-format", "geojson", "-o", out, filePath}, 0},
+		{[]string{"-tolerance", "0.01", "-algorithm", "visvalingam", "-o", filepath.Join(dir, "v.csv"), filePath}, 0},
+		{[]string{"-tolerance", "0.01", "-algorithm", "bezier", filePath}, 2},
+		{[]string{"-tolerance", "0.01", "-format", "kml", filePath}, 2},
+		{[]string{"-tolerance", "0.01", "nonexistent.csv"}, 1},
+		{[]string{"-tolerance", "0.01", "-o", filepath.Join(dir, "missing", "out.csv"), filePath}, 1},
+		{[]string{filePath}, 2},
+		{[]string{"-tolerance", "0.01", "-log-format", "xml", filePath}, 2},
+	}
+	for _, tt := range tests {
+		if got := quiet().runSimplify(tt.args); got != tt.want {
+			t.Errorf("runSimplify(%v) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+
+	simplified, err := geo.LoadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(simplified.Path) != 4 || simplified.Format != "geojson" {
+		t.Errorf("Unexpected simplified track: %+v", simplified)
+	}
+}
+
+func TestRunSimplifyWriteError(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full to write to")
+	}
+	filePath := filepath.Join(t.TempDir(), "track.csv")
+	if err := os.WriteFile(filePath, []byte("lat,lon\n0,0\n0,0.01\n"), 0644); err != nil {
+		t.Fatalf("Error creating test file: %v", err)
+	}
+	if got := quiet().runSimplify([]string{"-tolerance", "0.01", "-o", "/dev/full", filePath}); got != 1 {
+		t.Errorf("writing to a full device = %d, want 1", got)
+	}
+}
+
+func TestRunSimplifyLogFormat(t *testing.T) {
+	c, _, stderr := testCLI("")
+	if got := c.runSimplify([]string{"-tolerance", "0.01", "-algorithm", "bezier", "-log-format", "json", "track.csv"}); got != 2 {
+		t.Fatalf("runSimplify = %d, want 2", got)
+	}
+	if !strings.HasPrefix(stderr.String(), "{") || !strings.Contains(stderr.String(), `"level":"ERROR"`) || !strings.Contains(stderr.String(), "bezier") {
+		t.Errorf("Unexpected log: %s", stderr)
+	}
+}
+
+func TestDescribeSimplification(t *testing.T) {
+	calculator, _ := geo.NewCalculator("haversine", geo.Earth)
+	original := &geo.Dataset{Path: testPath, Body: geo.Earth}
+	simplified := &geo.Dataset{Path: geo.Path{testPath[0], testPath[2]}, Body: geo.Earth}
+	got := describeSimplification(original, simplified, calculator)
+	if !strings.HasPrefix(got, "Kept 2 of 3 points. Length changed from 6739.718 km to 1144.291 km") || !strings.HasSuffix(got, "(-83%).") {
+		t.Errorf("got %q", got)
+	}
+	if got := describeSimplification(simplified, simplified, calculator); !strings.HasSuffix(got, "(+0%).") {
+		t.Errorf("got %q", got)
+	}
+}
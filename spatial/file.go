@@ -0,0 +1,398 @@
+// Package spatial answers nearest-neighbour, radius and bounding-box queries
+// over a set of places, using a vantage-point tree with a great-circle
+// formula as the metric. Indexes can be saved to disk and memory-mapped
+// back, so large places files need only be parsed once.
+package spatial
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// An index file is little-endian and laid out as
+//
+//	header        headerSize bytes, see below
+//	points        count × (lat float64, lon float64)
+//	nodes         count × (point int32, inside int32, outside int32, 0 uint32, threshold float64)
+//	name offsets  (count+1) × uint32, into the name bytes
+//	name bytes    names of the points, concatenated
+//	source path   path of the places file the index was built from
+//
+// Every record has a fixed size and the points and nodes are 8-byte aligned,
+// so a mapped file is queried in place without decoding it up front.
+const (
+	// FileVersion is the version of the index file layout written by Save.
+	FileVersion = 1
+
+	headerSize = 128
+	pointSize  = 16
+	nodeSize   = 24
+)
+
+// fileMagic identifies an index file.
+var fileMagic = [8]byte{'G', 'O', 'D', 'I', 'S', 'T', 'I', 'X'}
+
+// Offsets of the header fields.
+const (
+	offMagic       = 0
+	offVersion     = 8
+	offCount       = 16
+	offRoot        = 24
+	offRadius      = 32
+	offFormula     = 40 // 16 bytes
+	offUnit        = 56 // 16 bytes
+	offBodyName    = 72 // 16 bytes
+	offSourceSize  = 88
+	offSourceMTime = 96
+	offNamesLen    = 104
+	offPathLen     = 112
+	offChecksum    = 116
+)
+
+var (
+	// ErrNotIndex is returned when opening a file that is not an index file.
+	ErrNotIndex = errors.New("not a go-distances index file")
+	// ErrVersion is returned when opening an index file written in a layout
+	// this version of the package does not understand.
+	ErrVersion = errors.New("unsupported index file version")
+	// ErrCorrupt is returned when an index file fails its checksum or is
+	// inconsistent.
+	ErrCorrupt = errors.New("corrupt index file")
+	// ErrStale is returned when the places file an index was built from has
+	// changed since.
+	ErrStale = errors.New("index file is stale")
+	// ErrNotSavable is returned when saving an index built with a custom
+	// metric, which cannot be recreated when the file is opened.
+	ErrNotSavable = errors.New("index has no formula to save")
+)
+
+// castagnoli is the CRC-32C table used for index checksums.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Source describes the places file an index was built from, so stale
+// indexes can be detected.
+type Source struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// SourceOf describes the named places file.
+func SourceOf(name string) (Source, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return Source{}, err
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return Source{}, err
+	}
+	return Source{Path: path, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Save writes the index to the named file, recording source as the places
+// file it was built from. The index is written to a temporary file in the
+// same directory first and renamed over name once complete, so a failed
+// write leaves any previous file in place.
+func (ix *Index) Save(name string, source Source) error {
+	temp, err := os.CreateTemp(filepath.Dir(name), ".index-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if err := ix.Write(temp, source); err != nil {
+		temp.Close()
+		return err
+	}
+	// temporary files are private, but indexes are shared like places files
+	if err := temp.Chmod(0o644); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), name)
+}
+
+// Write writes the index to w in the index file layout. Points must have
+// latitudes in [-90, 90] and longitudes in [-180, 180].
+func (ix *Index) Write(w io.Writer, source Source) error {
+	if ix.calculator == nil {
+		return ErrNotSavable
+	}
+	formula := ix.calculator.Formula.Name
+	body := ix.calculator.Body
+	for _, field := range []string{formula, body.Unit, body.Name} {
+		if len(field) > 16 {
+			return fmt.Errorf("%q is too long to save in an index file", field)
+		}
+	}
+
+	count := ix.Len()
+	var rest bytes.Buffer
+	record := make([]byte, nodeSize)
+	for i := range count {
+		point := ix.store.location(int32(i))
+		if !(math.Abs(point.Lat) <= 90) || !(math.Abs(point.Lon) <= 180) {
+			return fmt.Errorf("point %d at %v, %v cannot be saved in an index file", i, point.Lat, point.Lon)
+		}
+		binary.LittleEndian.PutUint64(record[0:], math.Float64bits(point.Lat))
+		binary.LittleEndian.PutUint64(record[8:], math.Float64bits(point.Lon))
+		rest.Write(record[:pointSize])
+	}
+	for n := range count {
+		nd := ix.store.node(int32(n))
+		binary.LittleEndian.PutUint32(record[0:], uint32(nd.Point))
+		binary.LittleEndian.PutUint32(record[4:], uint32(nd.Inside))
+		binary.LittleEndian.PutUint32(record[8:], uint32(nd.Outside))
+		binary.LittleEndian.PutUint32(record[12:], 0)
+		binary.LittleEndian.PutUint64(record[16:], math.Float64bits(nd.Threshold))
+		rest.Write(record)
+	}
+	var names bytes.Buffer
+	for i := range count {
+		binary.LittleEndian.PutUint32(record, uint32(names.Len()))
+		rest.Write(record[:4])
+		names.WriteString(ix.store.place(int32(i)).Name)
+	}
+	binary.LittleEndian.PutUint32(record, uint32(names.Len()))
+	rest.Write(record[:4])
+	rest.Write(names.Bytes())
+	rest.WriteString(source.Path)
+
+	header := make([]byte, headerSize)
+	copy(header[offMagic:], fileMagic[:])
+	binary.LittleEndian.PutUint32(header[offVersion:], FileVersion)
+	binary.LittleEndian.PutUint64(header[offCount:], uint64(count))
+	binary.LittleEndian.PutUint64(header[offRoot:], uint64(int64(ix.root)))
+	binary.LittleEndian.PutUint64(header[offRadius:], math.Float64bits(body.Radius))
+	copy(header[offFormula:offFormula+16], formula)
+	copy(header[offUnit:offUnit+16], body.Unit)
+	copy(header[offBodyName:offBodyName+16], body.Name)
+	binary.LittleEndian.PutUint64(header[offSourceSize:], uint64(source.Size))
+	var mtime int64
+	if !source.ModTime.IsZero() {
+		mtime = source.ModTime.UnixNano()
+	}
+	binary.LittleEndian.PutUint64(header[offSourceMTime:], uint64(mtime))
+	binary.LittleEndian.PutUint64(header[offNamesLen:], uint64(names.Len()))
+	binary.LittleEndian.PutUint32(header[offPathLen:], uint32(len(source.Path)))
+	binary.LittleEndian.PutUint32(header[offChecksum:], checksum(header, rest.Bytes()))
+
+	bw := bufio.NewWriter(w)
+	bw.Write(header)
+	bw.Write(rest.Bytes())
+	return bw.Flush()
+}
+
+// checksum returns the CRC-32C of the header, excluding the checksum field
+// itself, followed by the rest of the file.
+func checksum(header, rest []byte) uint32 {
+	sum := crc32.Update(0, castagnoli, header[:offChecksum])
+	sum = crc32.Update(sum, castagnoli, header[offChecksum+4:])
+	return crc32.Update(sum, castagnoli, rest)
+}
+
+// File is an index opened from disk. Its memory stays mapped until Close is
+// called, after which it must not be queried.
+type File struct {
+	*Index
+	source Source
+	unmap  func() error
+}
+
+// Open opens the named index file, checking its version and checksum. When
+// the places file the index was built from still exists but has changed,
+// Open fails with ErrStale.
+func Open(name string) (*File, error) {
+	data, unmap, err := mapFile(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := newFile(data)
+	if err != nil {
+		unmap()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	file.unmap = unmap
+
+	if current, err := SourceOf(file.source.Path); err == nil && file.source.Path != "" {
+		if current.Size != file.source.Size || !current.ModTime.Equal(file.source.ModTime) {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w: %s has changed since the index was built", name, ErrStale, file.source.Path)
+		}
+	}
+	return file, nil
+}
+
+// Decode reads an index from data, which must stay unmodified for as long as
+// the index is used. Unlike Open it does not check for staleness.
+func Decode(data []byte) (*File, error) {
+	file, err := newFile(data)
+	if err != nil {
+		return nil, err
+	}
+	file.unmap = func() error { return nil }
+	return file, nil
+}
+
+// Source returns the places file the index was built from.
+func (f *File) Source() Source {
+	return f.source
+}
+
+// Close releases the memory of the index.
+func (f *File) Close() error {
+	return f.unmap()
+}
+
+// newFile validates data as an index file and wraps it in a File.
+func newFile(data []byte) (*File, error) {
+	if len(data) < headerSize || !bytes.Equal(data[offMagic:offMagic+8], fileMagic[:]) {
+		return nil, ErrNotIndex
+	}
+	if version := binary.LittleEndian.Uint32(data[offVersion:]); version != FileVersion {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, version)
+	}
+
+	count := binary.LittleEndian.Uint64(data[offCount:])
+	namesLen := binary.LittleEndian.Uint64(data[offNamesLen:])
+	pathLen := uint64(binary.LittleEndian.Uint32(data[offPathLen:]))
+	if count > math.MaxInt32 || namesLen > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: bad header", ErrCorrupt)
+	}
+	size := headerSize + count*(pointSize+nodeSize+4) + 4 + namesLen + pathLen
+	if size != uint64(len(data)) {
+		return nil, fmt.Errorf("%w: size is %d bytes, want %d", ErrCorrupt, len(data), size)
+	}
+	if checksum(data[:headerSize], data[headerSize:]) != binary.LittleEndian.Uint32(data[offChecksum:]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+
+	s := &fileStore{data: data, count: int32(count)}
+	s.nodesOff = headerSize + int(count)*pointSize
+	s.offsetsOff = s.nodesOff + int(count)*nodeSize
+	s.namesOff = s.offsetsOff + (int(count)+1)*4
+	if err := s.validate(int(namesLen)); err != nil {
+		return nil, err
+	}
+
+	root := int32(int64(binary.LittleEndian.Uint64(data[offRoot:])))
+	if count == 0 && root != -1 || count > 0 && root != 0 {
+		return nil, fmt.Errorf("%w: bad root node %d", ErrCorrupt, root)
+	}
+
+	body := geo.Body{
+		Name:   headerString(data[offBodyName : offBodyName+16]),
+		Radius: math.Float64frombits(binary.LittleEndian.Uint64(data[offRadius:])),
+		Unit:   headerString(data[offUnit : offUnit+16]),
+	}
+	calculator, err := geo.NewCalculator(headerString(data[offFormula:offFormula+16]), body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMetric(calculator); err != nil {
+		return nil, err
+	}
+
+	source := Source{
+		Path: string(data[s.namesOff+int(namesLen):]),
+		Size: int64(binary.LittleEndian.Uint64(data[offSourceSize:])),
+	}
+	if mtime := int64(binary.LittleEndian.Uint64(data[offSourceMTime:])); mtime != 0 {
+		source.ModTime = time.Unix(0, mtime)
+	}
+
+	index := &Index{store: s, len: int(count), root: root, metric: calculator.Distance, calculator: calculator}
+	return &File{Index: index, source: source}, nil
+}
+
+// headerString returns a NUL-padded string field of the header.
+func headerString(field []byte) string {
+	return string(bytes.TrimRight(field, "\x00"))
+}
+
+// fileStore is a store reading the records of an index file in place.
+type fileStore struct {
+	data       []byte
+	count      int32
+	nodesOff   int
+	offsetsOff int
+	namesOff   int
+}
+
+func (s *fileStore) location(i int32) geo.Point {
+	record := s.data[headerSize+int(i)*pointSize:]
+	return geo.Point{
+		Lat: math.Float64frombits(binary.LittleEndian.Uint64(record[0:])),
+		Lon: math.Float64frombits(binary.LittleEndian.Uint64(record[8:])),
+	}
+}
+
+func (s *fileStore) place(i int32) geo.Point {
+	point := s.location(i)
+	start := binary.LittleEndian.Uint32(s.data[s.offsetsOff+int(i)*4:])
+	end := binary.LittleEndian.Uint32(s.data[s.offsetsOff+int(i+1)*4:])
+	point.Name = string(s.data[s.namesOff+int(start) : s.namesOff+int(end)])
+	return point
+}
+
+func (s *fileStore) node(n int32) node {
+	record := s.data[s.nodesOff+int(n)*nodeSize:]
+	return node{
+		Point:     int32(binary.LittleEndian.Uint32(record[0:])),
+		Inside:    int32(binary.LittleEndian.Uint32(record[4:])),
+		Outside:   int32(binary.LittleEndian.Uint32(record[8:])),
+		Threshold: math.Float64frombits(binary.LittleEndian.Uint64(record[16:])),
+	}
+}
+
+// validate checks that every point is on the globe, that every node refers
+// to existing points and to child nodes after itself, so queries can neither
+// index out of range nor loop, and that the name offsets are in order.
+func (s *fileStore) validate(namesLen int) error {
+	for i := range s.count {
+		if p := s.location(i); !(math.Abs(p.Lat) <= 90) || !(math.Abs(p.Lon) <= 180) {
+			return fmt.Errorf("%w: point %d is at %v, %v", ErrCorrupt, i, p.Lat, p.Lon)
+		}
+	}
+	for n := range s.count {
+		nd := s.node(n)
+		if nd.Point < 0 || nd.Point >= s.count {
+			return fmt.Errorf("%w: node %d refers to point %d", ErrCorrupt, n, nd.Point)
+		}
+		for _, child := range []int32{nd.Inside, nd.Outside} {
+			if child != -1 && (child <= n || child >= s.count) {
+				return fmt.Errorf("%w: node %d has child %d", ErrCorrupt, n, child)
+			}
+		}
+	}
+	previous := uint32(0)
+	for i := range s.count + 1 {
+		offset := binary.LittleEndian.Uint32(s.data[s.offsetsOff+int(i)*4:])
+		if offset < previous || int(offset) > namesLen || i == 0 && offset != 0 {
+			return fmt.Errorf("%w: bad name offset %d", ErrCorrupt, offset)
+		}
+		previous = offset
+	}
+	if int(previous) != namesLen {
+		return fmt.Errorf("%w: names are %d bytes, want %d", ErrCorrupt, previous, namesLen)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package spatial
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// savedIndex builds an index over a named random path and saves it.
+func savedIndex(t *testing.T, source Source) (string, *Index, geo.Path) {
+	t.Helper()
+	path := randomPath(500, 6)
+	for i := range path {
+		if i%3 == 0 {
+			path[i].Name = "place " + string(rune('A'+i%26))
+		}
+	}
+	index, _ := newTestIndex(t, path)
+	name := filepath.Join(t.TempDir(), "places.idx")
+	if err := index.Save(name, source); err != nil {
+		t.Fatal(err)
+	}
+	return name, index, path
+}
+
+func TestSaveOpen(t *testing.T) {
+	name, index, _ := savedIndex(t, Source{})
+	file, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if file.Len() != index.Len() {
+		t.Fatalf("got %d points, want %d", file.Len(), index.Len())
+	}
+	if c := file.Calculator(); c.Formula.Name != "haversine" || c.Body != geo.Earth {
+		t.Fatalf("got calculator %s on %+v", c.Formula.Name, c.Body)
+	}
+	for _, query := range randomPath(20, 7) {
+		if got, want := file.Nearest(query, 7), index.Nearest(query, 7); !slices.Equal(got, want) {
+			t.Fatalf("Nearest(%v):\ngot  %v\nwant %v", query, got, want)
+		}
+		if got, want := file.Within(query, 2000), index.Within(query, 2000); !slices.Equal(got, want) {
+			t.Fatalf("Within(%v):\ngot  %v\nwant %v", query, got, want)
+		}
+	}
+}
+
+func TestInBox(t *testing.T) {
+	_, index, path := savedIndex(t, Source{})
+	for _, box := range []geo.Box{
+		{MinLat: 10, MinLon: -30, MaxLat: 50, MaxLon: 20},
+		{MinLat: -60, MinLon: 150, MaxLat: -20, MaxLon: -160},
+		{MinLat: 60, MinLon: -180, MaxLat: 90, MaxLon: 180},
+	} {
+		var want []int
+		for i, point := range path {
+			if box.Contains(point) {
+				want = append(want, i)
+			}
+		}
+		var got []int
+		for _, neighbor := range index.InBox(box) {
+			got = append(got, neighbor.Index)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("InBox(%v): got %v, want %v", box, got, want)
+		}
+	}
+}
+
+func TestOpenCorrupt(t *testing.T) {
+	name, _, _ := savedIndex(t, Source{})
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := bytes.Clone(data)
+	corrupt[headerSize+3] ^= 0xff
+	if _, err := Decode(corrupt); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("flipped byte: expected ErrCorrupt, got %v", err)
+	}
+	if _, err := Decode(data[:len(data)-1]); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("truncated: expected ErrCorrupt, got %v", err)
+	}
+
+	future := bytes.Clone(data)
+	binary.LittleEndian.PutUint32(future[offVersion:], FileVersion+1)
+	if _, err := Decode(future); !errors.Is(err, ErrVersion) {
+		t.Errorf("expected ErrVersion, got %v", err)
+	}
+
+	if _, err := Decode([]byte(`{"places": []}`)); !errors.Is(err, ErrNotIndex) {
+		t.Errorf("expected ErrNotIndex, got %v", err)
+	}
+}
+
+func TestOpenStale(t *testing.T) {
+	dir := t.TempDir()
+	places := filepath.Join(dir, "places.csv")
+	if err := os.WriteFile(places, []byte("lat,lon\n1,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := SourceOf(places)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, _, _ := savedIndex(t, source)
+
+	file, err := Open(name)
+	if err != nil {
+		t.Fatalf("fresh index: %v", err)
+	}
+	if file.Source().Path != source.Path || file.Source().Size != source.Size {
+		t.Errorf("got source %+v, want %+v", file.Source(), source)
+	}
+	file.Close()
+
+	later := source.ModTime.Add(time.Second)
+	if err := os.Chtimes(places, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(name); !errors.Is(err, ErrStale) {
+		t.Errorf("expected ErrStale, got %v", err)
+	}
+
+	// once the source is gone the index is all there is
+	os.Remove(places)
+	file, err = Open(name)
+	if err != nil {
+		t.Fatalf("missing source: %v", err)
+	}
+	file.Close()
+}
+
+func TestSaveCustomMetric(t *testing.T) {
+	index := NewIndexMetric(randomPath(3, 8), func(a, b geo.Point) float64 { return 0 })
+	if err := index.Write(&bytes.Buffer{}, Source{}); !errors.Is(err, ErrNotSavable) {
+		t.Errorf("expected ErrNotSavable, got %v", err)
+	}
+	if got := index.InBox(geo.Box{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180}); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func TestSaveFailureKeepsFile(t *testing.T) {
+	name, _, _ := savedIndex(t, Source{})
+	before, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index := NewIndexMetric(randomPath(3, 8), func(a, b geo.Point) float64 { return 0 })
+	if err := index.Save(name, Source{}); !errors.Is(err, ErrNotSavable) {
+		t.Fatalf("expected ErrNotSavable, got %v", err)
+	}
+	if after, err := os.ReadFile(name); err != nil || !bytes.Equal(after, before) {
+		t.Errorf("the index file changed after a failed save: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(name)); len(entries) != 1 {
+		t.Errorf("got %d files in the directory, want only the index file", len(entries))
+	}
+}
+
+func TestSaveOpenEmpty(t *testing.T) {
+	index, _ := newTestIndex(t, nil)
+	var b bytes.Buffer
+	if err := index.Write(&b, Source{}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := Decode(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Len() != 0 || file.Nearest(geo.NewPoint(0, 0), 1) != nil {
+		t.Errorf("expected an empty index")
+	}
+}
+
+func TestWriteOutOfRange(t *testing.T) {
+	index, _ := newTestIndex(t, geo.Path{geo.NewPoint(0, 0), geo.NewPoint(10, 190)})
+	if err := index.Write(io.Discard, Source{}); err == nil {
+		t.Error("expected an error for a point off the globe")
+	}
+}
+
+// FuzzDecode mutates index files, fixing up their checksum so that the
+// mutations reach the checks behind it, and queries and rewrites those that
+// decode.
+func FuzzDecode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) >= headerSize {
+			binary.LittleEndian.PutUint32(data[offChecksum:], checksum(data[:headerSize], data[headerSize:]))
+		}
+		file, err := Decode(data)
+		if err != nil {
+			return
+		}
+		for i := range file.Len() {
+			if p := file.store.place(int32(i)); !(math.Abs(p.Lat) <= 90) || !(math.Abs(p.Lon) <= 180) {
+				t.Fatalf("point %d out of range: %v, %v", i, p.Lat, p.Lon)
+			}
+		}
+		for _, query := range randomPath(3, 9) {
+			file.Nearest(query, 3)
+			file.Within(query, 1000)
+		}
+		file.InBox(geo.Box{MinLat: -10, MinLon: 170, MaxLat: 10, MaxLon: -170})
+
+		var first, second bytes.Buffer
+		if err := file.Write(&first, file.Source()); err != nil {
+			t.Fatalf("cannot write a decoded index: %v", err)
+		}
+		again, err := Decode(first.Bytes())
+		if err != nil {
+			t.Fatalf("cannot decode a written index: %v", err)
+		}
+		if err := again.Write(&second, again.Source()); err != nil || !bytes.Equal(first.Bytes(), second.Bytes()) {
+			t.Fatalf("index does not round trip: %v", err)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+// Package spatial answers nearest-neighbour, radius and bounding-box queries
+// over a set of places, using a vantage-point tree with a great-circle
+// formula as the metric. Indexes can be saved to disk and memory-mapped
+// back, so large places files need only be parsed once.
+package spatial
+
+import (
+	"cmp"
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// Metric returns the distance between two points. It must satisfy the
+// triangle inequality, which every great-circle formula does, but not the
+// approximate formulas.
+type Metric func(a, b geo.Point) float64
+
+// ErrNotMetric is returned when indexing with an approximate formula, which
+// need not satisfy the triangle inequality the tree relies on to prune its
+// searches.
+var ErrNotMetric = errors.New("formula is not a metric")
+
+// checkMetric returns ErrNotMetric when the calculator's formula cannot be
+// the metric of an index.
+func checkMetric(calculator *geo.Calculator) error {
+	if calculator.Formula.Approximate {
+		return fmt.Errorf("%w: %s is approximate", ErrNotMetric, calculator.Formula.Name)
+	}
+	return nil
+}
+
+// node is a vantage point of the tree. Points closer to the vantage point
+// than Threshold are in the Inside subtree, the others in the Outside
+// subtree. Subtrees are indexes into the node list, or -1 when empty. Child
+// nodes always come after their parent.
+type node struct {
+	Point     int32
+	Threshold float64
+	Inside    int32
+	Outside   int32
+}
+
+// store holds the points and nodes of an index, either in memory or in a
+// mapped index file.
+type store interface {
+	// location returns the coordinates of point i, without its name.
+	location(i int32) geo.Point
+	// place returns point i, with its name.
+	place(i int32) geo.Point
+	node(n int32) node
+}
+
+// memStore is a store backed by slices.
+type memStore struct {
+	points geo.Path
+	nodes  []node
+}
+
+func (s *memStore) location(i int32) geo.Point { return s.points[i] }
+func (s *memStore) place(i int32) geo.Point    { return s.points[i] }
+func (s *memStore) node(n int32) node          { return s.nodes[n] }
+
+// Index is a vantage-point tree over a fixed set of points. It is safe for
+// concurrent queries.
+type Index struct {
+	store  store
+	len    int
+	root   int32
+	metric Metric
+	// calculator is the source of metric, or nil for a custom metric.
+	calculator *geo.Calculator
+}
+
+// Neighbor is a point found by a query.
+type Neighbor struct {
+	// Index is the position of the point in the indexed path.
+	Index    int       `json:"index"`
+	Point    geo.Point `json:"point"`
+	Distance float64   `json:"distance"`
+}
+
+// NewIndex builds an index over points, measuring distances with the
+// calculator's formula and body. It fails with ErrNotMetric for approximate
+// formulas.
+func NewIndex(points geo.Path, calculator *geo.Calculator) (*Index, error) {
+	if err := checkMetric(calculator); err != nil {
+		return nil, err
+	}
+	index := NewIndexMetric(points, calculator.Distance)
+	index.calculator = calculator
+	return index, nil
+}
+
+// NewIndexMetric builds an index over points with an arbitrary metric.
+// Such an index cannot be saved, nor answer bounding-box queries.
+func NewIndexMetric(points geo.Path, metric Metric) *Index {
+	s := &memStore{points: points, nodes: make([]node, 0, len(points))}
+	items := make([]int32, len(points))
+	for i := range items {
+		items[i] = int32(i)
+	}
+	root := s.build(items, metric)
+	return &Index{store: s, len: len(points), root: root, metric: metric}
+}
+
+// Len returns the number of indexed points.
+func (ix *Index) Len() int {
+	return ix.len
+}
+
+// Calculator returns the calculator the index measures distances with, or
+// nil when it was built with a custom metric.
+func (ix *Index) Calculator() *geo.Calculator {
+	return ix.calculator
+}
+
+// candidate is a point considered while building the tree.
+type candidate struct {
+	item     int32
+	distance float64
+}
+
+// build adds a subtree for items and returns the index of its root node.
+func (s *memStore) build(items []int32, metric Metric) int32 {
+	if len(items) == 0 {
+		return -1
+	}
+
+	n := int32(len(s.nodes))
+	s.nodes = append(s.nodes, node{Point: items[0], Inside: -1, Outside: -1})
+	rest := items[1:]
+	if len(rest) == 0 {
+		return n
+	}
+
+	// sort the remaining points by their distance to the vantage point and
+	// split them at the median
+	vantage := s.points[items[0]]
+	byDistance := make([]candidate, len(rest))
+	for i, item := range rest {
+		byDistance[i] = candidate{item: item, distance: metric(vantage, s.points[item])}
+	}
+	slices.SortFunc(byDistance, func(a, b candidate) int {
+		return cmp.Compare(a.distance, b.distance)
+	})
+	for i := range byDistance {
+		rest[i] = byDistance[i].item
+	}
+
+	mid := len(rest) / 2
+	s.nodes[n].Threshold = byDistance[mid].distance
+	inside := s.build(rest[:mid], metric)
+	outside := s.build(rest[mid:], metric)
+	s.nodes[n].Inside = inside
+	s.nodes[n].Outside = outside
+	return n
+}
+
+// Nearest returns the k points closest to query, nearest first.
+func (ix *Index) Nearest(query geo.Point, k int) []Neighbor {
+	if k <= 0 || ix.root < 0 {
+		return nil
+	}
+
+	found := &neighborHeap{}
+	ix.nearest(ix.root, query, k, found)
+
+	neighbors := make([]Neighbor, found.Len())
+	for i := len(neighbors) - 1; i >= 0; i-- {
+		neighbors[i] = heap.Pop(found).(Neighbor)
+	}
+	for i := range neighbors {
+		neighbors[i].Point = ix.store.place(int32(neighbors[i].Index))
+	}
+	return neighbors
+}
+
+func (ix *Index) nearest(n int32, query geo.Point, k int, found *neighborHeap) {
+	if n < 0 {
+		return
+	}
+	nd := ix.store.node(n)
+	distance := ix.metric(query, ix.store.location(nd.Point))
+	if found.Len() < k {
+		heap.Push(found, Neighbor{Index: int(nd.Point), Distance: distance})
+	} else if distance < (*found)[0].Distance {
+		(*found)[0] = Neighbor{Index: int(nd.Point), Distance: distance}
+		heap.Fix(found, 0)
+	}
+
+	// visit the side the query falls in first, as it is more likely to
+	// shrink the search radius
+	first, second := nd.Inside, nd.Outside
+	if distance >= nd.Threshold {
+		first, second = second, first
+	}
+	for _, child := range []int32{first, second} {
+		tau := math.Inf(1)
+		if found.Len() == k {
+			tau = (*found)[0].Distance
+		}
+		if child == nd.Inside && distance-tau <= nd.Threshold ||
+			child == nd.Outside && distance+tau >= nd.Threshold {
+			ix.nearest(child, query, k, found)
+		}
+	}
+}
+
+// Within returns every point within radius of query, nearest first.
+func (ix *Index) Within(query geo.Point, radius float64) []Neighbor {
+	var neighbors []Neighbor
+	ix.within(ix.root, query, radius, &neighbors)
+	slices.SortFunc(neighbors, func(a, b Neighbor) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.Index, b.Index))
+	})
+	for i := range neighbors {
+		neighbors[i].Point = ix.store.place(int32(neighbors[i].Index))
+	}
+	return neighbors
+}
+
+func (ix *Index) within(n int32, query geo.Point, radius float64, neighbors *[]Neighbor) {
+	if n < 0 {
+		return
+	}
+	nd := ix.store.node(n)
+	distance := ix.metric(query, ix.store.location(nd.Point))
+	if distance <= radius {
+		*neighbors = append(*neighbors, Neighbor{Index: int(nd.Point), Distance: distance})
+	}
+	if distance-radius <= nd.Threshold {
+		ix.within(nd.Inside, query, radius, neighbors)
+	}
+	if distance+radius >= nd.Threshold {
+		ix.within(nd.Outside, query, radius, neighbors)
+	}
+}
+
+// InBox returns every point inside box, nearest to the box's center first.
+// It returns nil for an index built with a custom metric.
+func (ix *Index) InBox(box geo.Box) []Neighbor {
+	if ix.calculator == nil {
+		return nil
+	}
+
+	// search the cap around the center that covers the box, with a little
+	// slack for rounding, then keep the points that are really inside
+	radius := box.CentralAngle() * ix.calculator.Body.Radius * (1 + 1e-9)
+	candidates := ix.Within(box.Center(), radius)
+	neighbors := candidates[:0]
+	for _, neighbor := range candidates {
+		if box.Contains(neighbor.Point) {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	return neighbors
+}
+
+// neighborHeap is a max-heap of neighbours by distance, so the farthest of
+// the k best candidates is always at the top.
+type neighborHeap []Neighbor
+
+func (h neighborHeap) Len() int { return len(h) }
+func (h neighborHeap) Less(i, j int) bool {
+	return h[i].Distance > h[j].Distance || h[i].Distance == h[j].Distance && h[i].Index > h[j].Index
+}
+func (h neighborHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap) Push(x any)   { *h = append(*h, x.(Neighbor)) }
+func (h *neighborHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
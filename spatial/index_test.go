@@ -0,0 +1,112 @@
+package spatial
+
+import (
+	"cmp"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/dickeyy/go-distances/formulas"
+	"github.com/dickeyy/go-distances/geo"
+)
+
+// randomPath returns n reproducible points spread over the whole sphere.
+func randomPath(n int, seed uint64) geo.Path {
+	r := rand.New(rand.NewPCG(seed, seed))
+	path := make(geo.Path, n)
+	for i := range path {
+		path[i] = geo.NewPoint(r.Float64()*180-90, r.Float64()*360-180)
+	}
+	return path
+}
+
+func newTestIndex(t *testing.T, path geo.Path) (*Index, *geo.Calculator) {
+	t.Helper()
+	calculator, err := geo.NewCalculator("haversine", geo.Earth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := NewIndex(path, calculator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return index, calculator
+}
+
+// bruteForce returns every point of path sorted by distance to query.
+func bruteForce(path geo.Path, query geo.Point, calculator *geo.Calculator) []Neighbor {
+	neighbors := make([]Neighbor, len(path))
+	for i, point := range path {
+		neighbors[i] = Neighbor{Index: i, Point: point, Distance: calculator.Distance(query, point)}
+	}
+	slices.SortFunc(neighbors, func(a, b Neighbor) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.Index, b.Index))
+	})
+	return neighbors
+}
+
+func TestNearest(t *testing.T) {
+	path := randomPath(1000, 1)
+	index, calculator := newTestIndex(t, path)
+	if index.Len() != 1000 {
+		t.Fatalf("got %d points, want 1000", index.Len())
+	}
+
+	for _, query := range randomPath(50, 2) {
+		want := bruteForce(path, query, calculator)[:5]
+		got := index.Nearest(query, 5)
+		if !slices.Equal(got, want) {
+			t.Fatalf("Nearest(%v, 5):\ngot  %v\nwant %v", query, got, want)
+		}
+	}
+}
+
+func TestNearestMoreThanIndexed(t *testing.T) {
+	path := randomPath(3, 3)
+	index, _ := newTestIndex(t, path)
+	if got := index.Nearest(geo.NewPoint(0, 0), 10); len(got) != 3 {
+		t.Fatalf("got %d neighbours, want 3", len(got))
+	}
+	if got := index.Nearest(geo.NewPoint(0, 0), 0); got != nil {
+		t.Fatalf("got %v, want nil", got)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	path := randomPath(1000, 4)
+	index, calculator := newTestIndex(t, path)
+
+	for _, query := range randomPath(50, 5) {
+		var want []Neighbor
+		for _, neighbor := range bruteForce(path, query, calculator) {
+			if neighbor.Distance <= 1500 {
+				want = append(want, neighbor)
+			}
+		}
+		got := index.Within(query, 1500)
+		if !slices.Equal(got, want) {
+			t.Fatalf("Within(%v, 1500):\ngot  %v\nwant %v", query, got, want)
+		}
+	}
+}
+
+func TestEmptyIndex(t *testing.T) {
+	index, _ := newTestIndex(t, nil)
+	if got := index.Nearest(geo.NewPoint(0, 0), 1); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+	if got := index.Within(geo.NewPoint(0, 0), 1e9); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func TestNewIndexApproximate(t *testing.T) {
+	for _, f := range formulas.All() {
+		calculator, _ := geo.NewCalculator(f.Name, geo.Earth)
+		_, err := NewIndex(randomPath(10, 4), calculator)
+		if f.Approximate != errors.Is(err, ErrNotMetric) {
+			t.Errorf("NewIndex with %s: got %v", f.Name, err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package spatial
+
+import "os"
+
+// mapFile reads the named file into memory, on platforms without mmap.
+func mapFile(name string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
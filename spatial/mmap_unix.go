@@ -0,0 +1,34 @@
+//go:build unix
+
+package spatial
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the named file into memory read-only. The returned function
+// unmaps it.
+func mapFile(name string) ([]byte, func() error, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if size < headerSize || int64(int(size)) != size {
+		// too small to be an index, and mapping an empty file fails
+		return nil, nil, ErrNotIndex
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: "mmap", Path: name, Err: err}
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,135 @@
+// Package utils provides utility functions for the go-distances project,
+// including file parsing and degree-to-radian conversion.
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvColumns maps accepted CSV header names to the Point field they fill.
+var csvColumns = map[string]string{
+	"name":      "name",
+	"latitude":  "latitude",
+	"lat":       "latitude",
+	"longitude": "longitude",
+	"lon":       "longitude",
+	"lng":       "longitude",
+	"location":  "location",
+	"geohash":   "location",
+	"pluscode":  "location",
+	"plus_code": "location",
+	"utm":       "location",
+	"mgrs":      "location",
+	"elevation": "elevation",
+	"ele":       "elevation",
+	"altitude":  "elevation",
+	"alt":       "elevation",
+	"time":      "time",
+	"timestamp": "time",
+}
+
+// decodeCSV decodes a CSV document. The first record is a header naming the
+// columns; either "latitude" and "longitude" (or "lat" and "lon"/"lng") or
+// "location" (or "geohash", "pluscode", "plus_code", "utm" or "mgrs") are
+// required, and "name", "elevation" (or "ele", "altitude" or "alt") and
+// "time" (or "timestamp") are optional. Other columns are ignored.
+func decodeCSV(r io.Reader) (Data, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			err = errors.New("missing CSV header")
+		}
+		return Data{}, &FileError{Kind: ErrSyntax, Err: err}
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if field, ok := csvColumns[column]; ok {
+			columns[field] = i
+		}
+	}
+	_, hasLocation := columns["location"]
+	for _, field := range []string{"latitude", "longitude"} {
+		if _, ok := columns[field]; !ok && !hasLocation {
+			return Data{}, &FileError{Kind: ErrSyntax, Err: fmt.Errorf("CSV header has no %s column", field)}
+		}
+	}
+
+	data := Data{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Data{}, &FileError{Kind: ErrSyntax, Err: err}
+		}
+		data.Places = append(data.Places, Point{
+			Name:      csvField(record, columns, "name"),
+			Latitude:  csvField(record, columns, "latitude"),
+			Longitude: csvField(record, columns, "longitude"),
+			Location:  csvField(record, columns, "location"),
+			Elevation: csvField(record, columns, "elevation"),
+			Time:      csvField(record, columns, "time"),
+		})
+	}
+	return data, nil
+}
+
+// csvField returns the value of field in record, or "" when the column is
+// absent or the record is too short.
+func csvField(record []string, columns map[string]int, field string) string {
+	i, ok := columns[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// encodeCSV writes the places as CSV with a name, latitude and longitude
+// column, and a location, elevation or time column when any place has one.
+func encodeCSV(w io.Writer, data Data) error {
+	var hasLocation, hasElevation, hasTime bool
+	for _, place := range data.Places {
+		hasLocation = hasLocation || place.Location != ""
+		hasElevation = hasElevation || place.Elevation != ""
+		hasTime = hasTime || place.Time != ""
+	}
+
+	writer := csv.NewWriter(w)
+	header := []string{"name", "latitude", "longitude"}
+	if hasLocation {
+		header = append(header, "location")
+	}
+	if hasElevation {
+		header = append(header, "elevation")
+	}
+	if hasTime {
+		header = append(header, "time")
+	}
+	writer.Write(header)
+	for _, place := range data.Places {
+		record := []string{place.Name, place.Latitude, place.Longitude}
+		if hasLocation {
+			record = append(record, place.Location)
+		}
+		if hasElevation {
+			record = append(record, place.Elevation)
+		}
+		if hasTime {
+			record = append(record, place.Time)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
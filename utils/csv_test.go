@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCSV(t *testing.T) {
+	input := "Name, Lat, Lng, notes\nNew York, 40.7128, -74.0060, home\nLos Angeles,34.0522,-118.2437\n"
+	data, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error decoding CSV: %v", err)
+	}
+	want := []Point{
+		{Name: "New York", Latitude: "40.7128", Longitude: "-74.0060"},
+		{Name: "Los Angeles", Latitude: "34.0522", Longitude: "-118.2437"},
+	}
+	if len(data.Places) != len(want) {
+		t.Fatalf("Expected %d places, got %d", len(want), len(data.Places))
+	}
+	for i := range want {
+		if data.Places[i] != want[i] {
+			t.Errorf("place %d: got %+v, want %+v", i, data.Places[i], want[i])
+		}
+	}
+}
+
+func TestDecodeCSVMissingColumn(t *testing.T) {
+	_, err := Decode(strings.NewReader("name,latitude\nNew York,40.7128\n"))
+	if !errors.Is(err, ErrSyntax) {
+		t.Fatalf("Expected ErrSyntax, got %v", err)
+	}
+}
+
+func TestDecodeCSVShortRecord(t *testing.T) {
+	_, _, _, _, _, err := ParseReader(strings.NewReader("latitude,longitude\n40.7128\n"))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "longitude" {
+		t.Fatalf("Expected *ParseError for longitude, got %v", err)
+	}
+}
+
+func TestDecodeCSVGeohash(t *testing.T) {
+	data, err := Decode(strings.NewReader("name,geohash\nSan Francisco,9q8yy\nNew York,dr5ru\n"))
+	if err != nil {
+		t.Fatalf("Error decoding CSV: %v", err)
+	}
+	if len(data.Places) != 2 || data.Places[1] != (Point{Name: "New York", Location: "dr5ru"}) {
+		t.Errorf("Unexpected places: %+v", data.Places)
+	}
+}
+
+func TestDecodeCSVElevation(t *testing.T) {
+	data, err := Decode(strings.NewReader("name,lat,lon,ele\nSummit,46.8523,-121.7603,4392\nTrailhead,46.7865,-121.7353,\n"))
+	if err != nil {
+		t.Fatalf("Error decoding CSV: %v", err)
+	}
+	if len(data.Places) != 2 || data.Places[0].Elevation != "4392" || data.Places[1].Elevation != "" {
+		t.Errorf("Unexpected places: %+v", data.Places)
+	}
+}
+
+func TestDecodeCSVTime(t *testing.T) {
+	data, err := Decode(strings.NewReader("lat,lon,timestamp\n46.8523,-121.7603,2024-05-01T09:30:00Z\n"))
+	if err != nil {
+		t.Fatalf("Error decoding CSV: %v", err)
+	}
+	if len(data.Places) != 1 || data.Places[0].Time != "2024-05-01T09:30:00Z" {
+		t.Errorf("Unexpected places: %+v", data.Places)
+	}
+}
+
+func FuzzDecodeCSV(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte) {
+		checkDecoded(t, document, FormatCSV)
+	})
+}
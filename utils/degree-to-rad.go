@@ -8,3 +8,8 @@ import "math"
 func DegreeToRad(degrees float64) float64 {
 	return degrees * math.Pi / 180
 }
+
+// RadToDegree converts an angle from radians to degrees.
+func RadToDegree(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
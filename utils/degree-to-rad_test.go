@@ -12,3 +12,12 @@ func TestDegreeToRad(t *testing.T) {
 		t.Errorf("got %f, want %f", rad, want)
 	}
 }
+
+func TestRadToDegree(t *testing.T) {
+	rad := 1.5707963267948966
+	deg := RadToDegree(rad)
+	want := 90.0
+	if deg != want {
+		t.Errorf("got %f, want %f", deg, want)
+	}
+}
@@ -0,0 +1,77 @@
+// Package utils provides utility functions for the go-distances project,
+// including file parsing and degree-to-radian conversion.
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors describing the kind of problem ParseFile ran into. They are
+// never returned on their own; use errors.Is to test for them.
+var (
+	// ErrNotFound means the places file does not exist.
+	ErrNotFound = errors.New("places file not found")
+	// ErrRead means the places file exists but could not be read.
+	ErrRead = errors.New("places file could not be read")
+	// ErrSyntax means the places file is not valid JSON or does not match
+	// the expected document layout.
+	ErrSyntax = errors.New("places file is malformed")
+	// ErrInvalidCoordinate means a place has a latitude or longitude that
+	// could not be parsed.
+	ErrInvalidCoordinate = errors.New("invalid coordinate")
+	// ErrOutOfRange means a place has a latitude outside [-90, 90], a
+	// longitude outside [-180, 180] or an elevation that is not finite.
+	ErrOutOfRange = errors.New("coordinate out of range")
+)
+
+// FileError records a problem with a places file as a whole.
+type FileError struct {
+	Path string // path of the file, empty when not read from disk
+	Kind error  // one of ErrNotFound, ErrRead or ErrSyntax
+	Err  error  // underlying cause
+}
+
+func (e *FileError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s: %v: %v", e.Path, e.Kind, e.Err)
+}
+
+// Unwrap returns both the kind and the underlying cause, so errors.Is and
+// errors.As match either of them.
+func (e *FileError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// ParseError records a problem with a single place in a places file, or
+// with a field of the document itself, such as its reference location, when
+// Index is negative.
+type ParseError struct {
+	Index int    // zero-based position of the place in the file, or -1
+	Name  string // name of the place, possibly empty
+	Field string // name of the offending field, e.g. "latitude"
+	Value string // raw value of the offending field
+	Err   error  // underlying cause
+}
+
+func (e *ParseError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("invalid %s %q: %v", e.Field, e.Value, e.Err)
+	}
+	place := fmt.Sprintf("place %d", e.Index+1)
+	if e.Name != "" {
+		place += fmt.Sprintf(" (%q)", e.Name)
+	}
+	return fmt.Sprintf("%s: invalid %s %q: %v", place, e.Field, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying cause, along with ErrInvalidCoordinate for a
+// latitude or longitude.
+func (e *ParseError) Unwrap() []error {
+	if e.Field == "latitude" || e.Field == "longitude" {
+		return []error{ErrInvalidCoordinate, e.Err}
+	}
+	return []error{e.Err}
+}
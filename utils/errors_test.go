@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+	"io/fs"
+	"strconv"
+	"testing"
+)
+
+func TestParseErrorMessage(t *testing.T) {
+	err := &ParseError{Index: 6, Name: "Denver", Field: "latitude", Value: "abc", Err: strconv.ErrSyntax}
+	want := `place 7 ("Denver"): invalid latitude "abc": invalid syntax`
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestParseErrorMessageNoName(t *testing.T) {
+	err := &ParseError{Index: 0, Field: "longitude", Value: "", Err: strconv.ErrSyntax}
+	want := `place 1: invalid longitude "": invalid syntax`
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestParseErrorMessageDocument(t *testing.T) {
+	err := &ParseError{Index: -1, Field: "reference", Value: "nowhere!", Err: strconv.ErrSyntax}
+	want := `invalid reference "nowhere!": invalid syntax`
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	var err error = &ParseError{Index: 0, Field: "latitude", Err: strconv.ErrRange}
+	if !errors.Is(err, ErrInvalidCoordinate) {
+		t.Errorf("expected error to match ErrInvalidCoordinate")
+	}
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("expected error to match strconv.ErrRange")
+	}
+	for _, field := range []string{"elevation", "time", "location", "reference"} {
+		err = &ParseError{Index: 0, Field: field, Err: strconv.ErrSyntax}
+		if errors.Is(err, ErrInvalidCoordinate) || !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("%s: expected error to match strconv.ErrSyntax only", field)
+		}
+	}
+}
+
+func TestFileErrorUnwrap(t *testing.T) {
+	var err error = &FileError{Path: "places.json", Kind: ErrNotFound, Err: fs.ErrNotExist}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected error to match ErrNotFound")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected error to match fs.ErrNotExist")
+	}
+	if errors.Is(err, ErrSyntax) {
+		t.Errorf("did not expect error to match ErrSyntax")
+	}
+	want := "places.json: places file not found: file does not exist"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
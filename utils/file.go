@@ -4,58 +4,196 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
 	"os"
 	"strconv"
+	"time"
 )
 
+// DefaultEarthRadius is the mean radius of the Earth in kilometres. It is
+// used when a places document does not specify a radius, which is always the
+// case for CSV and GPX input.
+const DefaultEarthRadius = 6371.0
+
 type Point struct {
-	Name      string `json:"name"`
-	Latitude  string `json:"latitude"`
-	Longitude string `json:"longitude"`
+	Name      string `json:"name,omitempty"`
+	Latitude  string `json:"latitude,omitempty"`
+	Longitude string `json:"longitude,omitempty"`
+	// Location is an encoded position, such as a geohash, used when
+	// Latitude and Longitude are both empty. See ParseLocation.
+	Location string `json:"location,omitempty"`
+	// Elevation is the optional height of the place above the surface, in
+	// metres. See ParseElevation.
+	Elevation string `json:"elevation,omitempty"`
+	// Time is the optional time the place was visited, in RFC 3339 format
+	// such as "2024-05-01T09:30:00Z". See ParseTime.
+	Time string `json:"time,omitempty"`
 }
 
 type Data struct {
 	Places      []Point `json:"places"`
-	EarthRadius float64 `json:"earthRadius"`
-	Formula     string  `json:"formula"`
+	EarthRadius float64 `json:"earthRadius,omitempty"`
+	// Unit names the unit of EarthRadius, such as "km" or "mi". Elevations,
+	// which are in metres, can only be converted into a known unit.
+	Unit    string `json:"unit,omitempty"`
+	Formula string `json:"formula,omitempty"`
+	// Reference is a location, such as a full plus code, near which the
+	// short plus codes of the places are recovered.
+	Reference string `json:"reference,omitempty"`
+	// Format is the format the document was decoded from.
+	Format Format `json:"-"`
 }
 
-// ParseFile reads a JSON file containing geographical point data and returns
-// the parsed information.
+// ParseFile reads a file containing geographical point data and returns
+// the parsed information. The file may be in any format understood by
+// Decode.
 //
 // It returns the number of points, slices of latitudes and longitudes,
 // the Earth's radius, and the formula to use for distance calculation.
+//
+// Errors are either a *FileError, when the file cannot be opened or decoded,
+// or a *ParseError, when a single place has a bad coordinate.
 func ParseFile(filePath string) (numPoints int, latitudes []float64, longitudes []float64, earthRadius float64, formula string, err error) {
+	data, err := DecodeFile(filePath)
+	if err != nil {
+		return
+	}
+	return parseData(data)
+}
+
+// DecodeFile reads a places document from the named file, detecting its
+// format like Decode. Errors are reported as a *FileError carrying the path.
+func DecodeFile(filePath string) (Data, error) {
 	// read the file
 	file, err := os.Open(filePath)
 	if err != nil {
-		return
+		kind := ErrRead
+		if errors.Is(err, fs.ErrNotExist) {
+			kind = ErrNotFound
+		}
+		return Data{}, &FileError{Path: filePath, Kind: kind, Err: err}
 	}
 	defer file.Close()
 
-	// decode the file
-	decoder := json.NewDecoder(file)
-	data := Data{}
-	err = decoder.Decode(&data)
+	data, err := Decode(file)
+	var fileErr *FileError
+	if errors.As(err, &fileErr) {
+		fileErr.Path = filePath
+	}
+	return data, err
+}
+
+// ParseReader reads geographical point data from r and returns the parsed
+// information, exactly like ParseFile.
+func ParseReader(r io.Reader) (numPoints int, latitudes []float64, longitudes []float64, earthRadius float64, formula string, err error) {
+	data, err := Decode(r)
 	if err != nil {
 		return
 	}
+	return parseData(data)
+}
 
+// parseData converts the places of a decoded document into coordinates.
+func parseData(data Data) (numPoints int, latitudes []float64, longitudes []float64, earthRadius float64, formula string, err error) {
 	numPoints = len(data.Places)
-	latitudes = make([]float64, numPoints)
-	longitudes = make([]float64, numPoints)
-	for i := range numPoints {
-		latitudes[i], err = strconv.ParseFloat(data.Places[i].Latitude, 64)
+	latitudes, longitudes, err = data.ParsePlaces()
+	if err != nil {
+		return
+	}
+	earthRadius, formula = data.Defaults()
+
+	return
+}
+
+// ParsePlaces parses the coordinates of every place in the document,
+// recovering short plus codes near the document's Reference. Failures are
+// reported as a *ParseError, with an Index of -1 for an invalid Reference.
+func (d Data) ParsePlaces() (latitudes, longitudes []float64, err error) {
+	var near *reference
+	if d.Reference != "" {
+		lat, lon, err := ParseLocation(d.Reference)
 		if err != nil {
-			return
+			return nil, nil, &ParseError{Index: -1, Field: "reference", Value: d.Reference, Err: err}
 		}
-		longitudes[i], err = strconv.ParseFloat(data.Places[i].Longitude, 64)
+		near = &reference{latitude: lat, longitude: lon}
+	}
+
+	latitudes = make([]float64, len(d.Places))
+	longitudes = make([]float64, len(d.Places))
+	for i, place := range d.Places {
+		latitudes[i], longitudes[i], err = parsePlace(i, place, near)
 		if err != nil {
-			return
+			return nil, nil, err
 		}
 	}
-	earthRadius = data.EarthRadius
-	formula = data.Formula
+	return latitudes, longitudes, nil
+}
+
+// ParsePlace parses the coordinates of place, which is at the given
+// zero-based index in its document. Places without a latitude and longitude
+// are located by their Location instead, which cannot be a short plus code.
+// Failures are reported as a *ParseError.
+func ParsePlace(index int, place Point) (latitude, longitude float64, err error) {
+	return parsePlace(index, place, nil)
+}
+
+func parsePlace(index int, place Point, near *reference) (latitude, longitude float64, err error) {
+	if place.Latitude == "" && place.Longitude == "" && place.Location != "" {
+		latitude, longitude, err = parseLocation(place.Location, near)
+		if err != nil {
+			err = &ParseError{Index: index, Name: place.Name, Field: "location", Value: place.Location, Err: err}
+		}
+		return
+	}
+	latitude, err = parseCoordinate(index, place, "latitude", place.Latitude)
+	if err != nil {
+		return
+	}
+	longitude, err = parseCoordinate(index, place, "longitude", place.Longitude)
+	return
+}
+
+// ParseElevation parses the elevation of place, which is at the given
+// zero-based index in its document. It returns nil when the place has no
+// elevation. Failures are reported as a *ParseError.
+func ParseElevation(index int, place Point) (*float64, error) {
+	if place.Elevation == "" {
+		return nil, nil
+	}
+	elevation, err := parseCoordinate(index, place, "elevation", place.Elevation)
+	if err != nil {
+		return nil, err
+	}
+	return &elevation, nil
+}
+
+// ParseTime parses the time of place, which is at the given zero-based index
+// in its document. It returns nil when the place has no time. Failures are
+// reported as a *ParseError.
+func ParseTime(index int, place Point) (*time.Time, error) {
+	if place.Time == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, place.Time)
+	if err != nil {
+		return nil, &ParseError{Index: index, Name: place.Name, Field: "time", Value: place.Time, Err: err}
+	}
+	return &t, nil
+}
+
+// Defaults returns the radius and formula of the document, filling in the
+// defaults for missing values and normalising formula aliases.
+func (d Data) Defaults() (earthRadius float64, formula string) {
+	earthRadius = d.EarthRadius
+	formula = d.Formula
+
+	// if the radius is missing, default to the Earth's mean radius in km
+	if earthRadius == 0 {
+		earthRadius = DefaultEarthRadius
+	}
 
 	// if formula does not exist, default to Vincenty
 	if formula == "" {
@@ -66,3 +204,39 @@ func ParseFile(filePath string) (numPoints int, latitudes []float64, longitudes
 
 	return
 }
+
+// decodeJSON decodes a places document in the project's own JSON layout.
+func decodeJSON(r io.Reader) (Data, error) {
+	data := Data{}
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return Data{}, &FileError{Kind: ErrSyntax, Err: err}
+	}
+	return data, nil
+}
+
+// coordinateLimits are the largest magnitudes of the fields that are angles,
+// in degrees. Other fields need only be finite.
+var coordinateLimits = map[string]float64{"latitude": 90, "longitude": 180}
+
+// parseCoordinate parses a single coordinate of the place at index i,
+// wrapping any failure in a *ParseError. Values outside the limits of the
+// field, and infinities and NaNs, fail with ErrOutOfRange.
+func parseCoordinate(i int, place Point, field, value string) (float64, error) {
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			err = numErr.Err
+		}
+		return 0, &ParseError{Index: i, Name: place.Name, Field: field, Value: value, Err: err}
+	}
+	limit, ok := coordinateLimits[field]
+	if !ok {
+		limit = math.MaxFloat64
+	}
+	// written so that NaN fails too
+	if !(math.Abs(coordinate) <= limit) {
+		return 0, &ParseError{Index: i, Name: place.Name, Field: field, Value: value, Err: ErrOutOfRange}
+	}
+	return coordinate, nil
+}
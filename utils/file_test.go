@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"errors"
+	"math"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 )
 
 var validFile2PointsVincenty = `{
@@ -189,8 +193,12 @@ func TestParseFileEmptyFormula(t *testing.T) {
 func TestParseFileNoFile(t *testing.T) {
 	filePath := "nonexistent.json"
 	_, _, _, _, _, err := ParseFile(filePath)
-	if err == nil {
-		t.Fatalf("Expected error, got nil")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != filePath {
+		t.Fatalf("Expected *FileError for %s, got %v", filePath, err)
 	}
 }
 
@@ -203,8 +211,8 @@ func TestParseFileInvalidJSON(t *testing.T) {
 
 	// read the test data from the file
 	_, _, _, _, _, err = ParseFile(filePath)
-	if err == nil {
-		t.Fatalf("Expected error, got nil")
+	if !errors.Is(err, ErrSyntax) {
+		t.Fatalf("Expected ErrSyntax, got %v", err)
 	}
 }
 
@@ -217,8 +225,12 @@ func TestParseFileInvalidLatitude(t *testing.T) {
 
 	// read the test data from the file
 	_, _, _, _, _, err = ParseFile(filePath)
-	if err == nil {
-		t.Fatalf("Expected error, got nil")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %v", err)
+	}
+	if parseErr.Index != 0 || parseErr.Name != "New York" || parseErr.Field != "latitude" || parseErr.Value != "invalid" {
+		t.Fatalf("Unexpected error details: %+v", parseErr)
 	}
 }
 
@@ -231,7 +243,100 @@ func TestParseFileInvalidLongitude(t *testing.T) {
 
 	// read the test data from the file
 	_, _, _, _, _, err = ParseFile(filePath)
-	if err == nil {
-		t.Fatalf("Expected error, got nil")
+	if !errors.Is(err, ErrInvalidCoordinate) {
+		t.Fatalf("Expected ErrInvalidCoordinate, got %v", err)
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "longitude" {
+		t.Fatalf("Expected *ParseError for longitude, got %v", err)
+	}
+}
+
+func TestParseElevation(t *testing.T) {
+	elevation, err := ParseElevation(0, Point{Elevation: "-12.5"})
+	if err != nil || elevation == nil || *elevation != -12.5 {
+		t.Errorf("ParseElevation = %v, %v, want -12.5", elevation, err)
+	}
+	if elevation, err := ParseElevation(0, Point{}); err != nil || elevation != nil {
+		t.Errorf("ParseElevation without elevation = %v, %v, want nil", elevation, err)
+	}
+
+	_, err = ParseElevation(3, Point{Name: "Summit", Elevation: "high"})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "elevation" || parseErr.Index != 3 {
+		t.Errorf("Expected *ParseError for elevation, got %v", err)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	visited, err := ParseTime(0, Point{Time: "2024-05-01T11:30:00+02:00"})
+	if err != nil || visited == nil || !visited.Equal(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("ParseTime = %v, %v, want 09:30 UTC", visited, err)
+	}
+	if visited, err := ParseTime(0, Point{}); err != nil || visited != nil {
+		t.Errorf("ParseTime without time = %v, %v, want nil", visited, err)
 	}
+
+	_, err = ParseTime(2, Point{Name: "Summit", Time: "noon"})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "time" || parseErr.Index != 2 {
+		t.Errorf("Expected *ParseError for time, got %v", err)
+	}
+}
+
+func TestParsePlaceOutOfRange(t *testing.T) {
+	tests := []struct {
+		place Point
+		field string
+	}{
+		{Point{Latitude: "90.5", Longitude: "0"}, "latitude"},
+		{Point{Latitude: "NaN", Longitude: "0"}, "latitude"},
+		{Point{Latitude: "0", Longitude: "-180.25"}, "longitude"},
+		{Point{Latitude: "0", Longitude: "+Inf"}, "longitude"},
+	}
+	for _, tt := range tests {
+		_, _, err := ParsePlace(0, tt.place)
+		var parseErr *ParseError
+		if !errors.Is(err, ErrOutOfRange) || !errors.As(err, &parseErr) || parseErr.Field != tt.field {
+			t.Errorf("ParsePlace(%+v): expected ErrOutOfRange for %s, got %v", tt.place, tt.field, err)
+		}
+	}
+	if _, _, err := ParsePlace(0, Point{Latitude: "-90", Longitude: "180"}); err != nil {
+		t.Errorf("Unexpected error at the limits: %v", err)
+	}
+	if _, err := ParseElevation(0, Point{Elevation: "-Inf"}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange for an infinite elevation, got %v", err)
+	}
+}
+
+func FuzzParsePlace(f *testing.F) {
+	f.Fuzz(func(t *testing.T, latitude, longitude, elevation string) {
+		place := Point{Latitude: latitude, Longitude: longitude, Elevation: elevation}
+		lat, lon, err := ParsePlace(0, place)
+		if err != nil {
+			return
+		}
+		if !(math.Abs(lat) <= 90) || !(math.Abs(lon) <= 180) {
+			t.Fatalf("ParsePlace(%q, %q) = %v, %v, out of range", latitude, longitude, lat, lon)
+		}
+		// the writers format coordinates like this
+		written := Point{Latitude: strconv.FormatFloat(lat, 'f', -1, 64), Longitude: strconv.FormatFloat(lon, 'f', -1, 64)}
+		if lat2, lon2, err := ParsePlace(0, written); err != nil || lat2 != lat || lon2 != lon {
+			t.Fatalf("%+v parsed as %v, %v, %v, want %v, %v", written, lat2, lon2, err, lat, lon)
+		}
+
+		value, err := ParseElevation(0, place)
+		if err != nil || value == nil {
+			return
+		}
+		if math.IsNaN(*value) || math.IsInf(*value, 0) {
+			t.Fatalf("ParseElevation(%q) = %v", elevation, *value)
+		}
+	})
+}
+
+func FuzzDecodeJSON(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte) {
+		checkDecoded(t, document, FormatJSON)
+	})
 }
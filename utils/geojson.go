@@ -0,0 +1,275 @@
+// Package utils provides utility functions for the go-distances project,
+// including file parsing and degree-to-radian conversion.
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// geoJSONGeometry is a GeoJSON geometry object. Coordinates are decoded
+// lazily because their shape depends on the geometry type.
+type geoJSONGeometry struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometries  []geoJSONGeometry `json:"geometries"`
+}
+
+type geoJSONFeature struct {
+	Type       string           `json:"type"`
+	Geometry   *geoJSONGeometry `json:"geometry"`
+	Properties map[string]any   `json:"properties"`
+}
+
+// geoJSONDocument is any top-level GeoJSON object. The earthRadius, unit,
+// formula and reference foreign members mirror the fields of the JSON places
+// layout.
+type geoJSONDocument struct {
+	geoJSONGeometry
+	Features    []geoJSONFeature `json:"features"`
+	Geometry    *geoJSONGeometry `json:"geometry"`
+	Properties  map[string]any   `json:"properties"`
+	EarthRadius float64          `json:"earthRadius"`
+	Unit        string           `json:"unit"`
+	Formula     string           `json:"formula"`
+	Reference   string           `json:"reference"`
+}
+
+// decodeGeoJSON decodes a GeoJSON document. Point, MultiPoint and LineString
+// geometries become places, in document order; the "name" property of a
+// feature becomes the name of its places. The "time" property of a feature
+// is the time of its place, and the "coordTimes" array property holds the
+// times of the positions of its MultiPoint or LineString. A feature without a
+// geometry is a place when it has a "location" property, such as a geohash.
+func decodeGeoJSON(r io.Reader) (Data, error) {
+	doc := geoJSONDocument{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return Data{}, &FileError{Kind: ErrSyntax, Err: err}
+	}
+
+	data := Data{EarthRadius: doc.EarthRadius, Unit: doc.Unit, Formula: doc.Formula, Reference: doc.Reference}
+	var err error
+	switch doc.Type {
+	case "FeatureCollection":
+		for _, feature := range doc.Features {
+			data.Places, err = appendFeature(data.Places, feature)
+			if err != nil {
+				break
+			}
+		}
+	case "Feature":
+		data.Places, err = appendFeature(data.Places, geoJSONFeature{Geometry: doc.Geometry, Properties: doc.Properties})
+	default:
+		data.Places, err = appendGeometry(data.Places, doc.geoJSONGeometry, "")
+	}
+	if err != nil {
+		return Data{}, &FileError{Kind: ErrSyntax, Err: err}
+	}
+	return data, nil
+}
+
+func appendFeature(places []Point, feature geoJSONFeature) ([]Point, error) {
+	name, _ := feature.Properties["name"].(string)
+	timestamp, _ := feature.Properties["time"].(string)
+	if feature.Geometry == nil {
+		if location, ok := feature.Properties["location"].(string); ok {
+			places = append(places, Point{Name: name, Location: location, Time: timestamp})
+		}
+		return places, nil
+	}
+
+	start := len(places)
+	places, err := appendGeometry(places, *feature.Geometry, name)
+	if err != nil {
+		return nil, err
+	}
+	added := places[start:]
+	if times, ok := feature.Properties["coordTimes"].([]any); ok {
+		if len(times) != len(added) {
+			return nil, fmt.Errorf("feature has %d coordTimes for %d positions", len(times), len(added))
+		}
+		for i, t := range times {
+			added[i].Time, _ = t.(string)
+		}
+	} else if len(added) == 1 {
+		added[0].Time = timestamp
+	}
+	return places, nil
+}
+
+func appendGeometry(places []Point, geometry geoJSONGeometry, name string) ([]Point, error) {
+	switch geometry.Type {
+	case "Point":
+		var position []float64
+		if err := json.Unmarshal(geometry.Coordinates, &position); err != nil {
+			return nil, fmt.Errorf("invalid Point coordinates: %w", err)
+		}
+		point, err := geoJSONPoint(position, name)
+		if err != nil {
+			return nil, err
+		}
+		return append(places, point), nil
+	case "MultiPoint", "LineString":
+		var positions [][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &positions); err != nil {
+			return nil, fmt.Errorf("invalid %s coordinates: %w", geometry.Type, err)
+		}
+		for i, position := range positions {
+			pointName := name
+			if name != "" && len(positions) > 1 {
+				pointName = fmt.Sprintf("%s #%d", name, i+1)
+			}
+			point, err := geoJSONPoint(position, pointName)
+			if err != nil {
+				return nil, err
+			}
+			places = append(places, point)
+		}
+		return places, nil
+	case "GeometryCollection":
+		var err error
+		for _, g := range geometry.Geometries {
+			places, err = appendGeometry(places, g, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return places, nil
+	}
+	return nil, fmt.Errorf("unsupported GeoJSON geometry %q", geometry.Type)
+}
+
+// geoJSONPoint converts a GeoJSON position, which is ordered longitude,
+// latitude and optionally elevation, into a Point.
+func geoJSONPoint(position []float64, name string) (Point, error) {
+	if len(position) < 2 {
+		return Point{}, fmt.Errorf("position %v needs at least two numbers", position)
+	}
+	point := Point{
+		Name:      name,
+		Latitude:  strconv.FormatFloat(position[1], 'f', -1, 64),
+		Longitude: strconv.FormatFloat(position[0], 'f', -1, 64),
+	}
+	if len(position) > 2 {
+		point.Elevation = strconv.FormatFloat(position[2], 'f', -1, 64)
+	}
+	return point, nil
+}
+
+// geoJSONOutput is the GeoJSON document written by encodeGeoJSON.
+type geoJSONOutput struct {
+	Type        string                 `json:"type"`
+	Features    []geoJSONOutputFeature `json:"features"`
+	EarthRadius float64                `json:"earthRadius,omitempty"`
+	Unit        string                 `json:"unit,omitempty"`
+	Formula     string                 `json:"formula,omitempty"`
+	Reference   string                 `json:"reference,omitempty"`
+}
+
+type geoJSONOutputFeature struct {
+	Type       string                 `json:"type"`
+	Properties map[string]any         `json:"properties"`
+	Geometry   *geoJSONOutputGeometry `json:"geometry"`
+}
+
+type geoJSONOutputGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// encodeGeoJSON writes the places as a FeatureCollection. A track of
+// unnamed places with coordinates becomes a single LineString feature, with
+// its times in a "coordTimes" property; any other places become one Point
+// feature each, or a feature with a "location" property and no geometry for
+// places without coordinates.
+func encodeGeoJSON(w io.Writer, data Data) error {
+	doc := geoJSONOutput{
+		Type:        "FeatureCollection",
+		Features:    []geoJSONOutputFeature{},
+		EarthRadius: data.EarthRadius,
+		Unit:        data.Unit,
+		Formula:     data.Formula,
+		Reference:   data.Reference,
+	}
+
+	track := len(data.Places) >= 2
+	for _, place := range data.Places {
+		if place.Name != "" || place.Latitude == "" || place.Longitude == "" {
+			track = false
+		}
+	}
+	if track {
+		coordinates := make([][]float64, len(data.Places))
+		var times []any
+		for i, place := range data.Places {
+			position, err := geoJSONPosition(i, place)
+			if err != nil {
+				return err
+			}
+			coordinates[i] = position
+			if place.Time != "" && times == nil {
+				times = make([]any, len(data.Places))
+			}
+		}
+		properties := map[string]any{}
+		if times != nil {
+			for i, place := range data.Places {
+				times[i] = place.Time
+			}
+			properties["coordTimes"] = times
+		}
+		doc.Features = append(doc.Features, geoJSONOutputFeature{
+			Type:       "Feature",
+			Properties: properties,
+			Geometry:   &geoJSONOutputGeometry{Type: "LineString", Coordinates: coordinates},
+		})
+	} else {
+		for i, place := range data.Places {
+			feature := geoJSONOutputFeature{Type: "Feature", Properties: map[string]any{}}
+			if place.Name != "" {
+				feature.Properties["name"] = place.Name
+			}
+			if place.Time != "" {
+				feature.Properties["time"] = place.Time
+			}
+			if place.Latitude == "" && place.Longitude == "" && place.Location != "" {
+				feature.Properties["location"] = place.Location
+			} else {
+				position, err := geoJSONPosition(i, place)
+				if err != nil {
+					return err
+				}
+				feature.Geometry = &geoJSONOutputGeometry{Type: "Point", Coordinates: position}
+			}
+			doc.Features = append(doc.Features, feature)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// geoJSONPosition converts the coordinates and elevation of the place at
+// index i into a GeoJSON position, ordered longitude, latitude and elevation.
+func geoJSONPosition(i int, place Point) ([]float64, error) {
+	latitude, err := parseCoordinate(i, place, "latitude", place.Latitude)
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := parseCoordinate(i, place, "longitude", place.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	position := []float64{longitude, latitude}
+	if place.Elevation != "" {
+		elevation, err := parseCoordinate(i, place, "elevation", place.Elevation)
+		if err != nil {
+			return nil, err
+		}
+		position = append(position, elevation)
+	}
+	return position, nil
+}
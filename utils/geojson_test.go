@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var geoJSONFeatureCollection = `{
+		"type": "FeatureCollection",
+		"earthRadius": 3959,
+		"formula": "haversine",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "New York"},
+				"geometry": {"type": "Point", "coordinates": [-74.006, 40.7128]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Trail"},
+				"geometry": {"type": "LineString", "coordinates": [[-118.2437, 34.0522, 89], [-87.6298, 41.8781]]}
+			},
+			{
+				"type": "Feature",
+				"properties": null,
+				"geometry": null
+			}
+		]
+	}`
+
+func TestDecodeGeoJSON(t *testing.T) {
+	data, err := DecodeFormat(strings.NewReader(geoJSONFeatureCollection), FormatGeoJSON)
+	if err != nil {
+		t.Fatalf("Error decoding GeoJSON: %v", err)
+	}
+	want := []Point{
+		{Name: "New York", Latitude: "40.7128", Longitude: "-74.006"},
+		{Name: "Trail #1", Latitude: "34.0522", Longitude: "-118.2437", Elevation: "89"},
+		{Name: "Trail #2", Latitude: "41.8781", Longitude: "-87.6298"},
+	}
+	if len(data.Places) != len(want) {
+		t.Fatalf("Expected %d places, got %d", len(want), len(data.Places))
+	}
+	for i := range want {
+		if data.Places[i] != want[i] {
+			t.Errorf("place %d: got %+v, want %+v", i, data.Places[i], want[i])
+		}
+	}
+	if data.EarthRadius != 3959 || data.Formula != "haversine" {
+		t.Errorf("Unexpected radius or formula: %f %s", data.EarthRadius, data.Formula)
+	}
+}
+
+func TestDecodeGeoJSONGeometry(t *testing.T) {
+	data, err := Decode(strings.NewReader(`{"type": "MultiPoint", "coordinates": [[1, 2], [3, 4]]}`))
+	if err != nil {
+		t.Fatalf("Error decoding GeoJSON: %v", err)
+	}
+	if len(data.Places) != 2 || data.Places[1].Latitude != "4" || data.Places[1].Longitude != "3" {
+		t.Fatalf("Unexpected places: %+v", data.Places)
+	}
+}
+
+func TestDecodeGeoJSONUnsupportedGeometry(t *testing.T) {
+	_, err := Decode(strings.NewReader(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 1], [0, 0]]]}`))
+	if !errors.Is(err, ErrSyntax) {
+		t.Fatalf("Expected ErrSyntax, got %v", err)
+	}
+}
+
+func TestDecodeGeoJSONShortPosition(t *testing.T) {
+	_, err := Decode(strings.NewReader(`{"type": "Point", "coordinates": [1]}`))
+	if !errors.Is(err, ErrSyntax) {
+		t.Fatalf("Expected ErrSyntax, got %v", err)
+	}
+}
+
+func TestDecodeGeoJSONLocationProperty(t *testing.T) {
+	input := `{"type": "Feature", "properties": {"name": "San Francisco", "location": "9q8yy"}, "geometry": null}`
+	data, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error decoding GeoJSON: %v", err)
+	}
+	if len(data.Places) != 1 || data.Places[0] != (Point{Name: "San Francisco", Location: "9q8yy"}) {
+		t.Errorf("Unexpected places: %+v", data.Places)
+	}
+}
+
+func TestDecodeGeoJSONTimes(t *testing.T) {
+	input := `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"time": "2024-05-01T09:00:00Z"}, "geometry": {"type": "Point", "coordinates": [1, 2]}},
+		{"type": "Feature", "properties": {"coordTimes": ["2024-05-01T09:10:00Z", "2024-05-01T09:20:00Z"]},
+			"geometry": {"type": "LineString", "coordinates": [[3, 4], [5, 6]]}}
+	]}`
+	data, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error decoding GeoJSON: %v", err)
+	}
+	want := []string{"2024-05-01T09:00:00Z", "2024-05-01T09:10:00Z", "2024-05-01T09:20:00Z"}
+	if len(data.Places) != len(want) {
+		t.Fatalf("Expected %d places, got %d", len(want), len(data.Places))
+	}
+	for i := range want {
+		if data.Places[i].Time != want[i] {
+			t.Errorf("place %d: got time %q, want %q", i, data.Places[i].Time, want[i])
+		}
+	}
+
+	input = `{"type": "Feature", "properties": {"coordTimes": ["2024-05-01T09:10:00Z"]},
+		"geometry": {"type": "LineString", "coordinates": [[3, 4], [5, 6]]}}`
+	if _, err := Decode(strings.NewReader(input)); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Expected ErrSyntax for mismatched coordTimes, got %v", err)
+	}
+}
+
+func FuzzDecodeGeoJSON(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte) {
+		checkDecoded(t, document, FormatGeoJSON)
+	})
+}
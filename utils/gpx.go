@@ -0,0 +1,99 @@
+// Package utils provides utility functions for the go-distances project,
+// including file parsing and degree-to-radian conversion.
+package utils
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+type gpxPoint struct {
+	Latitude  string `xml:"lat,attr"`
+	Longitude string `xml:"lon,attr"`
+	Elevation string `xml:"ele,omitempty"`
+	Time      string `xml:"time,omitempty"`
+	Name      string `xml:"name,omitempty"`
+}
+
+type gpxDocument struct {
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// gpxTrack is the GPX 1.1 document written by encodeGPX: a single track with
+// a single segment.
+type gpxTrack struct {
+	XMLName xml.Name   `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version string     `xml:"version,attr"`
+	Creator string     `xml:"creator,attr"`
+	Points  []gpxPoint `xml:"trk>trkseg>trkpt"`
+}
+
+// decodeGPX decodes a GPX 1.1 document. Waypoints come first, followed by the
+// points of every route and then every track segment, each in document order.
+func decodeGPX(r io.Reader) (Data, error) {
+	doc := gpxDocument{}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Data{}, &FileError{Kind: ErrSyntax, Err: err}
+	}
+
+	data := Data{}
+	points := doc.Waypoints
+	for _, route := range doc.Routes {
+		points = append(points, route.Points...)
+	}
+	for _, track := range doc.Tracks {
+		for _, segment := range track.Segments {
+			points = append(points, segment.Points...)
+		}
+	}
+	for _, point := range points {
+		data.Places = append(data.Places, Point{
+			Name:      strings.TrimSpace(point.Name),
+			Latitude:  strings.TrimSpace(point.Latitude),
+			Longitude: strings.TrimSpace(point.Longitude),
+			Elevation: strings.TrimSpace(point.Elevation),
+			Time:      strings.TrimSpace(point.Time),
+		})
+	}
+	return data, nil
+}
+
+// encodeGPX writes the places as the points of a single GPX track. Places
+// without a latitude and longitude, which GPX cannot locate, are reported as
+// a *ParseError.
+func encodeGPX(w io.Writer, data Data) error {
+	doc := gpxTrack{Version: "1.1", Creator: "go-distances", Points: make([]gpxPoint, len(data.Places))}
+	for i, place := range data.Places {
+		if place.Latitude == "" || place.Longitude == "" {
+			return &ParseError{Index: i, Name: place.Name, Field: "latitude", Value: place.Latitude, Err: errors.New("GPX points need a latitude and longitude")}
+		}
+		doc.Points[i] = gpxPoint{
+			Latitude:  place.Latitude,
+			Longitude: place.Longitude,
+			Elevation: place.Elevation,
+			Time:      place.Time,
+			Name:      place.Name,
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
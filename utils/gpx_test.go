@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+var gpxDocumentAll = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+	<wpt lat="40.7128" lon="-74.0060"><name>New York</name></wpt>
+	<rte>
+		<rtept lat="34.0522" lon="-118.2437"><name>Los Angeles</name></rtept>
+	</rte>
+	<trk>
+		<trkseg>
+			<trkpt lat="41.8781" lon="-87.6298"><ele> 181.5 </ele><time>2024-05-01T09:30:00Z</time></trkpt>
+		</trkseg>
+	</trk>
+</gpx>`
+
+func TestDecodeGPX(t *testing.T) {
+	data, err := Decode(strings.NewReader(gpxDocumentAll))
+	if err != nil {
+		t.Fatalf("Error decoding GPX: %v", err)
+	}
+	want := []Point{
+		{Name: "New York", Latitude: "40.7128", Longitude: "-74.0060"},
+		{Name: "Los Angeles", Latitude: "34.0522", Longitude: "-118.2437"},
+		{Name: "", Latitude: "41.8781", Longitude: "-87.6298", Elevation: "181.5", Time: "2024-05-01T09:30:00Z"},
+	}
+	if len(data.Places) != len(want) {
+		t.Fatalf("Expected %d places, got %d", len(want), len(data.Places))
+	}
+	for i := range want {
+		if data.Places[i] != want[i] {
+			t.Errorf("place %d: got %+v, want %+v", i, data.Places[i], want[i])
+		}
+	}
+}
+
+func FuzzDecodeGPX(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte) {
+		checkDecoded(t, document, FormatGPX)
+	})
+}
@@ -0,0 +1,140 @@
+// Package utils provides utility functions for the go-distances project,
+// including file parsing and degree-to-radian conversion.
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dickeyy/go-distances/geohash"
+	"github.com/dickeyy/go-distances/olc"
+	"github.com/dickeyy/go-distances/utm"
+)
+
+var (
+	// ErrUnknownLocation is wrapped by errors for location strings that no
+	// supported encoding recognises.
+	ErrUnknownLocation = errors.New("unknown location encoding")
+	// ErrNoReference is wrapped by errors for short plus codes parsed
+	// without a reference location.
+	ErrNoReference = errors.New("short plus code without a reference location")
+)
+
+// reference is a location that short plus codes are recovered near.
+type reference struct {
+	latitude, longitude float64
+}
+
+// locationEncoding is one encoding of location strings.
+type locationEncoding struct {
+	// scheme is the prefix, followed by a colon, that selects the encoding
+	// explicitly, as in "mgrs:31udq4825111932".
+	scheme string
+	// detect reports whether an unprefixed string is in the encoding.
+	detect func(s string) bool
+	// parse decodes s. near is nil when there is no reference location.
+	parse func(s string, near *reference) (latitude, longitude float64, err error)
+}
+
+// locationEncodings are tried in order by ParseLocation. MGRS references are
+// only detected in upper case, as they are conventionally written, so that
+// lower-case geohashes such as "31udq" are not mistaken for them.
+var locationEncodings = []locationEncoding{
+	{scheme: "pluscode", detect: isPlusCode, parse: parsePlusCode},
+	{scheme: "utm", detect: isUTM, parse: parseUTM},
+	{scheme: "mgrs", detect: isMGRS, parse: parseMGRS},
+	{scheme: "geohash", detect: geohash.Valid, parse: parseGeohash},
+}
+
+// ParseLocation decodes a location string into the latitude and longitude
+// of its center. Supported encodings are plus codes ("849VCWC8+R9"), UTM
+// coordinates ("10S 582120 4141100"), MGRS references ("10SEG8212041100")
+// and geohashes ("9q8yy"). A scheme prefix such as "geohash:" selects the
+// encoding when it is ambiguous. Short plus codes need a reference location;
+// see ParseLocationNear.
+func ParseLocation(s string) (latitude, longitude float64, err error) {
+	return parseLocation(s, nil)
+}
+
+// ParseLocationNear is like ParseLocation, but also accepts short plus codes
+// such as "CWC8+R9", recovering the nearest match to the given latitude and
+// longitude.
+func ParseLocationNear(s string, latitude, longitude float64) (float64, float64, error) {
+	return parseLocation(s, &reference{latitude: latitude, longitude: longitude})
+}
+
+func parseLocation(s string, near *reference) (latitude, longitude float64, err error) {
+	s = strings.TrimSpace(s)
+	if scheme, rest, found := strings.Cut(s, ":"); found {
+		for _, encoding := range locationEncodings {
+			if strings.EqualFold(scheme, encoding.scheme) {
+				return encoding.parse(strings.TrimSpace(rest), near)
+			}
+		}
+	}
+	for _, encoding := range locationEncodings {
+		if encoding.detect(s) {
+			return encoding.parse(s, near)
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: %q", ErrUnknownLocation, s)
+}
+
+// isPlusCode accepts any string with a plus code separator.
+func isPlusCode(s string) bool {
+	return strings.IndexByte(s, olc.Separator) >= 0
+}
+
+func parsePlusCode(s string, near *reference) (latitude, longitude float64, err error) {
+	code := s
+	if olc.IsShort(s) {
+		if near == nil {
+			return 0, 0, fmt.Errorf("%w: %q", ErrNoReference, s)
+		}
+		if code, err = olc.RecoverNearest(s, near.latitude, near.longitude); err != nil {
+			return 0, 0, err
+		}
+	}
+	area, err := olc.Decode(code)
+	if err != nil {
+		return 0, 0, err
+	}
+	latitude, longitude = area.Center()
+	return latitude, longitude, nil
+}
+
+// isUTM accepts strings like "10S 582120 4141100"; no other encoding has
+// spaces between numbers.
+func isUTM(s string) bool {
+	_, err := utm.Parse(s)
+	return err == nil
+}
+
+func parseUTM(s string, _ *reference) (latitude, longitude float64, err error) {
+	c, err := utm.Parse(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.LatLon()
+}
+
+func isMGRS(s string) bool {
+	if strings.ToUpper(s) != s {
+		return false
+	}
+	_, err := utm.ParseMGRS(s)
+	return err == nil
+}
+
+func parseMGRS(s string, _ *reference) (latitude, longitude float64, err error) {
+	c, err := utm.ParseMGRS(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.LatLon()
+}
+
+func parseGeohash(s string, _ *reference) (latitude, longitude float64, err error) {
+	return geohash.Decode(s)
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseLocationGeohash(t *testing.T) {
+	lat, lon, err := ParseLocation(" 9q8yy ")
+	if err != nil {
+		t.Fatalf("Error parsing geohash: %v", err)
+	}
+	if math.Abs(lat-37.77) > 0.03 || math.Abs(lon-(-122.41)) > 0.03 {
+		t.Errorf("Expected San Francisco, got %f, %f", lat, lon)
+	}
+}
+
+func TestParseLocationUnknown(t *testing.T) {
+	for _, location := range []string{"", "9q8a", "40.7,-74.0"} {
+		if _, _, err := ParseLocation(location); !errors.Is(err, ErrUnknownLocation) {
+			t.Errorf("ParseLocation(%q) error = %v, want ErrUnknownLocation", location, err)
+		}
+	}
+}
+
+func TestParsePlaceLocation(t *testing.T) {
+	input := `{"places": [{"name": "San Francisco", "location": "9q8yy"}, {"name": "New York", "location": "dr5ru"}]}`
+	numPoints, latitudes, longitudes, _, _, err := ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error parsing places: %v", err)
+	}
+	if numPoints != 2 || math.Abs(latitudes[1]-40.73) > 0.03 || math.Abs(longitudes[1]-(-73.99)) > 0.03 {
+		t.Errorf("Unexpected places: %v %v", latitudes, longitudes)
+	}
+}
+
+func TestParsePlaceCoordinatesBeforeLocation(t *testing.T) {
+	lat, lon, err := ParsePlace(0, Point{Latitude: "1", Longitude: "2", Location: "9q8yy"})
+	if err != nil || lat != 1 || lon != 2 {
+		t.Errorf("ParsePlace = %f, %f, %v, want 1, 2", lat, lon, err)
+	}
+}
+
+func TestParsePlaceInvalidLocation(t *testing.T) {
+	_, _, err := ParsePlace(2, Point{Name: "Nowhere", Location: "9q8a"})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Field != "location" || parseErr.Index != 2 {
+		t.Fatalf("Expected *ParseError for location, got %v", err)
+	}
+	if errors.Is(err, ErrInvalidCoordinate) || !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected ErrUnknownLocation only, got %v", err)
+	}
+}
+
+func TestParseLocationPlusCode(t *testing.T) {
+	lat, lon, err := ParseLocation("849VCWC8+R9")
+	if err != nil {
+		t.Fatalf("Error parsing plus code: %v", err)
+	}
+	if math.Abs(lat-37.4220625) > 1e-9 || math.Abs(lon-(-122.0840625)) > 1e-9 {
+		t.Errorf("Expected Mountain View, got %f, %f", lat, lon)
+	}
+
+	if _, _, err := ParseLocation("CWC8+R9"); !errors.Is(err, ErrNoReference) {
+		t.Errorf("Expected ErrNoReference for a short code, got %v", err)
+	}
+	if _, _, err := ParseLocation("849VCWC8+R"); err == nil || errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected an invalid plus code error, got %v", err)
+	}
+}
+
+func TestParseLocationNear(t *testing.T) {
+	lat, lon, err := ParseLocationNear("CWC8+R9", 37.4, -122.1)
+	if err != nil {
+		t.Fatalf("Error parsing short plus code: %v", err)
+	}
+	if math.Abs(lat-37.4220625) > 1e-9 || math.Abs(lon-(-122.0840625)) > 1e-9 {
+		t.Errorf("Expected Mountain View, got %f, %f", lat, lon)
+	}
+}
+
+func TestParsePlacesReference(t *testing.T) {
+	input := `{"reference": "849V0000+", "places": [{"location": "CWC8+R9"}, {"location": "849VCWC8+R9"}]}`
+	_, latitudes, longitudes, _, _, err := ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error parsing places: %v", err)
+	}
+	if latitudes[0] != latitudes[1] || longitudes[0] != longitudes[1] {
+		t.Errorf("Short and full codes differ: %v %v", latitudes, longitudes)
+	}
+
+	_, _, _, _, _, err = ParseReader(strings.NewReader(`{"reference": "nowhere!", "places": []}`))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Index != -1 || parseErr.Field != "reference" || !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected *ParseError for an invalid reference, got %v", err)
+	}
+}
+
+func TestParseLocationGrid(t *testing.T) {
+	for _, location := range []string{"31U 448252 5411933", "31UDQ4825111932", "31U DQ 48251 11932", "mgrs:31udq4825111932"} {
+		lat, lon, err := ParseLocation(location)
+		if err != nil {
+			t.Errorf("ParseLocation(%q): %v", location, err)
+			continue
+		}
+		if math.Abs(lat-48.8582) > 1e-4 || math.Abs(lon-2.2945) > 1e-4 {
+			t.Errorf("ParseLocation(%q) = %f, %f, want the Eiffel Tower", location, lat, lon)
+		}
+	}
+}
+
+func TestParseLocationScheme(t *testing.T) {
+	// a lower-case string that could be an MGRS reference is a geohash
+	// unless the scheme says otherwise
+	geohashLat, _, err := ParseLocation("31udq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgrsLat, _, err := ParseLocation("MGRS:31udq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if explicit, _, _ := ParseLocation("geohash:31UDQ"); explicit != geohashLat {
+		t.Errorf("geohash: scheme gave %f, want %f", explicit, geohashLat)
+	}
+	if math.Abs(mgrsLat-48.9) > 0.5 || math.Abs(geohashLat-mgrsLat) < 1 {
+		t.Errorf("Unexpected latitudes: geohash %f, MGRS %f", geohashLat, mgrsLat)
+	}
+
+	if _, _, err := ParseLocation("utm:9q8yy"); err == nil {
+		t.Error("Expected an error for a geohash with the utm: scheme")
+	}
+}
+
+func FuzzParseLocation(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string, latitude, longitude float64) {
+		if lat, lon, err := ParseLocation(s); err == nil && (!(math.Abs(lat) <= 90) || !(math.Abs(lon) <= 180)) {
+			t.Fatalf("ParseLocation(%q) = %v, %v, out of range", s, lat, lon)
+		}
+		if !(math.Abs(latitude) <= 90) || !(math.Abs(longitude) <= 180) {
+			return
+		}
+		if lat, lon, err := ParseLocationNear(s, latitude, longitude); err == nil && (!(math.Abs(lat) <= 90) || !(math.Abs(lon) <= 180)) {
+			t.Fatalf("ParseLocationNear(%q, %v, %v) = %v, %v, out of range", s, latitude, longitude, lat, lon)
+		}
+	})
+}
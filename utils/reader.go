@@ -0,0 +1,118 @@
+// Package utils provides utility functions for the go-distances project,
+// including file parsing and degree-to-radian conversion.
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format identifies the encoding of a places document.
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatGeoJSON Format = "geojson"
+	FormatCSV     Format = "csv"
+	FormatGPX     Format = "gpx"
+)
+
+// ErrUnknownFormat is wrapped by errors for documents whose format cannot be
+// determined or is not supported.
+var ErrUnknownFormat = errors.New("unknown places format")
+
+// geoJSONTypes are the values of the "type" member that mark a JSON document
+// as GeoJSON rather than the project's own layout.
+var geoJSONTypes = []string{
+	"FeatureCollection", "Feature", "Point", "MultiPoint", "LineString",
+	"MultiLineString", "Polygon", "MultiPolygon", "GeometryCollection",
+}
+
+// DetectFormat guesses the format of a places document from its contents.
+//
+// Documents starting with '{' are JSON, or GeoJSON when their top-level
+// "type" member is a GeoJSON type. Documents starting with '<' are GPX when
+// they contain a <gpx> element. Anything else that is not empty is assumed to
+// be CSV.
+func DetectFormat(data []byte) (Format, error) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+	if len(trimmed) == 0 {
+		return "", &FileError{Kind: ErrSyntax, Err: errors.New("empty document")}
+	}
+
+	switch trimmed[0] {
+	case '{':
+		probe := struct {
+			Type string `json:"type"`
+		}{}
+		// a document that fails to decode here is reported by decodeJSON
+		json.Unmarshal(trimmed, &probe)
+		for _, t := range geoJSONTypes {
+			if probe.Type == t {
+				return FormatGeoJSON, nil
+			}
+		}
+		return FormatJSON, nil
+	case '<':
+		if bytes.Contains(trimmed, []byte("<gpx")) {
+			return FormatGPX, nil
+		}
+		return "", &FileError{Kind: ErrSyntax, Err: fmt.Errorf("%w: XML document is not GPX", ErrUnknownFormat)}
+	default:
+		return FormatCSV, nil
+	}
+}
+
+// ParseFormat converts a format name such as "geojson" into a Format.
+// Names are case-insensitive.
+func ParseFormat(name string) (Format, error) {
+	format := Format(strings.ToLower(name))
+	switch format {
+	case FormatJSON, FormatGeoJSON, FormatCSV, FormatGPX:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// Decode reads a places document from r, detecting its format with
+// DetectFormat. The whole of r is read into memory.
+func Decode(r io.Reader) (Data, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Data{}, &FileError{Kind: ErrRead, Err: err}
+	}
+
+	format, err := DetectFormat(data)
+	if err != nil {
+		return Data{}, err
+	}
+	return DecodeFormat(bytes.NewReader(data), format)
+}
+
+// DecodeFormat reads a places document in the given format from r. The
+// places and the reference location are not parsed: see Data.ParsePlaces.
+func DecodeFormat(r io.Reader, format Format) (Data, error) {
+	var data Data
+	var err error
+	switch format {
+	case FormatJSON:
+		data, err = decodeJSON(r)
+	case FormatGeoJSON:
+		data, err = decodeGeoJSON(r)
+	case FormatCSV:
+		data, err = decodeCSV(r)
+	case FormatGPX:
+		data, err = decodeGPX(r)
+	default:
+		return Data{}, &FileError{Kind: ErrSyntax, Err: fmt.Errorf("%w: %q", ErrUnknownFormat, format)}
+	}
+	if err != nil {
+		return Data{}, err
+	}
+	data.Format = format
+	return data, nil
+}
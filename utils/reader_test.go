@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		data string
+		want Format
+	}{
+		{validFile2PointsVincenty, FormatJSON},
+		{`{"type": "FeatureCollection", "features": []}`, FormatGeoJSON},
+		{"\ufeff  {\"type\": \"Point\", \"coordinates\": [1, 2]}", FormatGeoJSON},
+		{`<?xml version="1.0"?><gpx version="1.1"></gpx>`, FormatGPX},
+		{"name,latitude,longitude\n", FormatCSV},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("DetectFormat(%q): %v", tt.data, err)
+		}
+		if got != tt.want {
+			t.Errorf("DetectFormat(%q) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDetectFormatEmpty(t *testing.T) {
+	_, err := DetectFormat([]byte("  \n"))
+	if !errors.Is(err, ErrSyntax) {
+		t.Fatalf("Expected ErrSyntax, got %v", err)
+	}
+}
+
+func TestDetectFormatUnknownXML(t *testing.T) {
+	_, err := DetectFormat([]byte("<kml></kml>"))
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("GeoJSON")
+	if err != nil || format != FormatGeoJSON {
+		t.Fatalf("got %s, %v, want %s", format, err, FormatGeoJSON)
+	}
+	if _, err := ParseFormat("kml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestDecodeFormatUnknown(t *testing.T) {
+	_, err := DecodeFormat(strings.NewReader(""), Format("kml"))
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	numPoints, latitudes, longitudes, earthRadius, formula, err := ParseReader(strings.NewReader(validFile2PointsVincenty))
+	if err != nil {
+		t.Fatalf("Error parsing reader: %v", err)
+	}
+	if numPoints != 2 || latitudes[1] != 34.0522 || longitudes[1] != -118.2437 {
+		t.Fatalf("Unexpected points: %d %v %v", numPoints, latitudes, longitudes)
+	}
+	if earthRadius != 6371.0 || formula != "vincenty" {
+		t.Fatalf("Unexpected radius or formula: %f %s", earthRadius, formula)
+	}
+}
+
+func TestParseReaderDefaultRadius(t *testing.T) {
+	_, _, _, earthRadius, _, err := ParseReader(strings.NewReader("lat,lon\n1,2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing reader: %v", err)
+	}
+	if earthRadius != DefaultEarthRadius {
+		t.Fatalf("Expected earth radius %f, got %f", DefaultEarthRadius, earthRadius)
+	}
+}
+
+func TestParseReaderNoPath(t *testing.T) {
+	_, _, _, _, _, err := ParseReader(strings.NewReader(invalidJSONFile))
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != "" {
+		t.Fatalf("Expected *FileError without a path, got %v", err)
+	}
+}
+
+// formats are the formats every decoded document is written back in by
+// checkDecoded.
+var formats = []Format{FormatJSON, FormatGeoJSON, FormatCSV, FormatGPX}
+
+// checkDecoded decodes document as format and, when it is accepted, checks
+// that every coordinate that parses is in range and that writing the places
+// is stable: a document written in its own format reads back and writes out
+// unchanged, and one written in another format does so after the first
+// trip has dropped what that format cannot hold.
+func checkDecoded(t *testing.T, document []byte, format Format) {
+	data, err := DecodeFormat(bytes.NewReader(document), format)
+	if err != nil {
+		return
+	}
+	checkRange(t, data)
+
+	for _, f := range formats {
+		var first bytes.Buffer
+		if err := Encode(&first, data, f); err != nil {
+			// only places that cannot be written in the format fail
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Encode(%s): %v", f, err)
+			}
+			continue
+		}
+		second := reencode(t, first.Bytes(), f)
+		if f == format && !bytes.Equal(first.Bytes(), second) {
+			t.Fatalf("%s does not round trip:\nfirst  %q\nsecond %q", f, first.Bytes(), second)
+		}
+		if third := reencode(t, second, f); !bytes.Equal(second, third) {
+			t.Fatalf("%s is not stable:\nsecond %q\nthird  %q", f, second, third)
+		}
+	}
+}
+
+// reencode decodes a document written by Encode and writes it again.
+func reencode(t *testing.T, document []byte, format Format) []byte {
+	t.Helper()
+	data, err := Decode(bytes.NewReader(document))
+	if err != nil {
+		t.Fatalf("cannot read back %s %q: %v", format, document, err)
+	}
+	if data.Format != format {
+		t.Fatalf("%s %q read back as %s", format, document, data.Format)
+	}
+	var b bytes.Buffer
+	if err := Encode(&b, data, format); err != nil {
+		t.Fatalf("cannot write back %s %q: %v", format, document, err)
+	}
+	return b.Bytes()
+}
+
+// checkRange checks that every parsed coordinate of data is in range.
+func checkRange(t *testing.T, data Data) {
+	t.Helper()
+	latitudes, longitudes, err := data.ParsePlaces()
+	if err == nil {
+		for i := range latitudes {
+			if !(math.Abs(latitudes[i]) <= 90) || !(math.Abs(longitudes[i]) <= 180) {
+				t.Fatalf("place %d parsed out of range: %v, %v", i, latitudes[i], longitudes[i])
+			}
+		}
+	}
+	for i, place := range data.Places {
+		if elevation, err := ParseElevation(i, place); err == nil && elevation != nil && (math.IsNaN(*elevation) || math.IsInf(*elevation, 0)) {
+			t.Fatalf("place %d has elevation %v", i, *elevation)
+		}
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte) {
+		format, err := DetectFormat(document)
+		if err != nil {
+			return
+		}
+		checkDecoded(t, document, format)
+
+		_, latitudes, longitudes, _, _, err := ParseReader(bytes.NewReader(document))
+		if err == nil {
+			for i := range latitudes {
+				if !(math.Abs(latitudes[i]) <= 90) || !(math.Abs(longitudes[i]) <= 180) {
+					t.Fatalf("ParseReader: place %d out of range: %v, %v", i, latitudes[i], longitudes[i])
+				}
+			}
+		}
+	})
+}
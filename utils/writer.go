@@ -0,0 +1,37 @@
+// Package utils provides utility functions for the go-distances project,
+// including file parsing and degree-to-radian conversion.
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Encode writes data to w as a places document in the given format, which
+// Decode reads back. JSON keeps every field of the document. GeoJSON keeps
+// the radius, formula and reference as foreign members, but CSV and GPX
+// cannot, just as they cannot when they are read. Coordinates and
+// elevations that are not numbers are reported as a *ParseError.
+func Encode(w io.Writer, data Data, format Format) error {
+	switch format {
+	case FormatJSON:
+		return encodeJSON(w, data)
+	case FormatGeoJSON:
+		return encodeGeoJSON(w, data)
+	case FormatCSV:
+		return encodeCSV(w, data)
+	case FormatGPX:
+		return encodeGPX(w, data)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+func encodeJSON(w io.Writer, data Data) error {
+	if data.Places == nil {
+		data.Places = []Point{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(data)
+}
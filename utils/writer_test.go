@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var encodeData = Data{
+	Places: []Point{
+		{Name: "Trailhead", Latitude: "46.7865", Longitude: "-121.7353", Elevation: "1647", Time: "2024-05-01T06:00:00Z"},
+		{Name: "Summit", Latitude: "46.8523", Longitude: "-121.7603", Elevation: "4392", Time: "2024-05-01T13:40:00Z"},
+		{Latitude: "46.8356", Longitude: "-121.7323"},
+	},
+	EarthRadius: 3959,
+	Unit:        "mi",
+	Formula:     "haversine",
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatGeoJSON, FormatCSV, FormatGPX} {
+		var b strings.Builder
+		if err := Encode(&b, encodeData, format); err != nil {
+			t.Fatalf("Encode(%s): %v", format, err)
+		}
+		data, err := Decode(strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatalf("Decode(%s): %v\n%s", format, err, b.String())
+		}
+		if !reflect.DeepEqual(data.Places, encodeData.Places) {
+			t.Errorf("%s places: got %+v, want %+v", format, data.Places, encodeData.Places)
+		}
+		if format == FormatJSON || format == FormatGeoJSON {
+			if data.EarthRadius != 3959 || data.Unit != "mi" || data.Formula != "haversine" {
+				t.Errorf("%s: unexpected radius, unit or formula: %f %s %s", format, data.EarthRadius, data.Unit, data.Formula)
+			}
+		}
+	}
+}
+
+func TestEncodeGeoJSONTrack(t *testing.T) {
+	track := Data{Places: []Point{
+		{Latitude: "1", Longitude: "2", Time: "2024-05-01T06:00:00Z"},
+		{Latitude: "3", Longitude: "4", Elevation: "5", Time: "2024-05-01T06:01:00Z"},
+	}}
+	var b strings.Builder
+	if err := Encode(&b, track, FormatGeoJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"LineString"`) || !strings.Contains(b.String(), `"coordTimes"`) {
+		t.Errorf("Expected a LineString with coordTimes, got:\n%s", b.String())
+	}
+	data, err := Decode(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data.Places, track.Places) {
+		t.Errorf("got %+v, want %+v", data.Places, track.Places)
+	}
+}
+
+func TestEncodeLocation(t *testing.T) {
+	data := Data{Places: []Point{{Name: "San Francisco", Location: "9q8yy"}}}
+	var b strings.Builder
+	if err := Encode(&b, data, FormatGeoJSON); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(strings.NewReader(b.String()))
+	if err != nil || !reflect.DeepEqual(decoded.Places, data.Places) {
+		t.Errorf("got %+v, %v, want %+v", decoded.Places, err, data.Places)
+	}
+
+	var parseErr *ParseError
+	if err := Encode(&b, data, FormatGPX); !errors.As(err, &parseErr) {
+		t.Errorf("Expected *ParseError for a GPX point without coordinates, got %v", err)
+	}
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	var b strings.Builder
+	data := Data{Places: []Point{{Latitude: "north", Longitude: "1"}}}
+	var parseErr *ParseError
+	if err := Encode(&b, data, FormatGeoJSON); !errors.As(err, &parseErr) || parseErr.Field != "latitude" {
+		t.Errorf("Expected *ParseError for latitude, got %v", err)
+	}
+	if err := Encode(&b, data, Format("kml")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
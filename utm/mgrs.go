@@ -0,0 +1,162 @@
+// Package utm converts between WGS 84 latitude/longitude and the Universal
+// Transverse Mercator (UTM) and Universal Polar Stereographic (UPS) grids,
+// and the Military Grid Reference System (MGRS) references built on them.
+package utm
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxPrecision is the number of digits of each of the easting and
+	// northing of an MGRS reference to the metre.
+	MaxPrecision = 5
+
+	square = 100000 // size of an MGRS grid square, in metres
+)
+
+var (
+	// utmColumns are the column letters of UTM squares, in sets cycling
+	// with the zone.
+	utmColumns = [3]string{"ABCDEFGH", "JKLMNPQR", "STUVWXYZ"}
+	// utmRows are the row letters of UTM squares, repeating every 2000 km
+	// and offset by five in even zones.
+	utmRows = [2]string{"ABCDEFGHJKLMNPQRSTUV", "FGHJKLMNPQRSTUVABCDE"}
+
+	// upsColumns, upsMinEasting, upsRows and upsMinNorthing describe the UPS
+	// squares, indexed by band A, B, Y, Z and by hemisphere south, north.
+	upsColumns     = [4]string{"JKLPQRSTUXYZ", "ABCFGHJKLPQR", "RSTUXYZ", "ABCFGHJ"}
+	upsMinEasting  = [4]int{8, 20, 18, 20}
+	upsRows        = [2]string{"ABCDEFGHJKLMNPQRSTUVWXYZ", "ABCDEFGHJKLMNP"}
+	upsMinNorthing = [2]int{8, 13}
+)
+
+// MGRS returns the grid reference of the coordinate with the given number of
+// digits for each of the easting and northing, from 0 (100 km) to
+// MaxPrecision (1 m), like "32UMU9160834764". Digits are truncated, so the
+// reference names the square the coordinate is in.
+func (c Coordinate) MGRS(precision int) (string, error) {
+	if err := c.validate(); err != nil {
+		return "", err
+	}
+	precision = max(0, min(precision, MaxPrecision))
+
+	column, row := int(math.Floor(c.Easting/square)), int(math.Floor(c.Northing/square))
+	var letters string
+	if c.IsUPS() {
+		band := strings.IndexByte("ABYZ", c.Band)
+		hemisphere := band / 2
+		column -= upsMinEasting[band]
+		row -= upsMinNorthing[hemisphere]
+		if column < 0 || column >= len(upsColumns[band]) || row < 0 || row >= len(upsRows[hemisphere]) {
+			return "", fmt.Errorf("%w: %v is outside the MGRS squares of band %c", ErrOutOfRange, c, c.Band)
+		}
+		letters = string([]byte{c.Band, upsColumns[band][column], upsRows[hemisphere][row]})
+	} else {
+		if column < 1 || column > 8 {
+			return "", fmt.Errorf("%w: %v is outside the MGRS squares of zone %d", ErrOutOfRange, c, c.Zone)
+		}
+		letters = fmt.Sprintf("%02d%c%c%c", c.Zone, c.Band,
+			utmColumns[(c.Zone-1)%3][column-1], utmRows[(c.Zone-1)%2][row%20])
+	}
+
+	scale := math.Pow(10, float64(MaxPrecision-precision))
+	easting := int(math.Floor(math.Mod(c.Easting, square) / scale))
+	northing := int(math.Floor(math.Mod(c.Northing, square) / scale))
+	if precision == 0 {
+		return letters, nil
+	}
+	return fmt.Sprintf("%s%0*d%0*d", letters, precision, easting, precision, northing), nil
+}
+
+// MGRSFromLatLon returns the grid reference of a point, in degrees. See
+// Coordinate.MGRS.
+func MGRSFromLatLon(lat, lon float64, precision int) (string, error) {
+	c, err := FromLatLon(lat, lon)
+	if err != nil {
+		return "", err
+	}
+	return c.MGRS(precision)
+}
+
+var (
+	mgrsUTMPattern = regexp.MustCompile(`^(\d{1,2})([C-HJ-NP-X])([A-HJ-NP-Z])([A-HJ-NP-V])(\d*)$`)
+	mgrsUPSPattern = regexp.MustCompile(`^([ABYZ])([A-HJ-NP-Z])([A-HJ-NP-Z])(\d*)$`)
+)
+
+// ParseMGRS parses a grid reference such as "32UMU9160834764" or
+// "32U MU 91608 34764", returning the coordinate of the center of the square
+// it names. References are case-insensitive.
+func ParseMGRS(s string) (Coordinate, error) {
+	compact := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: MGRS reference %q %s", ErrInvalid, s, reason)
+	}
+
+	var c Coordinate
+	var digits string
+	if m := mgrsUTMPattern.FindStringSubmatch(compact); m != nil {
+		c.Zone, _ = strconv.Atoi(m[1])
+		c.Band = m[2][0]
+		if c.Zone < 1 || c.Zone > 60 {
+			return Coordinate{}, invalid("has an invalid zone")
+		}
+		column := strings.IndexByte(utmColumns[(c.Zone-1)%3], m[3][0])
+		row := strings.IndexByte(utmRows[(c.Zone-1)%2], m[4][0])
+		if column < 0 || row < 0 {
+			return Coordinate{}, invalid("has a square that is not in its zone")
+		}
+		c.Easting = float64(column+1) * square
+		c.Northing = float64(row) * square
+		digits = m[5]
+	} else if m := mgrsUPSPattern.FindStringSubmatch(compact); m != nil {
+		c.Band = m[1][0]
+		band := strings.IndexByte("ABYZ", c.Band)
+		hemisphere := band / 2
+		column := strings.IndexByte(upsColumns[band], m[2][0])
+		row := strings.IndexByte(upsRows[hemisphere], m[3][0])
+		if column < 0 || row < 0 {
+			return Coordinate{}, invalid("has a square that is not in its band")
+		}
+		c.Easting = float64(upsMinEasting[band]+column) * square
+		c.Northing = float64(upsMinNorthing[hemisphere]+row) * square
+		digits = m[4]
+	} else {
+		return Coordinate{}, invalid("is malformed")
+	}
+
+	if len(digits)%2 == 1 || len(digits) > 2*MaxPrecision {
+		return Coordinate{}, invalid("has an invalid number of digits")
+	}
+	precision := len(digits) / 2
+	scale := math.Pow(10, float64(MaxPrecision-precision))
+	if precision > 0 {
+		easting, _ := strconv.Atoi(digits[:precision])
+		northing, _ := strconv.Atoi(digits[precision:])
+		c.Easting += float64(easting) * scale
+		c.Northing += float64(northing) * scale
+	}
+	c.Easting += scale / 2
+	c.Northing += scale / 2
+
+	if !c.IsUPS() {
+		// rows repeat every 2000 km, so add cycles until the northing reaches
+		// the bottom of the latitude band, rounded down to a whole square
+		bottom := float64(strings.IndexByte(bands, c.Band)*8 + MinLatitude)
+		_, y := utmProjection.forward(bottom*math.Pi/180, 0)
+		if bottom < 0 {
+			y += utmFalseNorthing
+		}
+		for c.Northing < math.Floor(y/square)*square {
+			c.Northing += 2000000
+		}
+	}
+	if err := c.validate(); err != nil {
+		return Coordinate{}, err
+	}
+	return c, nil
+}
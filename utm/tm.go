@@ -0,0 +1,99 @@
+// Package utm converts between WGS 84 latitude/longitude and the Universal
+// Transverse Mercator (UTM) and Universal Polar Stereographic (UPS) grids,
+// and the Military Grid Reference System (MGRS) references built on them.
+package utm
+
+import (
+	"math"
+
+	"github.com/dickeyy/go-distances/ellipsoid"
+)
+
+// transverseMercator projects an ellipsoid with Krüger's series in the
+// third flattening to sixth order, which is accurate to a few nanometres
+// within a UTM zone. See Karney, "Transverse Mercator with an accuracy of a
+// few nanometers" (2011).
+type transverseMercator struct {
+	e float64 // first eccentricity
+	// a is the rectifying radius scaled by the central scale factor.
+	a     float64
+	alpha [6]float64
+	beta  [6]float64
+}
+
+func newTransverseMercator(ell ellipsoid.Ellipsoid, k0 float64) *transverseMercator {
+	n := ell.N()
+	n2, n3, n4, n5, n6 := n*n, n*n*n, n*n*n*n, n*n*n*n*n, n*n*n*n*n*n
+	return &transverseMercator{
+		e: ell.E(),
+		a: k0 * ell.A / (1 + n) * (1 + n2/4 + n4/64 + n6/256),
+		alpha: [6]float64{
+			n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+			13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+			61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+			49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+			34729*n5/80640 - 3418889*n6/1995840,
+			212378941 * n6 / 319334400,
+		},
+		beta: [6]float64{
+			n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+			n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+			17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+			4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+			4583*n5/161280 - 108847*n6/3991680,
+			20648693 * n6 / 638668800,
+		},
+	}
+}
+
+// forward projects a point, in radians, with lon relative to the central
+// meridian. x grows east and y north from the intersection of the central
+// meridian and the equator.
+func (tm *transverseMercator) forward(lat, lon float64) (x, y float64) {
+	// conformal latitude, as its tangent
+	tau := math.Tan(lat)
+	sigma := math.Sinh(tm.e * math.Atanh(tm.e*tau/math.Sqrt(1+tau*tau)))
+	tauPrime := tau*math.Sqrt(1+sigma*sigma) - sigma*math.Sqrt(1+tau*tau)
+
+	// spherical transverse Mercator
+	cosLon := math.Cos(lon)
+	xiPrime := math.Atan2(tauPrime, cosLon)
+	etaPrime := math.Asinh(math.Sin(lon) / math.Sqrt(tauPrime*tauPrime+cosLon*cosLon))
+
+	xi, eta := xiPrime, etaPrime
+	for j, alpha := range tm.alpha {
+		k := 2 * float64(j+1)
+		xi += alpha * math.Sin(k*xiPrime) * math.Cosh(k*etaPrime)
+		eta += alpha * math.Cos(k*xiPrime) * math.Sinh(k*etaPrime)
+	}
+	return tm.a * eta, tm.a * xi
+}
+
+// inverse reverses forward, returning radians.
+func (tm *transverseMercator) inverse(x, y float64) (lat, lon float64) {
+	xi, eta := y/tm.a, x/tm.a
+	xiPrime, etaPrime := xi, eta
+	for j, beta := range tm.beta {
+		k := 2 * float64(j+1)
+		xiPrime -= beta * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaPrime -= beta * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+
+	sinhEta, sinXi, cosXi := math.Sinh(etaPrime), math.Sin(xiPrime), math.Cos(xiPrime)
+	tauPrime := sinXi / math.Sqrt(sinhEta*sinhEta+cosXi*cosXi)
+
+	// solve for the geodetic latitude with Newton's method
+	e2 := tm.e * tm.e
+	tau := tauPrime
+	for range 10 {
+		sigma := math.Sinh(tm.e * math.Atanh(tm.e*tau/math.Sqrt(1+tau*tau)))
+		tauI := tau*math.Sqrt(1+sigma*sigma) - sigma*math.Sqrt(1+tau*tau)
+		delta := (tauPrime - tauI) / math.Sqrt(1+tauI*tauI) *
+			(1 + (1-e2)*tau*tau) / ((1 - e2) * math.Sqrt(1+tau*tau))
+		tau += delta
+		if math.Abs(delta) < 1e-12 {
+			break
+		}
+	}
+	return math.Atan(tau), math.Atan2(sinhEta, cosXi)
+}
@@ -0,0 +1,52 @@
+// Package utm converts between WGS 84 latitude/longitude and the Universal
+// Transverse Mercator (UTM) and Universal Polar Stereographic (UPS) grids,
+// and the Military Grid Reference System (MGRS) references built on them.
+package utm
+
+import (
+	"math"
+
+	"github.com/dickeyy/go-distances/ellipsoid"
+)
+
+// polarStereographic projects an ellipsoid onto a plane tangent to the
+// north pole, or to the south pole for points given with negated latitudes.
+type polarStereographic struct {
+	e float64
+	// c converts t, the tangent of half the conformal colatitude, into the
+	// distance from the pole.
+	c float64
+}
+
+func newPolarStereographic(ell ellipsoid.Ellipsoid, k0 float64) *polarStereographic {
+	e := ell.E()
+	return &polarStereographic{
+		e: e,
+		c: 2 * ell.A * k0 / math.Sqrt(math.Pow(1+e, 1+e)*math.Pow(1-e, 1-e)),
+	}
+}
+
+// forward projects a northern point, in radians. x grows towards lon 90°E
+// and y away from lon 180°.
+func (ps *polarStereographic) forward(lat, lon float64) (x, y float64) {
+	sin := ps.e * math.Sin(lat)
+	t := math.Tan(math.Pi/4-lat/2) / math.Pow((1-sin)/(1+sin), ps.e/2)
+	rho := ps.c * t
+	return rho * math.Sin(lon), -rho * math.Cos(lon)
+}
+
+// inverse reverses forward, returning radians.
+func (ps *polarStereographic) inverse(x, y float64) (lat, lon float64) {
+	t := math.Hypot(x, y) / ps.c
+	lat = math.Pi/2 - 2*math.Atan(t)
+	for range 20 {
+		sin := ps.e * math.Sin(lat)
+		next := math.Pi/2 - 2*math.Atan(t*math.Pow((1-sin)/(1+sin), ps.e/2))
+		done := math.Abs(next-lat) < 1e-15
+		lat = next
+		if done {
+			break
+		}
+	}
+	return lat, math.Atan2(x, -y)
+}
@@ -0,0 +1,247 @@
+// Package utm converts between WGS 84 latitude/longitude and the Universal
+// Transverse Mercator (UTM) and Universal Polar Stereographic (UPS) grids,
+// and the Military Grid Reference System (MGRS) references built on them.
+package utm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dickeyy/go-distances/ellipsoid"
+)
+
+const (
+	// UTM covers latitudes from MinLatitude to MaxLatitude; UPS covers the
+	// polar caps beyond.
+	MinLatitude = -80
+	MaxLatitude = 84
+
+	utmFalseEasting  = 500000
+	utmFalseNorthing = 10000000 // southern hemisphere only
+	upsFalseOrigin   = 2000000  // both easting and northing
+
+	// bands are the UTM latitude bands from 80°S northwards, 8° each except
+	// for X, which is 12°.
+	bands = "CDEFGHJKLMNPQRSTUVWX"
+)
+
+var (
+	// ErrInvalid is wrapped by errors for malformed coordinates and grid
+	// references.
+	ErrInvalid = errors.New("invalid UTM coordinate")
+	// ErrOutOfRange is wrapped by errors for latitudes outside [-90, 90] and
+	// for points outside the forced zone.
+	ErrOutOfRange = errors.New("coordinate out of range")
+)
+
+var (
+	utmProjection = newTransverseMercator(ellipsoid.WGS84, 0.9996)
+	upsProjection = newPolarStereographic(ellipsoid.WGS84, 0.994)
+)
+
+// Coordinate is a position on the UTM or UPS grid, in metres.
+type Coordinate struct {
+	// Zone is the UTM zone, 1–60, or 0 for UPS.
+	Zone int
+	// Band is the latitude band letter, C–X, for UTM, or A, B (south) and
+	// Y, Z (north) for UPS. Only its hemisphere matters for the inverse
+	// projection.
+	Band     byte
+	Easting  float64
+	Northing float64
+}
+
+// IsUPS reports whether the coordinate is on a polar UPS grid.
+func (c Coordinate) IsUPS() bool {
+	return c.Zone == 0
+}
+
+// North reports whether the coordinate is in the northern hemisphere.
+func (c Coordinate) North() bool {
+	return c.Band >= 'N'
+}
+
+// String formats the coordinate in whole metres, like "32U 691608 5334764"
+// or "Z 2000000 2000000".
+func (c Coordinate) String() string {
+	zone := ""
+	if !c.IsUPS() {
+		zone = strconv.Itoa(c.Zone)
+	}
+	return fmt.Sprintf("%s%c %.0f %.0f", zone, c.Band, math.Floor(c.Easting+0.5), math.Floor(c.Northing+0.5))
+}
+
+// ZoneOf returns the UTM zone of a point, honouring the exceptions for
+// southwestern Norway and Svalbard, or 0 when the point is in a polar UPS
+// area.
+func ZoneOf(lat, lon float64) int {
+	if lat < MinLatitude || lat > MaxLatitude {
+		return 0
+	}
+	lon = normalizeLongitude(lon)
+	zone := int(math.Floor((lon+180)/6)) + 1
+	switch {
+	case lat >= 56 && lat < 64 && lon >= 3 && lon < 12:
+		zone = 32
+	case lat >= 72 && lon >= 0 && lon < 42:
+		// Svalbard uses the odd zones only, widened to 9° or 12°
+		switch {
+		case lon < 9:
+			zone = 31
+		case lon < 21:
+			zone = 33
+		case lon < 33:
+			zone = 35
+		default:
+			zone = 37
+		}
+	}
+	return zone
+}
+
+// FromLatLon converts a point, in degrees, to its standard UTM coordinate,
+// or to UPS in the polar caps.
+func FromLatLon(lat, lon float64) (Coordinate, error) {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 || math.IsNaN(lon) || math.IsInf(lon, 0) {
+		return Coordinate{}, fmt.Errorf("%w: latitude %v, longitude %v", ErrOutOfRange, lat, lon)
+	}
+	zone := ZoneOf(lat, lon)
+	if zone == 0 {
+		return fromLatLonUPS(lat, lon), nil
+	}
+	return fromLatLonZone(lat, lon, zone), nil
+}
+
+// FromLatLonZone converts a point, in degrees, to UTM in the given zone
+// rather than its own, which is useful to keep a survey on a single grid.
+// The point must be within UTM latitudes and no more than 3° outside the
+// zone, beyond which the projection loses accuracy quickly.
+func FromLatLonZone(lat, lon float64, zone int) (Coordinate, error) {
+	if zone < 1 || zone > 60 {
+		return Coordinate{}, fmt.Errorf("%w: zone %d", ErrInvalid, zone)
+	}
+	if math.IsNaN(lat) || lat < MinLatitude || lat > MaxLatitude ||
+		math.IsNaN(lon) || math.Abs(angleDifference(lon, centralMeridian(zone))) > 6 {
+		return Coordinate{}, fmt.Errorf("%w: latitude %v, longitude %v in zone %d", ErrOutOfRange, lat, lon, zone)
+	}
+	return fromLatLonZone(lat, lon, zone), nil
+}
+
+func fromLatLonZone(lat, lon float64, zone int) Coordinate {
+	band := bands[min(int(math.Floor((lat-MinLatitude)/8)), len(bands)-1)]
+	x, y := utmProjection.forward(lat*math.Pi/180, angleDifference(lon, centralMeridian(zone))*math.Pi/180)
+	northing := y
+	if lat < 0 {
+		northing += utmFalseNorthing
+	}
+	return Coordinate{Zone: zone, Band: band, Easting: x + utmFalseEasting, Northing: northing}
+}
+
+func fromLatLonUPS(lat, lon float64) Coordinate {
+	north := lat > 0
+	phi := math.Abs(lat) * math.Pi / 180
+	x, y := upsProjection.forward(phi, lon*math.Pi/180)
+	if !north {
+		y = -y
+	}
+
+	var band byte
+	switch {
+	case north && lon < 0:
+		band = 'Y'
+	case north:
+		band = 'Z'
+	case lon < 0:
+		band = 'A'
+	default:
+		band = 'B'
+	}
+	return Coordinate{Band: band, Easting: x + upsFalseOrigin, Northing: y + upsFalseOrigin}
+}
+
+// LatLon converts the coordinate back to a latitude and longitude in
+// degrees, with the longitude in [-180, 180).
+func (c Coordinate) LatLon() (lat, lon float64, err error) {
+	if err := c.validate(); err != nil {
+		return 0, 0, err
+	}
+
+	if c.IsUPS() {
+		x, y := c.Easting-upsFalseOrigin, c.Northing-upsFalseOrigin
+		if !c.North() {
+			y = -y
+		}
+		phi, lambda := upsProjection.inverse(x, y)
+		lat = phi * 180 / math.Pi
+		if !c.North() {
+			lat = -lat
+		}
+		return lat, normalizeLongitude(lambda * 180 / math.Pi), nil
+	}
+
+	y := c.Northing
+	if !c.North() {
+		y -= utmFalseNorthing
+	}
+	phi, lambda := utmProjection.inverse(c.Easting-utmFalseEasting, y)
+	return phi * 180 / math.Pi, normalizeLongitude(lambda*180/math.Pi + centralMeridian(c.Zone)), nil
+}
+
+// validate checks that the zone and band agree and that the easting and
+// northing are within the grid.
+func (c Coordinate) validate() error {
+	switch {
+	case c.IsUPS() && strings.IndexByte("ABYZ", c.Band) < 0,
+		!c.IsUPS() && (c.Zone < 1 || c.Zone > 60 || strings.IndexByte(bands, c.Band) < 0):
+		return fmt.Errorf("%w: zone %d band %q", ErrInvalid, c.Zone, c.Band)
+	case c.IsUPS() && (c.Easting < 0 || c.Easting > 2*upsFalseOrigin || c.Northing < 0 || c.Northing > 2*upsFalseOrigin),
+		!c.IsUPS() && (c.Easting < 0 || c.Easting > 2*utmFalseEasting || c.Northing < 0 || c.Northing > utmFalseNorthing),
+		math.IsNaN(c.Easting), math.IsNaN(c.Northing):
+		return fmt.Errorf("%w: easting %v, northing %v", ErrOutOfRange, c.Easting, c.Northing)
+	}
+	return nil
+}
+
+// coordinatePattern matches "32U 691608 5334764", with an optional space
+// after the zone, and the UPS form "Z 2000000 2000000".
+var coordinatePattern = regexp.MustCompile(`^(\d{1,2})?\s*([A-Za-z])\s+(\d+(?:\.\d*)?)\s+(\d+(?:\.\d*)?)$`)
+
+// Parse parses a coordinate in the format of Coordinate.String, with
+// optional fractional metres.
+func Parse(s string) (Coordinate, error) {
+	m := coordinatePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Coordinate{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	c := Coordinate{Band: strings.ToUpper(m[2])[0]}
+	if m[1] != "" {
+		c.Zone, _ = strconv.Atoi(m[1])
+	}
+	c.Easting, _ = strconv.ParseFloat(m[3], 64)
+	c.Northing, _ = strconv.ParseFloat(m[4], 64)
+	if err := c.validate(); err != nil {
+		return Coordinate{}, err
+	}
+	return c, nil
+}
+
+// centralMeridian returns the longitude of the middle of a UTM zone.
+func centralMeridian(zone int) float64 {
+	return float64(zone)*6 - 183
+}
+
+// angleDifference returns a - b wrapped into [-180, 180).
+func angleDifference(a, b float64) float64 {
+	return normalizeLongitude(a - b)
+}
+
+func normalizeLongitude(lon float64) float64 {
+	if lon >= -180 && lon < 180 {
+		return lon
+	}
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
@@ -0,0 +1,234 @@
+package utm
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+var landmarks = []struct {
+	name     string
+	lat, lon float64
+	utm      string
+	mgrs     string
+}{
+	{"Eiffel Tower", 48.8582, 2.2945, "31U 448252 5411933", "31UDQ4825111932"},
+	{"Sydney Opera House", -33.857, 151.215, "56H 334873 6252266", "56HLH3487352266"},
+	{"White House", 38.8977, -77.0365, "18S 323394 4307396", "18SUJ2339407395"},
+	{"Christ the Redeemer", -22.9519, -43.2106, "23K 683466 7460687", "23KPQ8346660687"},
+	{"Bergen", 60.39135, 5.3249, "32V 297508 6700645", "32VKN9750800645"},
+	{"Null Island", 0, 0, "31N 166021 0", "31NAA6602100000"},
+	{"North Pole", 90, 0, "Z 2000000 2000000", "ZAH0000000000"},
+	{"South Pole", -90, 0, "B 2000000 2000000", "BAN0000000000"},
+}
+
+func TestFromLatLon(t *testing.T) {
+	for _, tt := range landmarks {
+		c, err := FromLatLon(tt.lat, tt.lon)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := c.String(); got != tt.utm {
+			t.Errorf("%s: UTM = %s, want %s", tt.name, got, tt.utm)
+		}
+		if got, err := c.MGRS(MaxPrecision); err != nil || got != tt.mgrs {
+			t.Errorf("%s: MGRS = %s, %v, want %s", tt.name, got, err, tt.mgrs)
+		}
+	}
+}
+
+func TestZoneOf(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		want     int
+	}{
+		{0, -180, 1},
+		{0, 179.9, 60},
+		{0, 180, 1},
+		{60, 4, 32},   // Norway
+		{55.9, 4, 31}, // south of the Norway exception
+		{75, 8, 31},   // Svalbard
+		{75, 10, 33},
+		{75, 25, 35},
+		{75, 40, 37},
+		{75, 43, 38},
+		{84, 10, 33},
+		{84.1, 10, 0},
+		{-80, 10, 32},
+		{-80.1, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := ZoneOf(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("ZoneOf(%v, %v) = %d, want %d", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for lat := -89.5; lat <= 89.5; lat += 2.5 {
+		for lon := -179.0; lon < 180; lon += 7.3 {
+			c, err := FromLatLon(lat, lon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotLat, gotLon, err := c.LatLon()
+			if err != nil {
+				t.Fatalf("%v: %v", c, err)
+			}
+			if math.Abs(gotLat-lat) > 1e-10 || math.Abs(angleDifference(gotLon, lon)) > 1e-10 {
+				t.Errorf("%v, %v -> %v -> %v, %v", lat, lon, c, gotLat, gotLon)
+			}
+		}
+	}
+}
+
+func TestFromLatLonZone(t *testing.T) {
+	// Bergen is in zone 32 because of the Norway exception, but can be
+	// projected into its nominal zone 31 too
+	c, err := FromLatLonZone(60.39135, 5.3249, 31)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, lon, _ := c.LatLon()
+	if c.Zone != 31 || math.Abs(lat-60.39135) > 1e-10 || math.Abs(lon-5.3249) > 1e-10 {
+		t.Errorf("FromLatLonZone = %v -> %v, %v", c, lat, lon)
+	}
+
+	if _, err := FromLatLonZone(60, 30, 31); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("FromLatLonZone far outside the zone error = %v, want ErrOutOfRange", err)
+	}
+	if _, err := FromLatLonZone(60, 5, 61); !errors.Is(err, ErrInvalid) {
+		t.Errorf("FromLatLonZone in zone 61 error = %v, want ErrInvalid", err)
+	}
+	if _, err := FromLatLon(91, 0); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("FromLatLon(91, 0) error = %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"31U 448252 5411933", "31 u 448252.0 5411933", "Z 2000000 2000000"} {
+		c, err := Parse(s)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", s, err)
+			continue
+		}
+		if _, _, err := c.LatLon(); err != nil {
+			t.Errorf("Parse(%q).LatLon: %v", s, err)
+		}
+	}
+
+	for _, s := range []string{"", "31U 448252", "61U 448252 5411933", "31I 448252 5411933", "C 2000000 2000000", "31U 1448252 5411933"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) succeeded", s)
+		}
+	}
+}
+
+func TestParseMGRS(t *testing.T) {
+	for _, tt := range landmarks {
+		c, err := ParseMGRS(tt.mgrs)
+		if err != nil {
+			t.Errorf("%s: ParseMGRS(%q): %v", tt.name, tt.mgrs, err)
+			continue
+		}
+		lat, lon, err := c.LatLon()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the center of a 1 m square is within a metre of the point
+		if math.Abs(lat-tt.lat) > 1e-5 || math.Abs(angleDifference(lon, tt.lon)) > 1e-5 && math.Abs(tt.lat) != 90 {
+			t.Errorf("%s: ParseMGRS(%q) = %v, %v, want %v, %v", tt.name, tt.mgrs, lat, lon, tt.lat, tt.lon)
+		}
+	}
+}
+
+func TestParseMGRSPrecision(t *testing.T) {
+	tests := []struct {
+		mgrs              string
+		easting, northing float64
+	}{
+		{"31U DQ 48251 11932", 448251.5, 5411932.5},
+		{"31udq4811", 448500, 5411500},
+		{"31UDQ", 450000, 5450000},
+	}
+	for _, tt := range tests {
+		c, err := ParseMGRS(tt.mgrs)
+		if err != nil || c.Zone != 31 || c.Band != 'U' || c.Easting != tt.easting || c.Northing != tt.northing {
+			t.Errorf("ParseMGRS(%q) = %+v, %v, want %v %v", tt.mgrs, c, err, tt.easting, tt.northing)
+		}
+	}
+
+	for _, s := range []string{"", "31UDQ123", "31UIQ1234", "31UDW1234", "61UDQ1234", "CAA", "ZZZ00"} {
+		if _, err := ParseMGRS(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseMGRS(%q) error = %v, want ErrInvalid", s, err)
+		}
+	}
+}
+
+func TestMGRSPrecision(t *testing.T) {
+	tests := []struct {
+		precision int
+		want      string
+	}{
+		{-1, "31UDQ"},
+		{0, "31UDQ"},
+		{2, "31UDQ4811"},
+		{7, "31UDQ4825111932"},
+	}
+	for _, tt := range tests {
+		if got, _ := MGRSFromLatLon(48.8582, 2.2945, tt.precision); got != tt.want {
+			t.Errorf("MGRS precision %d = %s, want %s", tt.precision, got, tt.want)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		c, err := Parse(s)
+		if err != nil {
+			return
+		}
+		checkLatLon(t, s, c)
+		rounded, err := Parse(c.String())
+		if err != nil {
+			t.Fatalf("Parse(%q) = %v, which does not parse: %v", s, c, err)
+		}
+		if again, err := Parse(rounded.String()); err != nil || again != rounded {
+			t.Fatalf("%v does not round trip: got %v, %v", rounded, again, err)
+		}
+	})
+}
+
+func FuzzParseMGRS(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		c, err := ParseMGRS(s)
+		if err != nil {
+			return
+		}
+		checkLatLon(t, s, c)
+		// the easting and northing digits follow the last letter
+		compact := strings.Join(strings.Fields(s), "")
+		precision := (len(compact) - len(strings.TrimRight(compact, "0123456789"))) / 2
+		reference, err := c.MGRS(precision)
+		if err != nil {
+			t.Fatalf("ParseMGRS(%q) = %v, which has no reference: %v", s, c, err)
+		}
+		if again, err := ParseMGRS(reference); err != nil || again != c {
+			t.Fatalf("ParseMGRS(%q) = %v, %v, want %v", reference, again, err, c)
+		}
+	})
+}
+
+// checkLatLon checks that a parsed coordinate converts to a latitude and
+// longitude in range.
+func checkLatLon(t *testing.T, s string, c Coordinate) {
+	t.Helper()
+	lat, lon, err := c.LatLon()
+	if err != nil {
+		t.Fatalf("%q parsed as %v, which has no latitude and longitude: %v", s, c, err)
+	}
+	if !(math.Abs(lat) <= 90) || !(lon >= -180 && lon < 180) {
+		t.Fatalf("%q is at %v, %v, out of range", s, lat, lon)
+	}
+}
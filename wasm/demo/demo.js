@@ -0,0 +1,60 @@
+// Loads the WebAssembly build of go-distances and measures the route through
+// the places of the chosen file with goDistances.route.
+
+const file = document.getElementById("file");
+const formula = document.getElementById("formula");
+const error = document.getElementById("error");
+const legs = document.getElementById("legs");
+
+async function load() {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch("go-distances.wasm"), go.importObject);
+  go.run(instance);
+  for (const name of goDistances.formulas()) {
+    formula.add(new Option(name, name));
+  }
+}
+
+// describe names a place as in the tables of the command line.
+function describe(place) {
+  return place.name || `${place.lat}, ${place.lon}`;
+}
+
+function show(result) {
+  error.textContent = "";
+  legs.hidden = true;
+  if (result.error) {
+    error.textContent = result.error;
+    return;
+  }
+
+  const decimals = 3;
+  const body = legs.tBodies[0];
+  body.replaceChildren();
+  for (const leg of result.legs) {
+    const row = body.insertRow();
+    row.insertCell().textContent = leg.leg;
+    row.insertCell().textContent = describe(leg.from);
+    row.insertCell().textContent = describe(leg.to);
+    const distance = row.insertCell();
+    distance.className = "number";
+    distance.textContent = `${leg.distance.toFixed(decimals)} ${result.unit}`;
+  }
+  document.getElementById("total").textContent = `${result.total.toFixed(decimals)} ${result.unit}`;
+  legs.tHead.rows[0].cells[3].textContent = `Distance (${result.formula})`;
+  legs.hidden = false;
+}
+
+async function measure() {
+  if (file.files.length === 0) {
+    return;
+  }
+  const text = await file.files[0].text();
+  show(goDistances.route(text, { formula: formula.value }));
+}
+
+file.addEventListener("change", measure);
+formula.addEventListener("change", measure);
+load().catch((err) => {
+  error.textContent = `Could not load go-distances.wasm: ${err}`;
+});
@@ -0,0 +1,87 @@
+// Command demo serves a page that measures the route through a places file
+// in the browser, with the WebAssembly build of go-distances. It builds the
+// WebAssembly module when it starts, so it must be run from within the
+// module:
+//
+//	go run ./wasm/demo [-addr localhost:8000]
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed index.html demo.js
+var static embed.FS
+
+// built are the files served from the build directory rather than embedded.
+var built = []string{"go-distances.wasm", "wasm_exec.js"}
+
+func main() {
+	addr := flag.String("addr", "localhost:8000", "address to listen on")
+	flag.Parse()
+	if err := run(*addr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run builds the module into a temporary directory and serves the demo on
+// addr.
+func run(addr string) error {
+	dir, err := os.MkdirTemp("", "go-distances-demo-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := build(dir); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Serving the demo at http://%s/\n", addr)
+	return http.ListenAndServe(addr, handler(static, dir))
+}
+
+// build writes the WebAssembly module and the wasm_exec.js support script of
+// the Go toolchain that built it to dir.
+func build(dir string) error {
+	cmd := exec.Command("go", "build", "-o", filepath.Join(dir, "go-distances.wasm"), "github.com/dickeyy/go-distances/wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("building the WebAssembly module: %w", err)
+	}
+
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return fmt.Errorf("finding GOROOT: %w", err)
+	}
+	// wasm_exec.js moved from misc/wasm to lib/wasm in Go 1.24
+	for _, sub := range []string{"lib/wasm", "misc/wasm"} {
+		script, err := os.ReadFile(filepath.Join(strings.TrimSpace(string(goroot)), sub, "wasm_exec.js"))
+		if err == nil {
+			return os.WriteFile(filepath.Join(dir, "wasm_exec.js"), script, 0o644)
+		}
+	}
+	return fmt.Errorf("wasm_exec.js not found in %s", strings.TrimSpace(string(goroot)))
+}
+
+// handler serves the page from static and the built files from dir.
+func handler(static fs.FS, dir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	for _, name := range built {
+		mux.HandleFunc("GET /"+name, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeFile(w, r, filepath.Join(dir, name))
+		})
+	}
+	return mux
+}
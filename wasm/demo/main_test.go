@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go-distances.wasm"), []byte("\x00asm"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := handler(static, dir)
+
+	tests := []struct {
+		path, contentType, contains string
+	}{
+		{"/", "text/html", `src="demo.js"`},
+		{"/demo.js", "javascript", "goDistances.route"},
+		{"/go-distances.wasm", "application/wasm", "asm"},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%s: status %d", tt.path, recorder.Code)
+			continue
+		}
+		if got := recorder.Header().Get("Content-Type"); !strings.Contains(got, tt.contentType) {
+			t.Errorf("%s: Content-Type = %q, want %s", tt.path, got, tt.contentType)
+		}
+		if !strings.Contains(recorder.Body.String(), tt.contains) {
+			t.Errorf("%s: body has no %q", tt.path, tt.contains)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/wasm_exec.js", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("/wasm_exec.js before it is built: status %d, want 404", recorder.Code)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the WebAssembly module")
+	}
+	dir := t.TempDir()
+	if err := build(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range built {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.Size() == 0 {
+			t.Errorf("%s not built: %v", name, err)
+		}
+	}
+}
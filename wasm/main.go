@@ -0,0 +1,78 @@
+//go:build js && wasm
+
+// Command wasm is the WebAssembly build of go-distances. It defines a global
+// goDistances object whose functions take and return plain JavaScript
+// objects:
+//
+//	goDistances.formulas()
+//	    // ["haversine", "vincenty", "sloc", "fasthaversine", ...]
+//	goDistances.distance({from: {lat, lon}, to: {lat, lon}, formula, radius})
+//	    // {formula, unit, distance}
+//	goDistances.route(places, {formula})
+//	    // the document written by go-distances -format json
+//
+// The places passed to route are a places document, either as an object in
+// the JSON layout or as the text of a document in any supported format.
+// Failures are returned as {error, place, field} rather than thrown.
+//
+// Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o go-distances.wasm ./wasm
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/dickeyy/go-distances/jsapi"
+)
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("formulas", js.FuncOf(func(js.Value, []js.Value) any {
+		var names []any
+		for _, name := range jsapi.Formulas() {
+			names = append(names, name)
+		}
+		return names
+	}))
+	api.Set("distance", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		return fromJSON(jsapi.Distance(toJSON(arg(args, 0))))
+	}))
+	api.Set("route", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		places := arg(args, 0)
+		document := []byte(places.String())
+		if places.Type() != js.TypeString {
+			document = toJSON(places)
+		}
+		var formula string
+		if options := arg(args, 1); options.Type() == js.TypeObject {
+			if f := options.Get("formula"); f.Type() == js.TypeString {
+				formula = f.String()
+			}
+		}
+		return fromJSON(jsapi.Route(document, formula))
+	}))
+	js.Global().Set("goDistances", api)
+
+	// the functions stay callable for as long as the program runs
+	select {}
+}
+
+// arg returns the i-th argument, or undefined when there are fewer.
+func arg(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
+
+func toJSON(v js.Value) []byte {
+	if v.IsUndefined() {
+		return []byte("null")
+	}
+	return []byte(js.Global().Get("JSON").Call("stringify", v).String())
+}
+
+func fromJSON(b []byte) js.Value {
+	return js.Global().Get("JSON").Call("parse", string(b))
+}